@@ -9,6 +9,7 @@ import (
 	"blog-backend/usecase"
 	"context"
 	"log"
+	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -46,7 +47,17 @@ func main() {
 
 	// Initialize services and repositories
 	timeOut := 30 * time.Second
-	jwtService := infrastructure.NewJWTService(envConfig.JWTSecret)
+	jwtService, err := infrastructure.NewJWTService(context.Background(), repository.NewSigningKeyRepositoryFromDB(db), infrastructure.JWTOptions{
+		KeySecret:        envConfig.JWTSecret,
+		Algorithm:        envConfig.JWTAlgorithm,
+		Issuer:           envConfig.JWTIssuer,
+		Audience:         envConfig.JWTAudience,
+		RotationInterval: envConfig.JWTKeyRotation,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
+	kc := controller.NewJWKSController(jwtService)
 	passwordService := infrastructure.NewPasswordService()
 	geminiService := infrastructure.NewGeminiService(envConfig.GeminiAPIKey)
 	emailServices := infrastructure.NewEmailServices(envConfig.Email, envConfig.AppPassword) 
@@ -58,20 +69,126 @@ func main() {
 	gc := controller.NewGeminiController(gu)
 
 	ur := repository.NewUserRepositoryFromDB(db)
-	uu := usecase.NewUserUsecase(ur, timeOut, passwordService, cacheUseCase) 
+	refreshTR := repository.NewRefreshTokenRepositoryFromDB(db)
+	tokenDenylist := infrastructure.NewRedisTokenDenylist(redisClient)
+	tfr := repository.NewTwoFactorRepositoryFromDB(db)
+	totpService, err := infrastructure.NewTOTPService(envConfig.TOTPIssuer, envConfig.TOTPSecret, envConfig.JWTSecret)
+	if err != nil {
+		log.Fatalf("Failed to set up TOTP service: %v", err)
+	}
+	twoFactorStore := infrastructure.NewRedisTwoFactorStore(redisClient)
 
-	uc := controller.NewUserController(uu)
 	bcr := repository.NewCommentRepositoryFromDB(db)
 	brr := repository.NewReactionRepositoryFromDB(db)
 	br := repository.NewBlogRepositoryFromDB(db)
 	hr := repository.NewHistoryRepositoryFromDB(db) 
-	bu := usecase.NewBlogUsecase(br, brr, bcr, hr, geminiService, timeOut, cacheUseCase) 
+	rvr := repository.NewRevisionRepositoryFromDB(db)
+
+	_, statErr := os.Stat(envConfig.SearchIndexPath)
+	searchIndex, err := infrastructure.NewSearchIndex(envConfig.SearchIndexPath)
+	if err != nil {
+		log.Fatalf("Failed to load search index: %v", err)
+	}
+	defer searchIndex.Flush(context.Background())
+
+	eventBroker := infrastructure.NewRedisEventBroker(redisClient)
+	defer eventBroker.Close()
+
+	nr := repository.NewNotificationRepositoryFromDB(db)
+	nu := usecase.NewNotificationUsecase(nr, ur, eventBroker, timeOut)
+	nc := controller.NewNotificationController(nu)
+
+	txm := infrastructure.NewMongoTransactionManager(client)
+	bmr := repository.NewBookmarkRepositoryFromDB(db)
+	viewCounter := infrastructure.NewRedisViewCounter(redisClient, envConfig.ViewDedupeWindow)
+	trendingIndex := infrastructure.NewRedisTrendingIndex(redisClient)
+	sr := repository.NewStatsRepositoryFromDB(db)
+	bu := usecase.NewBlogUsecase(br, brr, bcr, hr, rvr, bmr, sr, ur, txm, searchIndex, nu, eventBroker, viewCounter, trendingIndex, geminiService, envConfig.ReactionTypes, timeOut, cacheUseCase) 
 	bc := controller.NewBlogController(bu)
+
+	bmu := usecase.NewBookmarkUsecase(bmr, br, timeOut, cacheUseCase)
+	bmc := controller.NewBookmarkController(bmu)
+
+	ru := usecase.NewRecommendationUsecase(hr, br, brr, trendingIndex, timeOut, cacheUseCase)
+	rc := controller.NewRecommendationController(ru)
+
+	fr := repository.NewFollowRepositoryFromDB(db)
+	fu := usecase.NewFollowUsecase(fr, ur, br, ru, nu, timeOut, cacheUseCase)
+	fc := controller.NewFollowController(fu)
+
+	eu := usecase.NewEventUsecase(eventBroker, br, timeOut)
+	ec := controller.NewEventController(eu)
+
+	rr := repository.NewReportRepositoryFromDB(db)
+	mu := usecase.NewModerationUsecase(rr, br, bcr, bu, envConfig.ReportHideThreshold, timeOut)
+	mc := controller.NewModerationController(mu)
+
+	su := usecase.NewStatsUsecase(sr, br, timeOut)
+	sc := controller.NewStatsController(su)
+
+	cs := usecase.NewCascadeService(txm, br, bcr, brr, hr, rvr, bmr, sr, fr, nr, ur, refreshTR, tfr, searchIndex, cacheUseCase)
+
+	uu := usecase.NewUserUsecase(ur, refreshTR, tokenDenylist, cs, timeOut, passwordService, cacheUseCase) 
+	uc := controller.NewUserController(uu)
+
+	pu := usecase.NewPurgeUsecase(br, bcr, ur, cs, envConfig.SoftDeleteRetention, timeOut)
+
+	// --- Background Jobs ---
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	go infrastructure.RunPeriodically(jobsCtx, "scheduled-publisher", time.Minute, func(ctx context.Context) error {
+		published, err := bu.PublishDueBlogs(ctx)
+		if published > 0 {
+			log.Printf("Published %d scheduled blogs", published)
+		}
+		return err
+	})
+
+	go infrastructure.RunPeriodically(jobsCtx, "reaction-reconcile", time.Hour, func(ctx context.Context) error {
+		fixed, err := bu.ReconcileReactionCounts(ctx)
+		if fixed > 0 {
+			log.Printf("Reconciled reaction counters of %d blogs", fixed)
+		}
+		return err
+	})
+
+	go infrastructure.RunPeriodically(jobsCtx, "view-flush", time.Minute, func(ctx context.Context) error {
+		_, err := bu.FlushViews(ctx)
+		return err
+	})
+
+	go infrastructure.RunPeriodically(jobsCtx, "trending-maintenance", time.Hour, bu.MaintainTrending)
+
+	// also picks up keys added by other instances
+	go infrastructure.RunPeriodically(jobsCtx, "signing-key-rotation", time.Minute, jwtService.RotateKeys)
+
+	go infrastructure.RunPeriodically(jobsCtx, "search-index-flush", 30*time.Second, searchIndex.Flush)
+
+	go infrastructure.RunPeriodically(jobsCtx, "purge-deleted", 6*time.Hour, func(ctx context.Context) error {
+		report, err := pu.PurgeExpired(ctx)
+		if report.Blogs+report.Comments+report.Users > 0 {
+			log.Printf("Purged %d blogs, %d comments and %d users past retention", report.Blogs, report.Comments, report.Users)
+		}
+		return err
+	})
+
+	// First start without an index on disk: build it in the background.
+	// Afterwards use cmd/reindex to rebuild it by hand.
+	if os.IsNotExist(statErr) {
+		go func() {
+			indexed, err := usecase.RebuildSearchIndex(jobsCtx, br, searchIndex)
+			if err != nil {
+				log.Printf("Failed to build search index: %v", err)
+				return
+			}
+			log.Printf("Built search index with %d blogs", indexed)
+		}()
+	}
 	
 	resetTR := repository.NewResetTokenRepository(db)
-	refreshTR := repository.NewRefreshTokenRepositoryFromDB(db)
 	atr := repository.NewActivationTokenRepository(db) 
-	au := usecase.NewAuthUsecase(ur, refreshTR, resetTR, jwtService, passwordService, emailServices, atr, timeOut) 
+	au := usecase.NewAuthUsecase(ur, refreshTR, tokenDenylist, resetTR, jwtService, passwordService, emailServices, atr, tfr, totpService, twoFactorStore, timeOut) 
 	ac := controller.NewAuthController(au, googleConfig)
 
 	// Set up Gin router
@@ -107,7 +224,7 @@ func main() {
 	// Apply the rate limit middleware globally
 	engine.Use(rateLimitMiddleware)
 
-	route.Setup(ac, bc, uc, gc, jwtService, engine)
+	route.Setup(ac, bc, uc, gc, bmc, fc, nc, ec, mc, sc, rc, kc, jwtService, tokenDenylist, engine)
 
 	// Start server
 	if err := engine.Run("localhost:3000"); err != nil {
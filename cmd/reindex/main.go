@@ -0,0 +1,37 @@
+// Command reindex rebuilds the blog search index from the blogs collection.
+// A running API loads the new index on its next flush instead of writing its
+// own over it.
+package main
+
+import (
+	"blog-backend/config"
+	"blog-backend/infrastructure"
+	"blog-backend/repository"
+	"blog-backend/usecase"
+	"context"
+	"log"
+	"time"
+)
+
+func main() {
+	envConfig, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err.Error())
+	}
+
+	client, db := infrastructure.NewDatabase(envConfig.MongoURI, envConfig.DBName)
+	defer client.Disconnect(context.TODO())
+
+	// the old index is replaced wholesale, so there is no point loading it
+	searchIndex := infrastructure.NewEmptySearchIndex(envConfig.SearchIndexPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	indexed, err := usecase.RebuildSearchIndex(ctx, repository.NewBlogRepositoryFromDB(db), searchIndex)
+	if err != nil {
+		log.Fatalf("Failed to rebuild search index: %v", err)
+	}
+	log.Printf("Indexed %d blogs into %s in %s", indexed, envConfig.SearchIndexPath, time.Since(start).Round(time.Millisecond))
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"blog-backend/domain"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,11 +17,62 @@ type Config struct {
 	DBName             string
 	GoogleClientID     string
 	GoogleClientSecret string
-	JWTSecret          string
-	GeminiAPIKey       string
-	Email              string
-	AppPassword        string
-	RedisURL           string
+	// JWTSecret seals the access token signing keys stored in the database.
+	JWTSecret       string
+	GeminiAPIKey    string
+	Email           string
+	AppPassword     string
+	RedisURL        string
+	SearchIndexPath string
+	// ReportHideThreshold is how many distinct open reports hide content
+	// until a moderator reviews it; 0 turns automatic hiding off.
+	ReportHideThreshold int
+	// SoftDeleteRetention is how long deleted blogs, comments and users can
+	// still be restored before the purge job removes them for good.
+	SoftDeleteRetention time.Duration
+	// ReactionTypes are the reactions users can leave on blogs.
+	ReactionTypes []domain.ReactionType
+	// ViewDedupeWindow is how long repeat views of a blog by the same user
+	// or address are not counted again.
+	ViewDedupeWindow time.Duration
+	// JWTAlgorithm signs new access tokens, EdDSA or RS256.
+	JWTAlgorithm string
+	JWTIssuer    string
+	JWTAudience  string
+	// JWTKeyRotation is how long a signing key signs before its successor
+	// takes over.
+	JWTKeyRotation time.Duration
+	// TOTPIssuer names the account in authenticator apps.
+	TOTPIssuer string
+	// TOTPSecret seals the TOTP secrets stored in the database. Secrets from
+	// before it existed were sealed with JWTSecret; they are resealed with
+	// TOTPSecret the next time their user signs in with a code, after which
+	// JWTSecret can be rotated without locking anyone out.
+	TOTPSecret string
+}
+
+// reactionTypePattern keeps reaction types usable as document field names.
+var reactionTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+// parseReactionTypes reads a comma separated list of reaction types. Like and
+// dislike are added when missing since older endpoints rely on them.
+func parseReactionTypes(value string) []domain.ReactionType {
+	types := []domain.ReactionType{domain.Like, domain.Dislike}
+	seen := map[domain.ReactionType]bool{domain.Like: true, domain.Dislike: true}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !reactionTypePattern.MatchString(name) {
+			log.Fatalf("REACTION_TYPES: %q is not a valid reaction type", name)
+		}
+		if reactionType := domain.ReactionType(name); !seen[reactionType] {
+			seen[reactionType] = true
+			types = append(types, reactionType)
+		}
+	}
+	return types
 }
 
 func LoadConfig() (*Config, error) {
@@ -81,15 +137,101 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	SearchIndexPath := os.Getenv("SEARCH_INDEX_PATH")
+	if SearchIndexPath == "" {
+		SearchIndexPath = "data/search.idx"
+	}
+
+	ReportHideThreshold := 5
+	if value := os.Getenv("REPORT_HIDE_THRESHOLD"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold < 0 {
+			log.Fatal("REPORT_HIDE_THRESHOLD must be a non-negative number")
+		}
+		ReportHideThreshold = threshold
+	}
+
+	SoftDeleteRetentionDays := 30
+	if value := os.Getenv("SOFT_DELETE_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			log.Fatal("SOFT_DELETE_RETENTION_DAYS must be a positive number")
+		}
+		SoftDeleteRetentionDays = days
+	}
+
+	ReactionTypes := domain.DefaultReactionTypes
+	if value := os.Getenv("REACTION_TYPES"); value != "" {
+		ReactionTypes = parseReactionTypes(value)
+	}
+
+	ViewDedupeMinutes := 30
+	if value := os.Getenv("VIEW_DEDUPE_MINUTES"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes < 1 {
+			log.Fatal("VIEW_DEDUPE_MINUTES must be a positive number")
+		}
+		ViewDedupeMinutes = minutes
+	}
+
+	JWTAlgorithm := domain.SigningEdDSA
+	if value := os.Getenv("JWT_ALGORITHM"); value != "" {
+		if value != domain.SigningEdDSA && value != domain.SigningRS256 {
+			log.Fatal("JWT_ALGORITHM must be EdDSA or RS256")
+		}
+		JWTAlgorithm = value
+	}
+
+	JWTIssuer := os.Getenv("JWT_ISSUER")
+	if JWTIssuer == "" {
+		JWTIssuer = "blog-backend"
+	}
+
+	JWTAudience := os.Getenv("JWT_AUDIENCE")
+	if JWTAudience == "" {
+		JWTAudience = "blog-backend-api"
+	}
+
+	JWTKeyRotationDays := 30
+	if value := os.Getenv("JWT_KEY_ROTATION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			log.Fatal("JWT_KEY_ROTATION_DAYS must be a positive number")
+		}
+		JWTKeyRotationDays = days
+	}
+
+	TOTPIssuer := os.Getenv("TOTP_ISSUER")
+	if TOTPIssuer == "" {
+		TOTPIssuer = "blog-backend"
+	}
+
+	TOTPSecret := os.Getenv("TOTP_SECRET")
+	if TOTPSecret == "" {
+		log.Println("TOTP_SECRET is not set; sealing TOTP secrets with JWT_SECRET")
+		TOTPSecret = JWTSecret
+	}
+
 	return &Config{
-		MongoURI:           MongoURI,
-		DBName:             DBName,
-		GoogleClientID:     GoogleClientID,
-		GoogleClientSecret: GoogleClientSecret,
-		JWTSecret:          JWTSecret,
-		GeminiAPIKey:       GeminiAPIKey,
-		Email:              Email,
-		AppPassword:        AppPassword,
-		RedisURL:           RedisUrl,
+		MongoURI:            MongoURI,
+		DBName:              DBName,
+		GoogleClientID:      GoogleClientID,
+		GoogleClientSecret:  GoogleClientSecret,
+		JWTSecret:           JWTSecret,
+		GeminiAPIKey:        GeminiAPIKey,
+		Email:               Email,
+		AppPassword:         AppPassword,
+		RedisURL:            RedisUrl,
+		SearchIndexPath:     SearchIndexPath,
+		ReportHideThreshold: ReportHideThreshold,
+		SoftDeleteRetention: time.Duration(SoftDeleteRetentionDays) * 24 * time.Hour,
+		ReactionTypes:       ReactionTypes,
+		ViewDedupeWindow:    time.Duration(ViewDedupeMinutes) * time.Minute,
+		JWTAlgorithm:        JWTAlgorithm,
+		JWTIssuer:           JWTIssuer,
+		JWTAudience:         JWTAudience,
+		JWTKeyRotation:      time.Duration(JWTKeyRotationDays) * 24 * time.Hour,
+		TOTPIssuer:          TOTPIssuer,
+		TOTPSecret:          TOTPSecret,
 	}, nil
 }
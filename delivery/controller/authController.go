@@ -3,9 +3,11 @@ package controller
 import (
 	"blog-backend/domain"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -66,16 +68,6 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie(
-		"status",
-		string(domain.Inactive),
-		int(time.Until(time.Now().Add(7 * 24 * time.Hour)).Seconds()),
-		"/",
-		"",
-		true,
-		true,
-	)
-
 	registeredUserResponse := signUpDetailResponseFromDomain(registerdUser)
 
 	c.JSON(http.StatusCreated, gin.H{"User": registeredUserResponse})
@@ -94,16 +86,6 @@ func (ac *AuthController) Activate(c *gin.Context) {
 		return 
 	}
 
-	c.SetCookie(
-		"status",
-		string(domain.Active),
-		int(time.Until(time.Now().Add(7 * 24 * time.Hour)).Seconds()),
-		"/",
-		"",
-		true,
-		true,
-	)
-
 	c.JSON(http.StatusOK, gin.H{"message":"Account activated."})
 }
 
@@ -119,7 +101,10 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	user, tokenPair, err := ac.AuthUseCase.Login(c, loginDetail.Email, loginDetail.Password)
+	user, tokenPair, err := ac.AuthUseCase.Login(c, loginDetail.Email, loginDetail.Password, sessionClient(c))
+	if accountRestricted(c, err) || twoFactorRequired(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -135,16 +120,6 @@ func (ac *AuthController) Login(c *gin.Context) {
 		true,
 	)
 
-	c.SetCookie(
-		"status",
-		string(user.Status),
-		int(time.Until(tokenPair.ExpiresIn).Seconds()),
-		"/",
-		"",
-		true,
-		true,
-	)
-
 	c.JSON(http.StatusOK, gin.H{
 		"User":      loginResponseFromDomain(user),
 		"TokenPair": tokenPair.AccessToken,
@@ -154,15 +129,22 @@ func (ac *AuthController) Login(c *gin.Context) {
 func (ac *AuthController) RefreshToken(c *gin.Context) {
 	// 1. Get the refresh token from the cookie
 	refreshToken, err := c.Cookie("refresh_token")
-	fmt.Println("Refresh Token:", refreshToken, err)
 	if err != nil || refreshToken == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token missing"})
 		return
 	}
 
 	// 2. Validate and process the refresh token using your usecase/service
-	user, tokenPair, err := ac.AuthUseCase.RefreshToken(c, refreshToken)
+	user, tokenPair, err := ac.AuthUseCase.RefreshToken(c, refreshToken, sessionClient(c))
 	if err != nil {
+		c.SetCookie("refresh_token", "", -1, "/", "", true, true)
+		if accountRestricted(c, err) {
+			return
+		}
+		if err == domain.ErrRefreshTokenReused {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used, the session has been revoked"})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
 		return
 	}
@@ -227,7 +209,10 @@ func (ac *AuthController) GoogleCallback(c *gin.Context) {
 		return
 	}
 
-	tokenPair, err := ac.AuthUseCase.IssueTokenPair(c, user)
+	tokenPair, err := ac.AuthUseCase.IssueTokenPair(c, user, sessionClient(c))
+	if accountRestricted(c, err) || twoFactorRequired(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token pair"})
 		return
@@ -249,6 +234,346 @@ func (ac *AuthController) GoogleCallback(c *gin.Context) {
     })
 }
 
+func (ac *AuthController) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	refreshToken, _ := c.Cookie("refresh_token")
+
+	sessions, err := ac.AuthUseCase.ListSessions(c, userID.(string), refreshToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions."})
+		return
+	}
+
+	response := make([]sessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = sessionResponseFromDomain(session)
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": response})
+}
+
+func (ac *AuthController) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	err := ac.AuthUseCase.RevokeSession(c, userID.(string), c.Param("id"))
+	if err != nil {
+		if err == domain.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked."})
+}
+
+// RevokeOtherSessions signs the user out of every session but the one the
+// request's refresh token cookie belongs to.
+func (ac *AuthController) RevokeOtherSessions(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	refreshToken, _ := c.Cookie("refresh_token")
+
+	revoked, err := ac.AuthUseCase.RevokeOtherSessions(c, userID.(string), refreshToken)
+	if err != nil {
+		if err == domain.ErrCurrentSessionUnknown {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// LogoutAll signs the user out of every session and rejects all of their
+// access tokens, the one used for this request included.
+func (ac *AuthController) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	if err := ac.AuthUseCase.LogoutAll(c, userID.(string)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out of all sessions."})
+		return
+	}
+	c.SetCookie("refresh_token", "", -1, "/", "", true, true)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions."})
+}
+
+// CompleteTwoFactorLogin exchanges the challenge a login answered with and
+// a TOTP or recovery code for a session.
+func (ac *AuthController) CompleteTwoFactorLogin(c *gin.Context) {
+	var request twoFactorLoginDTO
+	if err := c.ShouldBindJSON(&request); err != nil || request.Challenge == "" || request.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Challenge and code are required."})
+		return
+	}
+
+	user, tokenPair, err := ac.AuthUseCase.CompleteTwoFactorLogin(c, request.Challenge, request.Code, sessionClient(c))
+	if accountRestricted(c, err) {
+		return
+	}
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.SetCookie(
+		"refresh_token",
+		tokenPair.RefreshToken,
+		int(time.Until(tokenPair.ExpiresIn).Seconds()),
+		"/",
+		"",
+		true,
+		true,
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"User":      loginResponseFromDomain(user),
+		"TokenPair": tokenPair.AccessToken,
+	})
+}
+
+func (ac *AuthController) GetTwoFactorStatus(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	twoFactor, err := ac.AuthUseCase.GetTwoFactorStatus(c, userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get two-factor status."})
+		return
+	}
+	c.JSON(http.StatusOK, twoFactorResponseFromDomain(twoFactor))
+}
+
+// BeginTwoFactorEnrolment returns the secret to add to an authenticator app.
+// Two-factor authentication stays off until VerifyTwoFactor gets a code.
+func (ac *AuthController) BeginTwoFactorEnrolment(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	enrolment, err := ac.AuthUseCase.BeginTwoFactorEnrolment(c, userID.(string))
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           enrolment.Secret,
+		"provisioning_uri": enrolment.ProvisioningURI,
+	})
+}
+
+func (ac *AuthController) VerifyTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	code, ok := twoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := ac.AuthUseCase.ConfirmTwoFactorEnrolment(c, userID.(string), code)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled. Store the recovery codes somewhere safe, they are only shown once.",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func (ac *AuthController) DisableTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	code, ok := twoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	if err := ac.AuthUseCase.DisableTwoFactor(c, userID.(string), code); err != nil {
+		twoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled."})
+}
+
+func (ac *AuthController) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	code, ok := twoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := ac.AuthUseCase.RegenerateRecoveryCodes(c, userID.(string), code)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+// ResetTwoFactor turns off two-factor authentication for a user who lost
+// their authenticator and recovery codes.
+func (ac *AuthController) ResetTwoFactor(c *gin.Context) {
+	err := ac.AuthUseCase.ResetTwoFactor(c, c.Param("id"))
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset."})
+}
+
+// twoFactorRequired answers a login that needs a second factor with its
+// challenge, and reports whether err asked for one.
+func twoFactorRequired(c *gin.Context, err error) bool {
+	var required *domain.TwoFactorRequiredError
+	if !errors.As(err, &required) {
+		return false
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"two_factor_required": true,
+		"challenge":           required.Challenge,
+		"expires_at":          required.ExpiresAt,
+	})
+	return true
+}
+
+func twoFactorCode(c *gin.Context) (string, bool) {
+	var request twoFactorCodeDTO
+	if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required."})
+		return "", false
+	}
+	return request.Code, true
+}
+
+func twoFactorError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrInvalidTwoFactorCode, domain.ErrInvalidTwoFactorChallenge:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case domain.ErrTwoFactorLocked:
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case domain.ErrTwoFactorAlreadyEnabled, domain.ErrTwoFactorNotEnrolled:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case domain.ErrTwoFactorNotEnabled:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case domain.ErrInvalidUserID:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Two-factor request failed."})
+	}
+}
+
+// accountRestricted answers with why a suspended or banned user cannot sign
+// in, and reports whether err was such a refusal.
+func accountRestricted(c *gin.Context, err error) bool {
+	var restricted *domain.AccountRestrictedError
+	if !errors.As(err, &restricted) {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":  restricted.Error(),
+		"status": restricted.Status,
+		"reason": restricted.Reason,
+		"until":  restricted.Until,
+	})
+	return true
+}
+
+// sessionClient describes the device of the request. Clients may name
+// themselves with X-Device-Name, otherwise the name is guessed from the
+// user agent.
+func sessionClient(c *gin.Context) *domain.SessionClient {
+	userAgent := c.Request.UserAgent()
+	device := strings.TrimSpace(c.GetHeader("X-Device-Name"))
+	if device == "" {
+		device = deviceName(userAgent)
+	}
+	if len(device) > 100 {
+		device = device[:100]
+	}
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	return &domain.SessionClient{
+		Device:    device,
+		UserAgent: userAgent,
+		IP:        c.ClientIP(),
+	}
+}
+
+// deviceName turns a user agent into something like "Firefox on Windows".
+func deviceName(userAgent string) string {
+	platform := ""
+	for _, p := range []struct{ marker, name string }{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, p.marker) {
+			platform = p.name
+			break
+		}
+	}
+
+	browser := ""
+	for _, b := range []struct{ marker, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, b.marker) {
+			browser = b.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	}
+	return "Unknown device"
+}
+
 // DTO
 type signUpDTO struct {
 	Username string `json:"username"`
@@ -261,6 +586,15 @@ type loginDTO struct {
 	Password string `json:"password"`
 }
 
+type twoFactorLoginDTO struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+type twoFactorCodeDTO struct {
+	Code string `json:"code"`
+}
+
 type loginResponse struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
@@ -283,13 +617,53 @@ type signUpDetailResponse struct {
 	ContactInfo    string `json:"contact_info"`
 }
 
+type sessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type twoFactorResponse struct {
+	Enabled           bool       `json:"enabled"`
+	Pending           bool       `json:"pending"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+}
+
+func twoFactorResponseFromDomain(t *domain.TwoFactor) twoFactorResponse {
+	return twoFactorResponse{
+		Enabled:           t.Enabled,
+		Pending:           !t.Enabled && len(t.PendingSecret) > 0,
+		RecoveryCodesLeft: t.RecoveryCodesLeft,
+		EnabledAt:         t.EnabledAt,
+	}
+}
+
+func sessionResponseFromDomain(s *domain.Session) sessionResponse {
+	return sessionResponse{
+		ID:         s.ID,
+		Device:     s.Device,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    s.Current,
+	}
+}
+
 func loginResponseFromDomain(u *domain.User) loginResponse {
 	return loginResponse{
 		ID:       u.ID,
 		Username: u.Username,
 		Email:    u.Email,
 		Role:     string(u.Role),
-		Status:   string(u.Status),
+		Status:   string(u.EffectiveStatus(time.Now())),
 	}
 }
 
@@ -340,14 +714,14 @@ func emailValidator(email string) error {
 func (ac *AuthController)Logout(c *gin.Context ) {
 	RefreshToken, err := c.Cookie("refresh_token")
 	
-	fmt.Println("Refresh Token:", RefreshToken, err)
 	if err != nil || RefreshToken == "" {
 		c.JSON(400, gin.H{"error": "Refresh token missing"})
 		return
 	}
 	c.SetCookie("refresh_token", "", -1, "/", "", true, true)
 
-	err = ac.AuthUseCase.Logout(c, RefreshToken)
+	accessToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	err = ac.AuthUseCase.Logout(c, RefreshToken, accessToken)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to logout user."})
 		return
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -36,6 +37,10 @@ func (bc *BlogController) CreateBlog(c *gin.Context) {
 	blog := DtoToDomain(&blogDTO, userID.(string))
 	createdBlog, err := bc.BlogUseCase.CreateBlog(c, blog)
 	if err != nil {
+		if err == domain.ErrInvalidBlogStatus || err == domain.ErrInvalidPublishTime {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create blog", "details": err.Error()})
 		return
 	}
@@ -53,9 +58,10 @@ func (bc *BlogController) DeleteBlogByAuth(c *gin.Context) {
 		return
 	}
 	if !isAuth {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "your aren't authorized to delete the blog."})
+		c.JSON(http.StatusForbidden, gin.H{"error": "your aren't authorized to delete the blog."})
+		return
 	}
-	err = bc.BlogUseCase.DeleteBlog(c, blogID)
+	err = bc.BlogUseCase.DeleteBlog(c, blogID, userID.(string))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blog."})
 		return
@@ -67,64 +73,148 @@ func (bc *BlogController) DeleteBlogByAuth(c *gin.Context) {
 // since the middleware does the autherization we don't have to check
 func (bc *BlogController) DeleteBlogByAdmin(c *gin.Context) {
 	blogID := c.Param("id")
-	err := bc.BlogUseCase.DeleteBlog(c, blogID)
+	adminID, _ := c.Get("x-user-id")
+	err := bc.BlogUseCase.DeleteBlog(c, blogID, adminID.(string))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blog."})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Blog deleted successfully."})
 }
+
+func (bc *BlogController) RestoreBlog(c *gin.Context) {
+	err := bc.BlogUseCase.RestoreBlog(c, c.Param("id"))
+	if err == domain.ErrBlogNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No deleted blog with this id."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore blog."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Blog restored successfully."})
+}
 func (bc *BlogController) SearchBlogs(c *gin.Context) {
-	query := c.Query("q")
-	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required."})
+	cursor, limit := pageParams(c)
+	query := domain.SearchQuery{
+		Text:     c.Query("q"),
+		AuthorID: c.Query("author"),
+		Sort:     domain.SearchSort(c.Query("sort")),
+		Cursor:   cursor,
+		Limit:    limit,
+	}
+	for _, tag := range strings.Split(c.Query("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			query.Tags = append(query.Tags, tag)
+		}
+	}
+
+	var err error
+	if query.From, err = parseSearchDate(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' date."})
 		return
 	}
+	if query.To, err = parseSearchDate(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' date."})
+		return
+	}
+	if minLikes := c.Query("min_likes"); minLikes != "" {
+		if query.MinLikes, err = strconv.Atoi(minLikes); err != nil || query.MinLikes < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'min_likes' value."})
+			return
+		}
+	}
 
-	blogs, err := bc.BlogUseCase.SearchBlogs(c, query)
+	results, next, err := bc.BlogUseCase.SearchBlogs(c, query)
 	if err != nil {
+		if err == domain.ErrInvalidCursor || err == domain.ErrInvalidSearchSort || err == domain.ErrEmptySearch {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search blogs."})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"blogs": blogs})
+	c.JSON(http.StatusOK, gin.H{"results": results, "next_cursor": next})
+}
+
+// Trending lists the blogs with the most recent activity, with ?window=
+// 24h, 7d or 30d and optionally ?tags=a,b.
+func (bc *BlogController) Trending(c *gin.Context) {
+	cursor, limit := pageParams(c)
+	var tags []string
+	for _, tag := range strings.Split(c.Query("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	blogs, next, err := bc.BlogUseCase.GetTrending(c, domain.TrendingWindow(c.Query("window")), tags, cursor, limit)
+	if err != nil {
+		if err == domain.ErrInvalidTrendingWindow || err == domain.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trending blogs."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blogs": blogs, "next_cursor": next})
+}
+
+// parseSearchDate accepts either a full RFC3339 timestamp or a plain date.
+// A plain date used as an upper bound covers the whole day.
+func parseSearchDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
 }
 
 func (bc *BlogController) GetBlogsByUserID(c *gin.Context) {
 	userID := c.Param("id")
+	cursor, limit := pageParams(c)
 
-	blogs, err := bc.BlogUseCase.GetBlogsByUserID(c, userID)
+	blogs, next, err := bc.BlogUseCase.GetBlogsByUserID(c, userID, cursor, limit)
 	if err != nil {
+		if err == domain.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user's blogs."})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"blogs": blogs})
+	c.JSON(http.StatusOK, gin.H{"blogs": blogs, "next_cursor": next})
 }
 
 func (bc *BlogController) ListBlogs(c *gin.Context) {
-	p := c.Query("page")
-	l := c.Query("limit")
 	field := c.Query("field")
 	if field == "" {
 		field = "created_at"
 	}
-	page, err := strconv.Atoi(p)
-	if err != nil || p == "" {
-		page = 1
-	}
-	limit, err := strconv.Atoi(l)
-	if err != nil || l == "" {
-		limit = 10
-	}
-	blogs, total, err := bc.BlogUseCase.ListBlogs(c, page, limit, field)
+	cursor, limit := pageParams(c)
+	blogs, next, err := bc.BlogUseCase.ListBlogs(c, cursor, limit, field)
 	if err != nil {
+		if err == domain.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blogs."})
 		return
 	}
-	totalPages := (total + int64(limit) - 1) / int64(limit)
 
-	c.JSON(http.StatusOK, gin.H{"blogs": blogs, "total": totalPages, "page": page, "limit": limit})
+	c.JSON(http.StatusOK, gin.H{"blogs": blogs, "next_cursor": next, "limit": limit})
 }
 
 func (bc *BlogController) GetBlog(c *gin.Context) {
@@ -134,21 +224,255 @@ func (bc *BlogController) GetBlog(c *gin.Context) {
 	}
 	userID, exists := c.Get("x-user-id")
 	log.Println(userID)
-	if exists{
-		err := bc.BlogUseCase.AddReadHistory(c, userID.(string), id)
-		log.Println("sent to usecase")
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to add read history."})
+	blog, err := bc.BlogUseCase.GetBlog(c, id)
+	if err == domain.ErrBlogNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blog."})
+		return
+	}
+	// unpublished and hidden blogs are only visible to their author and admins
+	if !blog.IsVisible() {
+		role, _ := c.Get("x-user-role")
+		if !exists || (userID.(string) != blog.AuthorID && role != string(domain.Admin)) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
 			return
 		}
+	}
+	if blog.IsVisible() {
+		viewer := "ip:" + c.ClientIP()
+		if exists {
+			viewer = "user:" + userID.(string)
+		}
+		if err := bc.BlogUseCase.RecordView(c, id, viewer); err != nil {
+			log.Printf("Failed to record view of blog %s: %v", id, err)
+		}
+		// drafts and hidden blogs the author or an admin opens stay out of
+		// the reading history, like they stay out of the view counts
+		if exists {
+			err := bc.BlogUseCase.AddReadHistory(c, userID.(string), id)
+			log.Println("sent to usecase")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to add read history."})
+				return
+			}
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"blog": blog})
+}
 
+func (bc *BlogController) GetMyBlogs(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
 	}
-	blog, err := bc.BlogUseCase.GetBlog(c, id)
+
+	status := domain.BlogStatus(c.Query("status"))
+	cursor, limit := pageParams(c)
+	blogs, next, err := bc.BlogUseCase.GetMyBlogs(c, userID.(string), status, cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blog."})
+		if err == domain.ErrInvalidBlogStatus || err == domain.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch your blogs."})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"blog": blog})
+
+	c.JSON(http.StatusOK, gin.H{"blogs": blogs, "next_cursor": next})
+}
+
+func (bc *BlogController) PublishBlog(c *gin.Context) {
+	bc.changeBlogStatus(c, domain.BlogPublished, time.Time{})
+}
+
+func (bc *BlogController) UnpublishBlog(c *gin.Context) {
+	bc.changeBlogStatus(c, domain.BlogDraft, time.Time{})
+}
+
+func (bc *BlogController) ArchiveBlog(c *gin.Context) {
+	bc.changeBlogStatus(c, domain.BlogArchived, time.Time{})
+}
+
+func (bc *BlogController) ScheduleBlog(c *gin.Context) {
+	var scheduleDTO ScheduleDTO
+	if err := c.ShouldBindJSON(&scheduleDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. publish_at is required"})
+		return
+	}
+	bc.changeBlogStatus(c, domain.BlogScheduled, scheduleDTO.PublishAt)
+}
+
+func (bc *BlogController) changeBlogStatus(c *gin.Context, status domain.BlogStatus, publishAt time.Time) {
+	blogID := c.Param("id")
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	allowed, err := bc.canManageBlog(c, blogID)
+	if err == domain.ErrBlogNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify author."})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or an admin can change the blog's status."})
+		return
+	}
+
+	err = bc.BlogUseCase.ChangeBlogStatus(c, blogID, userID.(string), status, publishAt)
+	switch err {
+	case nil:
+	case domain.ErrInvalidBlogStatus, domain.ErrInvalidPublishTime:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case domain.ErrBlogNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update blog status."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Blog status updated successfully.", "status": status})
+}
+
+// canManageBlog reports whether the caller is the blog's author or an admin.
+func (bc *BlogController) canManageBlog(c *gin.Context, blogID string) (bool, error) {
+	role, _ := c.Get("x-user-role")
+	if role == string(domain.Admin) {
+		return true, nil
+	}
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		return false, nil
+	}
+	return bc.BlogUseCase.IsBlogAuthor(c, blogID, userID.(string))
+}
+
+func (bc *BlogController) ListRevisions(c *gin.Context) {
+	blogID := c.Param("id")
+	allowed, err := bc.canManageBlog(c, blogID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify author."})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or an admin can view revisions."})
+		return
+	}
+
+	revisions, err := bc.BlogUseCase.GetRevisions(c, blogID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+func (bc *BlogController) GetRevision(c *gin.Context) {
+	blogID := c.Param("id")
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision version."})
+		return
+	}
+	allowed, err := bc.canManageBlog(c, blogID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify author."})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or an admin can view revisions."})
+		return
+	}
+
+	revision, err := bc.BlogUseCase.GetRevision(c, blogID, version)
+	if err != nil {
+		if err == domain.ErrRevisionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revision."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revision": revision})
+}
+
+func (bc *BlogController) DiffRevisions(c *gin.Context) {
+	blogID := c.Param("id")
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'from' must be a revision version."})
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil || to <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'to' must be a revision version."})
+		return
+	}
+	allowed, err := bc.canManageBlog(c, blogID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify author."})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or an admin can view revisions."})
+		return
+	}
+
+	diff, err := bc.BlogUseCase.DiffRevisions(c, blogID, from, to)
+	if err != nil {
+		if err == domain.ErrRevisionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to diff revisions."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"diff": diff})
+}
+
+func (bc *BlogController) RestoreRevision(c *gin.Context) {
+	blogID := c.Param("id")
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision version."})
+		return
+	}
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	err = bc.BlogUseCase.RestoreRevision(c, blogID, userID.(string), version)
+	if err != nil {
+		switch err {
+		case domain.ErrRevisionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case domain.ErrNothingToRestore:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case domain.ErrConcurrentEdit:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision.", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Revision restored successfully."})
 }
 
 func (bc *BlogController) UpdateBlog(c *gin.Context) {
@@ -186,6 +510,10 @@ func (bc *BlogController) UpdateBlog(c *gin.Context) {
 	}
 
 	err := bc.BlogUseCase.UpdateBlog(c, id, userIDStr, updatesMap)
+	if err == domain.ErrConcurrentEdit {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update blog."})
 		return
@@ -202,6 +530,10 @@ func (bc *BlogController) RemoveReaction(c *gin.Context) {
 	}
 
 	err := bc.BlogUseCase.RemoveReaction(c, blogID, userID.(string))
+	if err == domain.ErrReactionNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove reaction", "details": err.Error()})
 		return
@@ -228,14 +560,51 @@ func (bc *BlogController) CreateComment(c *gin.Context) {
 		return
 	}
 	comment := CommentDtoToDomain(&commentDTO, blogID, userID.(string))
-	_, err := bc.BlogUseCase.AddComment(c, comment)
+	created, err := bc.BlogUseCase.AddComment(c, comment)
 	
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to add you comment"})
+		switch err {
+		case domain.ErrCommentNotFound, domain.ErrInvalidParentComment, domain.ErrCommentTooDeep:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case domain.ErrBlogNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to add you comment"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "comment added successfully!", "comment": created})
+
+}
+
+func (bc *BlogController) EditComment(c *gin.Context) {
+	commentID := c.Param("commentId")
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var editDTO CommentEditDTO
+	if err := c.ShouldBindJSON(&editDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request. Comment is required"})
+		return
+	}
+
+	comment, err := bc.BlogUseCase.EditComment(c, c.Param("id"), commentID, userID.(string), editDTO.Content)
+	if err != nil {
+		switch err {
+		case domain.ErrUserNotAuthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "you can only edit your own comments."})
+		case domain.ErrCommentNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to edit comment."})
+		}
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"message": "comment added successfully!"})
 
+	c.JSON(http.StatusOK, gin.H{"comment": comment})
 }
 
 func (bc *BlogController) ListAllComments(c *gin.Context) {
@@ -245,14 +614,129 @@ func (bc *BlogController) ListAllComments(c *gin.Context) {
 		return
 	}
 
-	comments, err := bc.BlogUseCase.GetComments(c, blogID)
+	cursor, limit := pageParams(c)
+	sort := domain.CommentSort(c.Query("sort"))
+
+	comments, next, err := bc.BlogUseCase.GetComments(c, blogID, c.GetString("x-user-id"), c.GetString("x-user-role"), sort, cursor, limit)
 	if err != nil {
+		if err == domain.ErrInvalidCursor || err == domain.ErrInvalidCommentSort {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == domain.ErrBlogNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments", "details": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"comments": comments})
+	c.JSON(http.StatusOK, gin.H{"comments": comments, "next_cursor": next})
+
+}
 
+type reactionRequest struct {
+	Type string `json:"type" binding:"required"`
+}
+
+// React sets the caller's reaction to any of the configured types, replacing
+// an earlier one.
+func (bc *BlogController) React(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	var req reactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reaction type is required."})
+		return
+	}
+
+	err := bc.BlogUseCase.AddReaction(c, c.Param("id"), userID.(string), req.Type)
+	if err == domain.ErrInvalidReactionType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "allowed": bc.BlogUseCase.ReactionTypes()})
+		return
+	}
+	if err == domain.ErrBlogNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reaction."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reaction added successfully."})
+}
+
+// ReactToComment sets the caller's reaction to a comment, replacing an
+// earlier one.
+func (bc *BlogController) ReactToComment(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	var req reactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reaction type is required."})
+		return
+	}
+
+	err := bc.BlogUseCase.ReactToComment(c, c.Param("id"), c.Param("commentId"), userID.(string), req.Type)
+	switch err {
+	case nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Reaction added successfully."})
+	case domain.ErrInvalidReactionType:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "allowed": bc.BlogUseCase.ReactionTypes()})
+	case domain.ErrBlogNotFound, domain.ErrCommentNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reaction."})
+	}
+}
+
+func (bc *BlogController) RemoveCommentReaction(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	err := bc.BlogUseCase.RemoveCommentReaction(c, c.Param("id"), c.Param("commentId"), userID.(string))
+	switch err {
+	case nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Reaction removed successfully"})
+	case domain.ErrCommentNotFound, domain.ErrReactionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove reaction"})
+	}
+}
+
+// ListReactions lists who reacted to the blog, newest first, optionally only
+// with ?type=.
+func (bc *BlogController) ListReactions(c *gin.Context) {
+	cursor, limit := pageParams(c)
+	reactionType := domain.ReactionType(c.Query("type"))
+
+	reactions, next, err := bc.BlogUseCase.GetReactions(c, c.Param("id"), reactionType, cursor, limit)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidReactionType:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "allowed": bc.BlogUseCase.ReactionTypes()})
+		case domain.ErrInvalidCursor:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case domain.ErrBlogNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reactions."})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reactions": reactions, "next_cursor": next})
 }
 
 func (bc *BlogController) LikeBlog(c *gin.Context) {
@@ -269,6 +753,10 @@ func (bc *BlogController) LikeBlog(c *gin.Context) {
 	}
 
 	err := bc.BlogUseCase.AddReaction(c, blogID, userID.(string), string(domain.Like))
+	if err == domain.ErrBlogNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to add reaction."})
 		return
@@ -291,6 +779,10 @@ func (bc *BlogController) DislikeBlog(c *gin.Context) {
 	}
 
 	err := bc.BlogUseCase.AddReaction(c, blogID, userID.(string), string(domain.Dislike))
+	if err == domain.ErrBlogNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reaction. error: " + err.Error()})
 		return
@@ -301,13 +793,34 @@ func (bc *BlogController) DislikeBlog(c *gin.Context) {
 
 func (bc BlogController) DeleteCommentByAdmin(c *gin.Context) {
 	comId := c.Param("id")
-	err := bc.BlogUseCase.RemoveComment(c, comId)
+	adminID, _ := c.Get("x-user-id")
+	err := bc.BlogUseCase.RemoveComment(c, comId, adminID.(string))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"Message": "comment deleted successfully"})
 }
+
+func (bc BlogController) RestoreComment(c *gin.Context) {
+	comment, err := bc.BlogUseCase.RestoreComment(c, c.Param("id"), c.Param("commentId"))
+	switch err {
+	case nil:
+	case domain.ErrBlogNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case domain.ErrCommentNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "No deleted comment with this id on the blog."})
+		return
+	case domain.ErrParentCommentDeleted:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore comment."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"comment": comment})
+}
 func (bc BlogController) DeleteCommentByAuth(c *gin.Context) {
 	userId, _ := c.Get("x-user-id")
 	comId := c.Param("id")
@@ -318,8 +831,9 @@ func (bc BlogController) DeleteCommentByAuth(c *gin.Context) {
 	}
 	if !isAuth {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "your aren't authorized to delete the comment."})
+		return
 	}
-	err = bc.BlogUseCase.RemoveComment(c, comId)
+	err = bc.BlogUseCase.RemoveComment(c, comId, userId.(string))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment."})
 		return
@@ -329,29 +843,25 @@ func (bc BlogController) DeleteCommentByAuth(c *gin.Context) {
 }
 
 
-func (bc *BlogController) GetRecommendations(c *gin.Context){
-	userID, exists := c.Get("x-user-id")
-	if !exists{
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "you are not authorized to get recommendations"})
-		return 
-	}
-	blogs, err := bc.BlogUseCase.GetRecommendations(c, userID.(string))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recommendations."})
-		return
-	}
-	c.IndentedJSON(http.StatusOK, gin.H{"Blogs Recommended": blogs})
-	
-}
-
 type CommentDTO struct{
 		Content string `json:"content" binding:"required"`
+		ParentID string `json:"parent_id,omitempty"`
+}
+
+type CommentEditDTO struct {
+	Content string `json:"content" binding:"required"`
 }
 
 type BlogDTO struct {
 	Title   string   `json:"title" binding:"required"`
 	Content string   `json:"content" binding:"required"`
 	Tags    []string `json:"tags" binding:"required"`
+	Status    string    `json:"status,omitempty"`
+	PublishAt time.Time `json:"publish_at,omitempty"`
+}
+
+type ScheduleDTO struct {
+	PublishAt time.Time `json:"publish_at" binding:"required"`
 }
 
 func DtoToDomain(blogDTO *BlogDTO, authorID string) *domain.Blog {
@@ -360,6 +870,8 @@ func DtoToDomain(blogDTO *BlogDTO, authorID string) *domain.Blog {
 		Content:      blogDTO.Content,
 		AuthorID:     authorID,
 		Tags:         blogDTO.Tags,
+		Status:       domain.BlogStatus(blogDTO.Status),
+		PublishAt:    blogDTO.PublishAt,
 		ViewCount:    0,
 		LikeCount:    0,
 		DislikeCount: 0,
@@ -371,6 +883,7 @@ func CommentDtoToDomain(commentDTO *CommentDTO, blogID, authorID string) *domain
 	return &domain.Comment{
 		BlogID:    blogID,
 		AuthorID:  authorID,
+		ParentID:  commentDTO.ParentID,
 		Content:   commentDTO.Content,
 		CreatedAt: time.Now(),}
 }
@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"blog-backend/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookmarkController struct {
+	BookmarkUseCase domain.IBookmarkUseCase
+}
+
+func NewBookmarkController(bu domain.IBookmarkUseCase) *BookmarkController {
+	return &BookmarkController{
+		BookmarkUseCase: bu,
+	}
+}
+
+func (bc *BookmarkController) GetBookmarks(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+	cursor, limit := pageParams(c)
+
+	bookmarks, next, err := bc.BookmarkUseCase.GetBookmarks(c, userID.(string), c.Query("list"), cursor, limit)
+	if err != nil {
+		bookmarkError(c, err, "Failed to fetch bookmarks.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bookmarks": bookmarks, "next_cursor": next})
+}
+
+func (bc *BookmarkController) AddBookmark(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var bookmarkDTO BookmarkDTO
+	if err := c.ShouldBindJSON(&bookmarkDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. blog_id is required."})
+		return
+	}
+
+	bookmark, err := bc.BookmarkUseCase.AddBookmark(c, userID.(string), bookmarkDTO.BlogID, bookmarkDTO.ListID, bookmarkDTO.Note)
+	if err != nil {
+		bookmarkError(c, err, "Failed to save blog.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"bookmark": bookmark})
+}
+
+func (bc *BookmarkController) UpdateBookmark(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var updateDTO BookmarkUpdateDTO
+	if err := c.ShouldBindJSON(&updateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body."})
+		return
+	}
+
+	bookmark, err := bc.BookmarkUseCase.UpdateBookmark(c, userID.(string), c.Param("blogId"), domain.BookmarkUpdate{
+		ListID: updateDTO.ListID,
+		Note:   updateDTO.Note,
+	})
+	if err != nil {
+		bookmarkError(c, err, "Failed to update bookmark.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bookmark": bookmark})
+}
+
+func (bc *BookmarkController) RemoveBookmark(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	if err := bc.BookmarkUseCase.RemoveBookmark(c, userID.(string), c.Param("blogId")); err != nil {
+		bookmarkError(c, err, "Failed to remove bookmark.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bookmark removed successfully."})
+}
+
+func (bc *BookmarkController) GetReadingLists(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	lists, err := bc.BookmarkUseCase.GetReadingLists(c, userID.(string))
+	if err != nil {
+		bookmarkError(c, err, "Failed to fetch reading lists.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lists": lists})
+}
+
+func (bc *BookmarkController) CreateReadingList(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var listDTO ReadingListDTO
+	if err := c.ShouldBindJSON(&listDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. name is required."})
+		return
+	}
+
+	list, err := bc.BookmarkUseCase.CreateReadingList(c, userID.(string), listDTO.Name, listDTO.Description)
+	if err != nil {
+		bookmarkError(c, err, "Failed to create reading list.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"list": list})
+}
+
+func (bc *BookmarkController) UpdateReadingList(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var listDTO ReadingListUpdateDTO
+	if err := c.ShouldBindJSON(&listDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body."})
+		return
+	}
+
+	list, err := bc.BookmarkUseCase.UpdateReadingList(c, userID.(string), c.Param("listId"), domain.ReadingListUpdate{
+		Name:        listDTO.Name,
+		Description: listDTO.Description,
+	})
+	if err != nil {
+		bookmarkError(c, err, "Failed to update reading list.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"list": list})
+}
+
+func (bc *BookmarkController) DeleteReadingList(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	if err := bc.BookmarkUseCase.DeleteReadingList(c, userID.(string), c.Param("listId")); err != nil {
+		bookmarkError(c, err, "Failed to delete reading list.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reading list deleted successfully."})
+}
+
+func (bc *BookmarkController) ReorderReadingList(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var orderDTO ReadingListOrderDTO
+	if err := c.ShouldBindJSON(&orderDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. blog_ids is required."})
+		return
+	}
+
+	if err := bc.BookmarkUseCase.ReorderReadingList(c, userID.(string), c.Param("listId"), orderDTO.BlogIDs); err != nil {
+		bookmarkError(c, err, "Failed to reorder reading list.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reading list reordered successfully."})
+}
+
+func bookmarkError(c *gin.Context, err error, message string) {
+	switch err {
+	case domain.ErrBlogNotFound, domain.ErrBookmarkNotFound, domain.ErrReadingListNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case domain.ErrAlreadyBookmarked, domain.ErrReadingListExists:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case domain.ErrDefaultReadingList:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case domain.ErrInvalidCursor, domain.ErrInvalidReadingListName, domain.ErrInvalidBookmarkOrder:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+type BookmarkDTO struct {
+	BlogID string `json:"blog_id" binding:"required"`
+	ListID string `json:"list_id,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+type BookmarkUpdateDTO struct {
+	ListID *string `json:"list_id,omitempty"`
+	Note   *string `json:"note,omitempty"`
+}
+
+type ReadingListDTO struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+type ReadingListUpdateDTO struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type ReadingListOrderDTO struct {
+	BlogIDs []string `json:"blog_ids" binding:"required"`
+}
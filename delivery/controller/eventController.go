@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"blog-backend/domain"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseHeartbeat keeps idle streams from being closed by proxies.
+const sseHeartbeat = 25 * time.Second
+
+type EventController struct {
+	EventUseCase domain.IEventUseCase
+}
+
+func NewEventController(eu domain.IEventUseCase) *EventController {
+	return &EventController{
+		EventUseCase: eu,
+	}
+}
+
+func (ec *EventController) StreamBlog(c *gin.Context) {
+	userID := c.GetString("x-user-id")
+	role := c.GetString("x-user-role")
+
+	events, err := ec.EventUseCase.SubscribeBlog(c.Request.Context(), c.Param("id"), userID, role)
+	if err == domain.ErrBlogNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open blog stream."})
+		return
+	}
+
+	streamEvents(c, events)
+}
+
+func (ec *EventController) StreamNotifications(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	events, err := ec.EventUseCase.SubscribeUser(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open notification stream."})
+		return
+	}
+
+	streamEvents(c, events)
+}
+
+// streamEvents writes events as Server-Sent Events until the client goes away
+// or the subscription ends.
+func streamEvents(c *gin.Context, events <-chan *domain.LiveEvent) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event.Data)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"blog-backend/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowController struct {
+	FollowUseCase domain.IFollowUseCase
+}
+
+func NewFollowController(fu domain.IFollowUseCase) *FollowController {
+	return &FollowController{
+		FollowUseCase: fu,
+	}
+}
+
+func (fc *FollowController) Follow(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	if err := fc.FollowUseCase.Follow(c, userID.(string), c.Param("id")); err != nil {
+		followError(c, err, "Failed to follow user.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User followed successfully."})
+}
+
+func (fc *FollowController) Unfollow(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	if err := fc.FollowUseCase.Unfollow(c, userID.(string), c.Param("id")); err != nil {
+		followError(c, err, "Failed to unfollow user.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unfollowed successfully."})
+}
+
+func (fc *FollowController) GetFollowers(c *gin.Context) {
+	cursor, limit := pageParams(c)
+
+	users, next, err := fc.FollowUseCase.GetFollowers(c, c.Param("id"), cursor, limit)
+	if err != nil {
+		followError(c, err, "Failed to fetch followers.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"followers": users, "next_cursor": next})
+}
+
+func (fc *FollowController) GetFollowing(c *gin.Context) {
+	cursor, limit := pageParams(c)
+
+	users, next, err := fc.FollowUseCase.GetFollowing(c, c.Param("id"), cursor, limit)
+	if err != nil {
+		followError(c, err, "Failed to fetch followed users.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"following": users, "next_cursor": next})
+}
+
+func (fc *FollowController) GetFeed(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+	cursor, limit := pageParams(c)
+
+	items, next, err := fc.FollowUseCase.GetFeed(c, userID.(string), cursor, limit)
+	if err != nil {
+		followError(c, err, "Failed to fetch feed.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items, "next_cursor": next})
+}
+
+func followError(c *gin.Context, err error, message string) {
+	switch err {
+	case domain.ErrUserNotFound, domain.ErrNotFollowing:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case domain.ErrAlreadyFollowing:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case domain.ErrCannotFollowSelf, domain.ErrInvalidCursor:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
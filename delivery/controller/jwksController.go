@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"blog-backend/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSController struct {
+	JWTService domain.IJWTService
+}
+
+func NewJWKSController(jwtService domain.IJWTService) *JWKSController {
+	return &JWKSController{
+		JWTService: jwtService,
+	}
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// GetJWKS publishes the keys access tokens are verified with. New keys are
+// listed well before they sign, so a few minutes of caching is safe.
+func (kc *JWKSController) GetJWKS(c *gin.Context) {
+	keys := kc.JWTService.PublicKeys()
+	response := make([]jsonWebKey, len(keys))
+	for i, key := range keys {
+		response[i] = jsonWebKey{
+			KeyType:   key.KeyType,
+			KeyID:     key.KeyID,
+			Use:       key.Use,
+			Algorithm: key.Algorithm,
+			Curve:     key.Curve,
+			X:         key.X,
+			N:         key.N,
+			E:         key.E,
+		}
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": response})
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"blog-backend/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationController struct {
+	ModerationUseCase domain.IModerationUseCase
+}
+
+func NewModerationController(mu domain.IModerationUseCase) *ModerationController {
+	return &ModerationController{
+		ModerationUseCase: mu,
+	}
+}
+
+func (mc *ModerationController) ReportBlog(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var reportDTO ReportDTO
+	if err := c.ShouldBindJSON(&reportDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. reason is required."})
+		return
+	}
+
+	report, err := mc.ModerationUseCase.ReportBlog(c, userID.(string), c.Param("id"), domain.ReportReason(reportDTO.Reason), reportDTO.Details)
+	if err != nil {
+		moderationError(c, err, "Failed to report blog.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"report": report})
+}
+
+func (mc *ModerationController) ReportComment(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var reportDTO ReportDTO
+	if err := c.ShouldBindJSON(&reportDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. reason is required."})
+		return
+	}
+
+	report, err := mc.ModerationUseCase.ReportComment(c, userID.(string), c.Param("id"), c.Param("commentId"), domain.ReportReason(reportDTO.Reason), reportDTO.Details)
+	if err != nil {
+		moderationError(c, err, "Failed to report comment.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"report": report})
+}
+
+func (mc *ModerationController) ListReports(c *gin.Context) {
+	cursor, limit := pageParams(c)
+	status := c.DefaultQuery("status", string(domain.ReportOpen))
+	if status == "all" {
+		status = ""
+	}
+	filter := domain.ReportFilter{
+		Status:     domain.ReportStatus(status),
+		TargetType: domain.ReportTargetType(c.Query("target_type")),
+	}
+
+	reports, next, err := mc.ModerationUseCase.ListReports(c, filter, cursor, limit)
+	if err != nil {
+		moderationError(c, err, "Failed to fetch reports.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reports": reports, "next_cursor": next})
+}
+
+func (mc *ModerationController) GetReport(c *gin.Context) {
+	report, err := mc.ModerationUseCase.GetReport(c, c.Param("id"))
+	if err != nil {
+		moderationError(c, err, "Failed to fetch report.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+func (mc *ModerationController) ResolveReport(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var resolveDTO ResolveReportDTO
+	if err := c.ShouldBindJSON(&resolveDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. action is required."})
+		return
+	}
+
+	report, err := mc.ModerationUseCase.ResolveReport(c, c.Param("id"), userID.(string), domain.ModerationAction(resolveDTO.Action), resolveDTO.Note)
+	if err != nil {
+		moderationError(c, err, "Failed to resolve report.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+func (mc *ModerationController) UnhideBlog(c *gin.Context) {
+	if err := mc.ModerationUseCase.UnhideBlog(c, c.Param("id")); err != nil {
+		moderationError(c, err, "Failed to unhide blog.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Blog is visible again."})
+}
+
+func (mc *ModerationController) UnhideComment(c *gin.Context) {
+	if err := mc.ModerationUseCase.UnhideComment(c, c.Param("id"), c.Param("commentId")); err != nil {
+		moderationError(c, err, "Failed to unhide comment.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment is visible again."})
+}
+
+func moderationError(c *gin.Context, err error, message string) {
+	switch err {
+	case domain.ErrBlogNotFound, domain.ErrCommentNotFound, domain.ErrReportNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case domain.ErrAlreadyReported, domain.ErrReportResolved:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case domain.ErrCannotReportOwnContent:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case domain.ErrInvalidCursor, domain.ErrInvalidReportReason, domain.ErrInvalidReportStatus,
+		domain.ErrInvalidReportTarget, domain.ErrInvalidModerationAction:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+type ReportDTO struct {
+	Reason  string `json:"reason" binding:"required"`
+	Details string `json:"details,omitempty"`
+}
+
+type ResolveReportDTO struct {
+	Action string `json:"action" binding:"required"`
+	Note   string `json:"note,omitempty"`
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"blog-backend/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	NotificationUseCase domain.INotificationUseCase
+}
+
+func NewNotificationController(nu domain.INotificationUseCase) *NotificationController {
+	return &NotificationController{
+		NotificationUseCase: nu,
+	}
+}
+
+func (nc *NotificationController) GetNotifications(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+	cursor, limit := pageParams(c)
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, next, err := nc.NotificationUseCase.GetNotifications(c, userID.(string), unreadOnly, cursor, limit)
+	if err != nil {
+		notificationError(c, err, "Failed to fetch notifications.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications, "next_cursor": next})
+}
+
+func (nc *NotificationController) GetUnreadCount(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	count, err := nc.NotificationUseCase.CountUnread(c, userID.(string))
+	if err != nil {
+		notificationError(c, err, "Failed to count unread notifications.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+func (nc *NotificationController) MarkRead(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	if err := nc.NotificationUseCase.MarkRead(c, userID.(string), c.Param("id")); err != nil {
+		notificationError(c, err, "Failed to mark notification as read.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read."})
+}
+
+func (nc *NotificationController) MarkAllRead(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	count, err := nc.NotificationUseCase.MarkAllRead(c, userID.(string))
+	if err != nil {
+		notificationError(c, err, "Failed to mark notifications as read.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notifications marked as read.", "updated": count})
+}
+
+func (nc *NotificationController) GetPreferences(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	prefs, err := nc.NotificationUseCase.GetPreferences(c, userID.(string))
+	if err != nil {
+		notificationError(c, err, "Failed to fetch notification preferences.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+func (nc *NotificationController) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		return
+	}
+
+	var prefsDTO NotificationPreferencesDTO
+	if err := c.ShouldBindJSON(&prefsDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. muted is required."})
+		return
+	}
+
+	muted := make([]domain.NotificationType, len(prefsDTO.Muted))
+	for i, t := range prefsDTO.Muted {
+		muted[i] = domain.NotificationType(t)
+	}
+	prefs, err := nc.NotificationUseCase.UpdatePreferences(c, userID.(string), muted)
+	if err != nil {
+		notificationError(c, err, "Failed to update notification preferences.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+func notificationError(c *gin.Context, err error, message string) {
+	switch err {
+	case domain.ErrNotificationNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case domain.ErrInvalidCursor, domain.ErrInvalidNotificationType:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+type NotificationPreferencesDTO struct {
+	Muted []string `json:"muted" binding:"required"`
+}
@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pageParams reads the cursor and limit query parameters shared by every
+// paginated listing. A missing or invalid limit falls back to 10.
+func pageParams(c *gin.Context) (string, int) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+	return c.Query("cursor"), limit
+}
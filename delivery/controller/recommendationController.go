@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"blog-backend/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationController struct {
+	RecommendationUseCase domain.IRecommendationUseCase
+}
+
+func NewRecommendationController(ru domain.IRecommendationUseCase) *RecommendationController {
+	return &RecommendationController{
+		RecommendationUseCase: ru,
+	}
+}
+
+func (rc *RecommendationController) GetRecommendations(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	cursor, limit := pageParams(c)
+
+	blogs, next, err := rc.RecommendationUseCase.GetRecommendations(c, userID.(string), cursor, limit)
+	if err != nil {
+		if err == domain.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recommendations."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"blogs": blogs, "next_cursor": next})
+}
+
+// GetLegacyRecommendations serves /blogs/get-recommendation in the shape
+// older clients expect: the first page only, without a cursor.
+func (rc *RecommendationController) GetLegacyRecommendations(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "you are not authorized to get recommendations"})
+		return
+	}
+	_, limit := pageParams(c)
+
+	blogs, _, err := rc.RecommendationUseCase.GetRecommendations(c, userID.(string), "", limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recommendations."})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"Blogs Recommended": blogs})
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"blog-backend/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatsController struct {
+	StatsUseCase domain.IStatsUseCase
+}
+
+func NewStatsController(su domain.IStatsUseCase) *StatsController {
+	return &StatsController{
+		StatsUseCase: su,
+	}
+}
+
+// statsQuery reads ?from=&to=&interval=; dates are plain dates or RFC3339.
+func statsQuery(c *gin.Context) (domain.StatsQuery, bool) {
+	query := domain.StatsQuery{Interval: domain.StatsInterval(c.Query("interval"))}
+	var err error
+	if query.From, err = parseSearchDate(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' date."})
+		return query, false
+	}
+	if query.To, err = parseSearchDate(c.Query("to"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' date."})
+		return query, false
+	}
+	return query, true
+}
+
+func (sc *StatsController) GetBlogStats(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	role, _ := c.Get("x-user-role")
+	roleName, _ := role.(string)
+	query, ok := statsQuery(c)
+	if !ok {
+		return
+	}
+
+	stats, err := sc.StatsUseCase.GetBlogStats(c, c.Param("id"), userID.(string), domain.Role(roleName), query)
+	if err != nil {
+		statsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
+
+// GetDashboard sums up the stats of all of the caller's posts.
+func (sc *StatsController) GetDashboard(c *gin.Context) {
+	userID, exists := c.Get("x-user-id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	query, ok := statsQuery(c)
+	if !ok {
+		return
+	}
+
+	dashboard, err := sc.StatsUseCase.GetDashboard(c, userID.(string), query)
+	if err != nil {
+		statsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"dashboard": dashboard})
+}
+
+func statsError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrInvalidStatsRange, domain.ErrInvalidStatsInterval:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case domain.ErrBlogNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found."})
+	case domain.ErrUserNotAuthorized:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or an admin can view the stats."})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stats."})
+	}
+}
@@ -3,7 +3,7 @@ package controller
 import (
 	"blog-backend/domain"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -100,33 +100,121 @@ func (uc *UserController) DemoteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "User demoted successfully."})
 }
 func (uc *UserController) GetUsers(c *gin.Context) {
-	p := c.Query("page")
-	l := c.Query("limit")
-	page, err := strconv.Atoi(p)
-	if err != nil || p == "" {
-		page = 1
-	}
-	limit, err := strconv.Atoi(l)
-	if err != nil || l == "" {
-		limit = 10
-	}
-	users, total, err := uc.UserUseCase.GetUsers(c, page, limit)
+	cursor, limit := pageParams(c)
+	users, next, err := uc.UserUseCase.GetUsers(c, cursor, limit)
 	if err != nil {
+		if err == domain.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch Users."})
 		return
 	}
-	totalPages := (total + int64(limit) - 1) / int64(limit)
 
-	c.JSON(http.StatusOK, gin.H{"users": users, "total": totalPages, "page": page, "limit": limit})
+	c.JSON(http.StatusOK, gin.H{"users": users, "next_cursor": next, "limit": limit})
 }
 
+// DeleteUser takes ?content=delete|transfer to choose what happens to the
+// user's blogs and comments when the account is purged; transfer is the default.
 func (uc *UserController) DeleteUser(c *gin.Context) {
 	userID := c.Param("id")
-	err := uc.UserUseCase.DeleteUser(c, userID)
+	adminID, _ := c.Get("x-user-id")
+	action := domain.UserContentAction(c.DefaultQuery("content", string(domain.TransferUserContent)))
+	err := uc.UserUseCase.DeleteUser(c, userID, adminID.(string), action)
+	if err == domain.ErrInvalidContentAction {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err == domain.ErrGhostAccount {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err == domain.ErrUserNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully."})
+}
+
+func (uc *UserController) RestoreUser(c *gin.Context) {
+	err := uc.UserUseCase.RestoreUser(c, c.Param("id"))
+	if err == domain.ErrUserNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No deleted user with this id."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore user."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User restored successfully."})
+}
+
+type suspendUserRequest struct {
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until"` // optional; the suspension lasts until lifted without it
+}
+
+func (uc *UserController) SuspendUser(c *gin.Context) {
+	var request suspendUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body."})
+		return
+	}
+	adminID, _ := c.Get("x-user-id")
+
+	err := uc.UserUseCase.SuspendUser(c, c.Param("id"), adminID.(string), request.Reason, request.Until)
+	if err != nil {
+		restrictionError(c, err, "Failed to suspend user.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User suspended successfully."})
+}
+
+func (uc *UserController) BanUser(c *gin.Context) {
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body."})
+		return
+	}
+	adminID, _ := c.Get("x-user-id")
+
+	err := uc.UserUseCase.BanUser(c, c.Param("id"), adminID.(string), request.Reason)
+	if err != nil {
+		restrictionError(c, err, "Failed to ban user.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User banned successfully."})
+}
+
+// UnsuspendUser lifts a suspension or a ban.
+func (uc *UserController) UnsuspendUser(c *gin.Context) {
+	err := uc.UserUseCase.UnsuspendUser(c, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blog."})
+		restrictionError(c, err, "Failed to unsuspend user.")
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"message": "User unsuspended successfully."})
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Blog deleted successfully."})
+func restrictionError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case domain.ErrInvalidSuspension:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case domain.ErrCannotRestrictSelf, domain.ErrGhostAccount:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case domain.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found."})
+	case domain.ErrUserNotRestricted:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
 }
@@ -8,32 +8,45 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func Setup(ac *controller.AuthController, bc *controller.BlogController, uc *controller.UserController, gc *controller.GeminiController,jwtService domain.IJWTService, engine *gin.Engine) {
+func Setup(ac *controller.AuthController, bc *controller.BlogController, uc *controller.UserController, gc *controller.GeminiController, bmc *controller.BookmarkController, fc *controller.FollowController, nc *controller.NotificationController, ec *controller.EventController, mc *controller.ModerationController, sc *controller.StatsController, rc *controller.RecommendationController, kc *controller.JWKSController, jwtService domain.IJWTService, denylist domain.ITokenDenylist, engine *gin.Engine) {
+	engine.GET("/.well-known/jwks.json", kc.GetJWKS)
+
 	// ============ Public Routes ============
 	publicRouter := engine.Group("/api")
 	NewAuthRouter(ac, publicRouter)
-	NewBlogRouter(bc, publicRouter, jwtService) // Public blog routes (read-only)
+	NewBlogRouter(bc, publicRouter, jwtService, denylist) // Public blog routes (read-only)
 	
 
 	// ============ Protected Routes (User) ============
 	userRouter := engine.Group("/api")
-	userRouter.Use(middleware.NewAuthMiddleware(jwtService))
+	userRouter.Use(middleware.NewAuthMiddleware(jwtService, denylist))
 	userRouter.Use(middleware.NewStatusCheckMiddleware())
 	NewUserRouter(uc, userRouter)
+	NewSessionRouter(ac, userRouter)
 	NewBlogAuthRouter(bc, gc, userRouter) // Authenticated blog routes (create/update/delete)
+	NewBookmarkRouter(bmc, userRouter)
+	NewFollowRouter(fc, userRouter)
+	NewNotificationRouter(nc, userRouter)
+	NewEventRouter(ec, publicRouter, userRouter)
+	NewReportRouter(mc, userRouter)
+	NewStatsRouter(sc, userRouter)
+	NewRecommendationRouter(rc, userRouter)
 
 	// ============ Admin Routes ============
 	adminRouter := engine.Group("/api/admin")
-	adminRouter.Use(middleware.NewAuthMiddleware(jwtService))
+	adminRouter.Use(middleware.NewAuthMiddleware(jwtService, denylist))
 	adminRouter.Use(middleware.NewAdminMiddleware())
 	adminRouter.Use(middleware.NewStatusCheckMiddleware())
 	NewAdminRouter(uc, bc, adminRouter)
+	NewModerationRouter(mc, adminRouter)
+	NewTwoFactorRouter(ac, userRouter, adminRouter)
 }
 
 func NewAuthRouter(handler *controller.AuthController, group *gin.RouterGroup) {
 	group.POST("/auth/register", handler.Register)
 	group.GET("/auth/activate", handler.Activate)
 	group.POST("/auth/login", handler.Login )
+	group.POST("/auth/login/2fa", handler.CompleteTwoFactorLogin)
 	group.GET("/auth/google/login", handler.GoogleLogin)
 	group.GET("/auth/google/callback", handler.GoogleCallback)
 	group.POST("/auth/logout", handler.Logout)
@@ -49,39 +62,136 @@ func NewUserRouter(handler *controller.UserController, group *gin.RouterGroup) {
 	group.GET("/users/:id", handler.GetUserByID)
 }
 
-func NewBlogRouter(handler *controller.BlogController, group *gin.RouterGroup, jwtService domain.IJWTService) {
-    group.Use(middleware.NewOptionalAuthMiddleware(jwtService))
+func NewSessionRouter(handler *controller.AuthController, group *gin.RouterGroup) {
+	group.GET("/users/me/sessions", handler.ListSessions)
+	group.DELETE("/users/me/sessions", handler.RevokeOtherSessions)
+	group.DELETE("/users/me/sessions/:id", handler.RevokeSession)
+	group.POST("/auth/logout-all", handler.LogoutAll)
+}
+
+func NewTwoFactorRouter(handler *controller.AuthController, userGroup *gin.RouterGroup, adminGroup *gin.RouterGroup) {
+	userGroup.GET("/users/me/2fa", handler.GetTwoFactorStatus)
+	userGroup.POST("/users/me/2fa/enrol", handler.BeginTwoFactorEnrolment)
+	userGroup.POST("/users/me/2fa/verify", handler.VerifyTwoFactor)
+	userGroup.DELETE("/users/me/2fa", handler.DisableTwoFactor)
+	userGroup.POST("/users/me/2fa/recovery-codes", handler.RegenerateRecoveryCodes)
+
+	adminGroup.DELETE("/users/:id/2fa", handler.ResetTwoFactor)
+}
+
+func NewBlogRouter(handler *controller.BlogController, group *gin.RouterGroup, jwtService domain.IJWTService, denylist domain.ITokenDenylist) {
+    group.Use(middleware.NewOptionalAuthMiddleware(jwtService, denylist))
     group.GET("/blogs", handler.ListBlogs)
     group.GET("/blogs/user/:id", handler.GetBlogsByUserID)
     group.GET("/blogs/:id", handler.GetBlog)
     group.GET("/blogs/search", handler.SearchBlogs)
+    group.GET("/blogs/trending", handler.Trending)
     group.GET("/blogs/:id/comments", handler.ListAllComments)
+    group.GET("/blogs/:id/reactions", handler.ListReactions)
 }
 
 func NewBlogAuthRouter(handler *controller.BlogController, handler2 *controller.GeminiController, group *gin.RouterGroup) {
 	group.POST("/blogs", handler.CreateBlog)
 	group.PATCH("/blogs/:id", handler.UpdateBlog)
+	group.POST("/blogs/:id/publish", handler.PublishBlog)
+	group.POST("/blogs/:id/unpublish", handler.UnpublishBlog)
+	group.POST("/blogs/:id/schedule", handler.ScheduleBlog)
+	group.POST("/blogs/:id/archive", handler.ArchiveBlog)
+	group.GET("/users/me/blogs", handler.GetMyBlogs)
+	group.GET("/blogs/:id/revisions", handler.ListRevisions)
+	group.GET("/blogs/:id/revisions/diff", handler.DiffRevisions)
+	group.GET("/blogs/:id/revisions/:version", handler.GetRevision)
+	group.POST("/blogs/:id/revisions/:version/restore", handler.RestoreRevision)
 	group.DELETE("/blogs/:id", handler.DeleteBlogByAuth)
 	group.POST("/blogs/:id/like", handler.LikeBlog)
 	group.POST("/blogs/:id/dislike", handler.DislikeBlog)
+	group.POST("/blogs/:id/reactions", handler.React)
 	group.DELETE("/blogs/:id/reaction",handler.RemoveReaction)
 	group.POST("/blogs/:id/comments",handler.CreateComment)
+	group.PATCH("/blogs/:id/comments/:commentId", handler.EditComment)
+	group.POST("/blogs/:id/comments/:commentId/reactions", handler.ReactToComment)
+	group.DELETE("/blogs/:id/comments/:commentId/reaction", handler.RemoveCommentReaction)
 	group.DELETE("/blogs/:id/comments", handler.DeleteCommentByAuth)
-	group.GET("/blogs/get-recommendation", handler.GetRecommendations)
 
 	group.POST("/blogs/ai/generate-content", handler2.GenerateContent)
 	group.POST("/blogs/ai/refine-content", handler2.RefineContent)
 	group.POST("/blogs/ai/generate-skeleton", handler2.GenerateSkeleton)
 }
 
+func NewBookmarkRouter(handler *controller.BookmarkController, group *gin.RouterGroup) {
+	group.GET("/users/me/bookmarks", handler.GetBookmarks)
+	group.POST("/users/me/bookmarks", handler.AddBookmark)
+	group.PATCH("/users/me/bookmarks/:blogId", handler.UpdateBookmark)
+	group.DELETE("/users/me/bookmarks/:blogId", handler.RemoveBookmark)
+	group.GET("/users/me/bookmarks/lists", handler.GetReadingLists)
+	group.POST("/users/me/bookmarks/lists", handler.CreateReadingList)
+	group.PATCH("/users/me/bookmarks/lists/:listId", handler.UpdateReadingList)
+	group.DELETE("/users/me/bookmarks/lists/:listId", handler.DeleteReadingList)
+	group.PUT("/users/me/bookmarks/lists/:listId/order", handler.ReorderReadingList)
+}
+
+func NewFollowRouter(handler *controller.FollowController, group *gin.RouterGroup) {
+	group.POST("/users/:id/follow", handler.Follow)
+	group.DELETE("/users/:id/follow", handler.Unfollow)
+	group.GET("/users/:id/followers", handler.GetFollowers)
+	group.GET("/users/:id/following", handler.GetFollowing)
+	group.GET("/feed", handler.GetFeed)
+}
+
+func NewNotificationRouter(handler *controller.NotificationController, group *gin.RouterGroup) {
+	group.GET("/notifications", handler.GetNotifications)
+	group.GET("/notifications/unread-count", handler.GetUnreadCount)
+	group.POST("/notifications/read-all", handler.MarkAllRead)
+	group.POST("/notifications/:id/read", handler.MarkRead)
+	group.GET("/notifications/preferences", handler.GetPreferences)
+	group.PUT("/notifications/preferences", handler.UpdatePreferences)
+}
+
+// NewEventRouter registers the Server-Sent Events streams. Blog streams are
+// public; the notification stream needs a logged in user.
+func NewEventRouter(handler *controller.EventController, publicGroup *gin.RouterGroup, userGroup *gin.RouterGroup) {
+	publicGroup.GET("/blogs/:id/events", handler.StreamBlog)
+	userGroup.GET("/notifications/stream", handler.StreamNotifications)
+}
+
 func NewAdminRouter(userHandler *controller.UserController, blogHandler *controller.BlogController, group *gin.RouterGroup) {
 	// User Management
 	group.GET("/users", userHandler.GetUsers)
 	group.POST("/users/:id/promote", userHandler.PromoteUser)
 	group.POST("/users/:id/demote", userHandler.DemoteUser)
 	group.DELETE("/users/:id", userHandler.DeleteUser)
+	group.POST("/users/:id/restore", userHandler.RestoreUser)
+	group.POST("/users/:id/suspend", userHandler.SuspendUser)
+	group.POST("/users/:id/ban", userHandler.BanUser)
+	group.POST("/users/:id/unsuspend", userHandler.UnsuspendUser)
 
 	// Blog Moderation
 	group.DELETE("/blogs/:id", blogHandler.DeleteBlogByAdmin)
 	group.DELETE("/blogs/:id/comments", blogHandler.DeleteCommentByAdmin)
+	group.POST("/blogs/:id/restore", blogHandler.RestoreBlog)
+	group.POST("/blogs/:id/comments/:commentId/restore", blogHandler.RestoreComment)
+}
+
+func NewReportRouter(handler *controller.ModerationController, group *gin.RouterGroup) {
+	group.POST("/blogs/:id/report", handler.ReportBlog)
+	group.POST("/blogs/:id/comments/:commentId/report", handler.ReportComment)
+}
+
+func NewStatsRouter(handler *controller.StatsController, group *gin.RouterGroup) {
+	group.GET("/blogs/:id/stats", handler.GetBlogStats)
+	group.GET("/users/me/stats", handler.GetDashboard)
+}
+
+func NewRecommendationRouter(handler *controller.RecommendationController, group *gin.RouterGroup) {
+	group.GET("/users/me/recommendations", handler.GetRecommendations)
+	// kept for older clients
+	group.GET("/blogs/get-recommendation", handler.GetLegacyRecommendations)
+}
+
+func NewModerationRouter(handler *controller.ModerationController, group *gin.RouterGroup) {
+	group.GET("/reports", handler.ListReports)
+	group.GET("/reports/:id", handler.GetReport)
+	group.POST("/reports/:id/resolve", handler.ResolveReport)
+	group.POST("/blogs/:id/unhide", handler.UnhideBlog)
+	group.POST("/blogs/:id/comments/:commentId/unhide", handler.UnhideComment)
 }
@@ -5,18 +5,43 @@ import "context"
 type IAuthUseCase interface {
 	Register(ctx context.Context, user *User) (*User, error)
 	Activate(ctx context.Context, tokenID string) error
-	Login(ctx context.Context, email, password string) (*User, *TokenPair, error)
-	Logout(ctx context.Context, refreshToken string) error
-	RefreshToken(ctx context.Context, refreshToken string) (*User, *TokenPair, error)
+	Login(ctx context.Context, email, password string, client *SessionClient) (*User, *TokenPair, error)
+	// Logout ends the refresh token's session and denies the access token, if any.
+	Logout(ctx context.Context, refreshToken, accessToken string) error
+	// LogoutAll ends every session of the user and rejects all access tokens issued so far.
+	LogoutAll(ctx context.Context, userID string) error
+	RefreshToken(ctx context.Context, refreshToken string, client *SessionClient) (*User, *TokenPair, error)
 	ForgotPassword(ctx context.Context, email string)  error
 	ResetPassword(ctx context.Context, token, newPassword string) error
 	FindOrCreateGoogleUser(ctx context.Context, email, username, profilePicture, googleID string) (*User, error)
-	IssueTokenPair(ctx context.Context, user *User) (*TokenPair, error)
+	IssueTokenPair(ctx context.Context, user *User, client *SessionClient) (*TokenPair, error)
+
+	// Sessions; refreshToken identifies the session the request comes from
+	ListSessions(ctx context.Context, userID, refreshToken string) ([]*Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID, refreshToken string) (int64, error)
+
+	// Two-factor authentication; code is a TOTP code or a recovery code
+	CompleteTwoFactorLogin(ctx context.Context, challenge, code string, client *SessionClient) (*User, *TokenPair, error)
+	GetTwoFactorStatus(ctx context.Context, userID string) (*TwoFactor, error)
+	BeginTwoFactorEnrolment(ctx context.Context, userID string) (*TOTPEnrolment, error)
+	// ConfirmTwoFactorEnrolment enables two-factor authentication once code
+	// proves the app was set up, returning the recovery codes.
+	ConfirmTwoFactorEnrolment(ctx context.Context, userID, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, userID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	// ResetTwoFactor is for admins helping a user who lost their device.
+	ResetTwoFactor(ctx context.Context, userID string) error
 }
 
 type IJWTService interface {
-	GenerateToken(userID, username, email, role string) (string, error)
-	ParseToken(tokenString string) (string, string, error)
+	GenerateToken(userID, username, email, role, status string) (string, error)
+	ParseToken(tokenString string) (*AccessClaims, error)
+	// PublicKeys returns the keys tokens are verified with, for the JWKS.
+	PublicKeys() []*JSONWebKey
+	// RotateKeys reloads the key ring, adds the next key once the signing key
+	// is due for rotation and drops keys whose tokens have all expired.
+	RotateKeys(ctx context.Context) error
 }
 
 type IPasswordService interface {
@@ -5,26 +5,81 @@ import (
 	"time"
 )
 
+type BlogStatus string
+
+const (
+	BlogDraft     BlogStatus = "draft"
+	BlogScheduled BlogStatus = "scheduled"
+	BlogPublished BlogStatus = "published"
+	BlogArchived  BlogStatus = "archived"
+)
+
 type Blog struct {
 	ID        string
 	Title     string
 	Content   string
 	AuthorID  string
 	Tags      []string
+	Status      BlogStatus
+	PublishAt   time.Time // only meaningful while Status is BlogScheduled
+	PublishedAt time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	ViewCount    int                
     LikeCount    int                
     DislikeCount int                
     CommentCount int
+    SaveCount    int // how many readers bookmarked the blog
+    // ReactionCounts counts every reaction type; LikeCount and DislikeCount
+    // mirror its like and dislike entries.
+    ReactionCounts map[ReactionType]int
+    Hidden       bool // hidden by moderators; only the author and admins still see it
 }
 
+// IsVisible reports whether readers other than the author may see the blog.
+func (b *Blog) IsVisible() bool {
+	return b.Status == BlogPublished && !b.Hidden
+}
+
+// MaxCommentDepth is how deeply replies may nest; top-level comments have depth 0.
+const MaxCommentDepth = 4
+
 type Comment struct {
     ID        string
     BlogID    string
     AuthorID  string
+    ParentID  string
+    Ancestors []string // IDs from the top-level comment down to the parent
+    Depth      int
+    ReplyCount int
     Content   string             
+    Edited    bool
+    EditedAt  time.Time
+    Hidden    bool // hidden by moderators; the content is withheld from readers
+    ReactionCounts map[ReactionType]int
+    // Score ranks comments for the "top" sort: one point for every reaction
+    // except dislikes, which take one away.
+    Score     int
     CreatedAt time.Time          
+    Replies   []*Comment
+}
+
+type CommentSort string
+
+const (
+	CommentSortOldest CommentSort = "oldest"
+	CommentSortTop    CommentSort = "top"
+)
+
+func (s CommentSort) IsValid() bool {
+	return s == CommentSortOldest || s == CommentSortTop
+}
+
+// CommentRemoval describes comments removed or restored together.
+type CommentRemoval struct {
+	CommentIDs []string // every removed comment, replies included
+	BlogIDs    []string
+	ParentIDs  []string // surviving comments whose replies changed
 }
 
 
@@ -33,21 +88,55 @@ type ReactionType string
 const (
 	Like 	ReactionType = "like"
 	Dislike ReactionType = "dislike"
+	Love       ReactionType = "love"
+	Insightful ReactionType = "insightful"
+	Funny      ReactionType = "funny"
 )
 
+// DefaultReactionTypes are offered unless REACTION_TYPES says otherwise.
+// Like and dislike are always offered for the older endpoints.
+var DefaultReactionTypes = []ReactionType{Like, Dislike, Love, Insightful, Funny}
+
+// Reaction is a user's reaction to a blog or, when CommentID is set, to a
+// comment on that blog.
 type Reaction struct {
     ID        string
     BlogID    string
+    CommentID string
     UserID    string
     Type      ReactionType             
     CreatedAt time.Time          
 }
 
+// ReactionUser is a reaction in a blog's reaction list with the profile of
+// the user who left it.
+type ReactionUser struct {
+	UserID         string
+	Username       string
+	ProfilePicture string
+	Type           ReactionType
+	ReactedAt      time.Time
+}
+
+// ReactionCounters are the reaction counters stored on a blog or comment,
+// which the reconcile job compares against the reactions themselves.
+type ReactionCounters struct {
+	ID           string
+	Counts       map[ReactionType]int
+	LikeCount    int
+	DislikeCount int
+}
+
 type UpdateMetricsField string 
 
 const (
 	LikeCountField    UpdateMetricsField = "like_count"
 	DislikeCountField UpdateMetricsField = "dislike_count"
+	SaveCountField    UpdateMetricsField = "save_count"
+	ViewCountField    UpdateMetricsField = "view_count"
+	// ReactionCountsField holds the per type counts; increment one type with
+	// ReactionCountsField + "." + type.
+	ReactionCountsField UpdateMetricsField = "reaction_counts"
 )
 
 type TagsCount struct {
@@ -65,7 +154,10 @@ type ReadHistory struct {
 
 type IHistoryRepository interface {
 	AddReadHistory(ctx context.Context, userID, blogID string, blogTags []string) error
-	GetRecommendations(ctx context.Context, userID string) ([]*Blog, error)
+	// GetRecentReads returns up to limit of the user's reads, newest first.
+	GetRecentReads(ctx context.Context, userID string, limit int) ([]*BlogRead, error)
+	RemoveBlogsFromHistory(ctx context.Context, blogIDs []string) error
+	DeleteHistoryForUser(ctx context.Context, userID string) error
 }
 
 
@@ -74,56 +166,204 @@ type IBlogRepository interface {
 	// IsAuthor(ctx context.Context, blogID, userID string) (bool, error)
 	CreateBlog(ctx context.Context, blog *Blog) (*Blog, error)
 	GetBlogByID(ctx context.Context, id string) (*Blog, error)
+	GetBlogsByIDs(ctx context.Context, ids []string) ([]*Blog, error)
+	// ListRecentBlogsByTags returns published blogs carrying any of the
+	// tags, newest first.
+	ListRecentBlogsByTags(ctx context.Context, tags []string, limit int) ([]*Blog, error)
 	UpdateBlog(ctx context.Context, blogID string, userID string, updates map[string]interface{}) error
-	DeleteBlog(ctx context.Context, id string) error
+	// DeleteBlog soft deletes the blog; every read leaves it out afterwards.
+	DeleteBlog(ctx context.Context, id string, deletedBy string) error
+	RestoreBlog(ctx context.Context, id string) error
+
+	// Retention
+	// ListDeletedBlogIDs returns up to limit blogs soft deleted before the
+	// cutoff, oldest deletion first.
+	ListDeletedBlogIDs(ctx context.Context, before time.Time, limit int) ([]string, error)
+	// FilterDeletedBlogIDs narrows ids down to the blogs that are still soft
+	// deleted.
+	FilterDeletedBlogIDs(ctx context.Context, ids []string) ([]string, error)
+	// PurgeBlogs permanently removes the blogs that are still soft deleted
+	// and returns the ids of those it removed.
+	PurgeBlogs(ctx context.Context, ids []string) ([]string, error)
+	// DeleteBlogsByAuthor soft deletes the author's live blogs at the given
+	// time and returns their IDs.
+	DeleteBlogsByAuthor(ctx context.Context, authorID, deletedBy string, at time.Time) ([]string, error)
+	// RestoreBlogsByAuthor restores the author's blogs deleted at exactly
+	// the given time, leaving ones deleted on their own alone.
+	RestoreBlogsByAuthor(ctx context.Context, authorID string, at time.Time) ([]string, error)
+	// ListBlogIDsByAuthor returns all of the author's blogs, deleted or not.
+	ListBlogIDsByAuthor(ctx context.Context, authorID string) ([]string, error)
+	TransferBlogs(ctx context.Context, fromAuthorID, toAuthorID string) (int64, error)
+	// SetBlogMetrics overwrites counters with recalculated values.
+	SetBlogMetrics(ctx context.Context, blogID string, metrics map[string]int) error
 
 	// Blog Listing
-	ListBlogs(ctx context.Context, page, limit int, field string) ([]*Blog, int64, error)
-	ListBlogsByAuthor(ctx context.Context, authorID string) ([]*Blog, error)
-	SearchBlogs(ctx context.Context, query string) ([]*Blog, error)
+	// Listings are cursor paginated: pass "" for the first page and the
+	// returned next cursor for the following one; "" means no more pages.
+	ListBlogs(ctx context.Context, cursor string, limit int, field string) ([]*Blog, string, error)
+	ListBlogsByAuthor(ctx context.Context, authorID string, status BlogStatus, cursor string, limit int) ([]*Blog, string, error)
+	// ListBlogsByAuthors pages through the published blogs of any of the authors, newest first.
+	ListBlogsByAuthors(ctx context.Context, authorIDs []string, cursor string, limit int) ([]*Blog, string, error)
+	// LatestBlogUpdate returns when any published blog of the authors was last
+	// published or edited; zero when they have none.
+	LatestBlogUpdate(ctx context.Context, authorIDs []string) (time.Time, error)
+	SearchBlogs(ctx context.Context, query SearchQuery) ([]*SearchResult, string, error)
+
+	// Blog lifecycle
+	// PublishDueBlogs returns the ids of the blogs it published.
+	PublishDueBlogs(ctx context.Context, now time.Time) ([]string, error)
 
 	//Blog authorization
 	IsAuthor(ctx context.Context, blogID, userID string) (bool, error)
 	
 	UpdateBlogMetrics(ctx context.Context, blogID string, field string, increment int) error
+	// IncrementBlogMetrics applies all the increments in a single write.
+	IncrementBlogMetrics(ctx context.Context, blogID string, increments map[string]int) error
+	// AddViewCounts adds the views to each blog in one bulk write. Unknown
+	// and deleted blogs are skipped.
+	AddViewCounts(ctx context.Context, views map[string]int) error
+	// ListReactionCounters pages through all blogs in id order, starting
+	// after afterID ("" for the first page).
+	ListReactionCounters(ctx context.Context, afterID string, limit int) ([]*ReactionCounters, error)
+	// SetReactionCounts overwrites the per type counts together with the
+	// like and dislike counters.
+	SetReactionCounts(ctx context.Context, blogID string, counts map[ReactionType]int) error
 
+	// Moderation
+	SetBlogHidden(ctx context.Context, blogID string, hidden bool) error
 }
 
 type IReactionRepository interface {
 	// Reactions
-	AddReaction(ctx context.Context, reaction *Reaction) error
-	RemoveReaction(ctx context.Context, blogID, userID string) error
-	CheckReactionExists(ctx context.Context, blogID, userID string) (*Reaction, bool, error)
-	UpdateReaction(ctx context.Context, blogID, userID string, reactionType ReactionType) error
+	// SetReaction stores the user's reaction, replacing any earlier one, and
+	// returns the type it replaced or "" if there was none.
+	SetReaction(ctx context.Context, reaction *Reaction) (ReactionType, error)
+	// DeleteReaction and DeleteCommentReaction return the type of the
+	// removed reaction, or ErrReactionNotFound.
+	DeleteReaction(ctx context.Context, blogID, userID string) (ReactionType, error)
+	DeleteCommentReaction(ctx context.Context, commentID, userID string) (ReactionType, error)
+	// GetReactions pages through the blog's reactions newest first, only of
+	// reactionType unless it is "".
+	GetReactions(ctx context.Context, blogID string, reactionType ReactionType, cursor string, limit int) ([]*Reaction, string, error)
+	// DeleteReactionsForBlogs also removes the reactions on their comments.
+	DeleteReactionsForBlogs(ctx context.Context, blogIDs []string) (int64, error)
+	DeleteReactionsForComments(ctx context.Context, commentIDs []string) (int64, error)
+	// DeleteReactionsByUser returns the ids of the blogs and comments the
+	// user had reacted to.
+	DeleteReactionsByUser(ctx context.Context, userID string) (blogIDs []string, commentIDs []string, err error)
+	// Counting only covers reactions on the blog itself, not its comments.
+	CountReactions(ctx context.Context, blogID string) (map[ReactionType]int, error)
+	CountReactionsForBlogs(ctx context.Context, blogIDs []string) (map[string]map[ReactionType]int, error)
+	CountReactionsForComments(ctx context.Context, commentIDs []string) (map[string]map[ReactionType]int, error)
+	// ListLikedBlogIDs returns the blogs the user reacted to with anything
+	// but a dislike, newest first.
+	ListLikedBlogIDs(ctx context.Context, userID string, limit int) ([]string, error)
+	// CountCoReactions looks at up to readerLimit other users who liked any
+	// of the blogs and counts, per blog, how many of them liked it too.
+	// Only the limit blogs liked most are returned.
+	CountCoReactions(ctx context.Context, blogIDs []string, excludeUserID string, readerLimit, limit int) (map[string]int, error)
 }
 
 type ICommentRepository interface {
 	// Comments
 	AddComment(ctx context.Context, comment *Comment) (*Comment, error)
-	GetCommentsForBlog(ctx context.Context, blogID string) ([]*Comment, error)
-	DeleteComment(ctx context.Context, commentID string) error
+	GetCommentByID(ctx context.Context, commentID string) (*Comment, error)
+	GetCommentsForBlog(ctx context.Context, blogID string, sort CommentSort, cursor string, limit int) ([]*Comment, string, error)
+	GetReplies(ctx context.Context, rootIDs []string) ([]*Comment, error)
+	UpdateCommentContent(ctx context.Context, commentID, content string) error
+	IncrementReplyCount(ctx context.Context, commentID string, increment int) error
+	// DeleteComment soft deletes the comment together with all of its replies
+	// and returns how many comments were deleted.
+	DeleteComment(ctx context.Context, commentID string, deletedBy string) (int64, error)
+	// RestoreComment brings back a deleted comment and the replies deleted
+	// with it, returning the comment and how many comments were restored.
+	RestoreComment(ctx context.Context, blogID, commentID string) (*Comment, int64, error)
+	// ListDeletedCommentIDs returns up to limit comments soft deleted before
+	// the cutoff, oldest deletion first.
+	ListDeletedCommentIDs(ctx context.Context, before time.Time, limit int) ([]string, error)
+	PurgeComments(ctx context.Context, ids []string) (int64, error)
+	DeleteCommentsForBlogs(ctx context.Context, blogIDs []string) (int64, error)
+	// DeleteCommentsByAuthor removes the author's comments with their reply
+	// threads.
+	DeleteCommentsByAuthor(ctx context.Context, authorID string) (*CommentRemoval, error)
+	// SoftDeleteCommentsByAuthor soft deletes the author's live comments
+	// with their reply threads at the given time.
+	SoftDeleteCommentsByAuthor(ctx context.Context, authorID, deletedBy string, at time.Time) (*CommentRemoval, error)
+	// RestoreCommentsByAuthor brings back what SoftDeleteCommentsByAuthor
+	// deleted at the given time.
+	RestoreCommentsByAuthor(ctx context.Context, authorID string, at time.Time) (*CommentRemoval, error)
+	TransferComments(ctx context.Context, fromAuthorID, toAuthorID string) (int64, error)
+	// CountComments and CountReplies count comments that are not deleted.
+	CountComments(ctx context.Context, blogID string) (int64, error)
+	CountReplies(ctx context.Context, commentID string) (int64, error)
+	SetReplyCount(ctx context.Context, commentID string, count int) error
+	// IncrementReactionCounts moves the per type counts and the score of a
+	// live comment by the given amounts.
+	IncrementReactionCounts(ctx context.Context, commentID string, increments map[ReactionType]int) error
+	SetReactionCounts(ctx context.Context, commentID string, counts map[ReactionType]int) error
+	ListReactionCounters(ctx context.Context, afterID string, limit int) ([]*ReactionCounters, error)
 	IsComAuthor(ctx context.Context, comId, userId string) (bool,error)
-
+	SetCommentHidden(ctx context.Context, commentID string, hidden bool) error
 }
 
 type IBlogUseCase interface {
 	CreateBlog(ctx context.Context, blog *Blog) (*Blog, error)
 	GetBlog(ctx context.Context, blogID string) (*Blog, error)
+	// RecordView counts a view of the blog by viewer, a user or client
+	// address, at most once per dedupe window.
+	RecordView(ctx context.Context, blogID, viewer string) error
+	// FlushViews writes the buffered views to the blogs and returns how
+	// many were written.
+	FlushViews(ctx context.Context) (int64, error)
 	UpdateBlog(ctx context.Context, blogID string, userID string, updates map[string]interface{}) error
-	DeleteBlog(ctx context.Context, blogID string) error
-	ListBlogs(ctx context.Context, page, limit int, field string) ([]*Blog,int64, error)
-	SearchBlogs(ctx context.Context, query string) ([]*Blog, error)
+	DeleteBlog(ctx context.Context, blogID string, deletedBy string) error
+	RestoreBlog(ctx context.Context, blogID string) error
+	ListBlogs(ctx context.Context, cursor string, limit int, field string) ([]*Blog, string, error)
+	SearchBlogs(ctx context.Context, query SearchQuery) ([]*SearchResult, string, error)
+	// GetTrending pages through the visible blogs ranked by recent activity,
+	// optionally only those carrying all of tags.
+	GetTrending(ctx context.Context, window TrendingWindow, tags []string, cursor string, limit int) ([]*Blog, string, error)
+	MaintainTrending(ctx context.Context) error
 	IsBlogAuthor(ctx context.Context, blogID, userID string) (bool, error)
-	GetBlogsByUserID(ctx context.Context, userID string) ([]*Blog, error)
+	GetBlogsByUserID(ctx context.Context, userID string, cursor string, limit int) ([]*Blog, string, error)
+	GetMyBlogs(ctx context.Context, userID string, status BlogStatus, cursor string, limit int) ([]*Blog, string, error)
+
+	// Lifecycle
+	ChangeBlogStatus(ctx context.Context, blogID, userID string, status BlogStatus, publishAt time.Time) error
+	PublishDueBlogs(ctx context.Context) (int64, error)
+
+	// Revisions
+	GetRevisions(ctx context.Context, blogID string) ([]*BlogRevision, error)
+	GetRevision(ctx context.Context, blogID string, version int) (*BlogRevision, error)
+	DiffRevisions(ctx context.Context, blogID string, from, to int) (*RevisionDiff, error)
+	RestoreRevision(ctx context.Context, blogID, userID string, version int) error
+
 	// Reactions
+	// AddReaction is idempotent: repeating the user's current reaction
+	// changes nothing.
 	AddReaction(ctx context.Context, blogID, userID string, reactionType string) error
 	RemoveReaction(ctx context.Context, blogID, userID string) error
+	GetReactions(ctx context.Context, blogID string, reactionType ReactionType, cursor string, limit int) ([]*ReactionUser, string, error)
+	// ReactionTypes are the reaction types users can pick from.
+	ReactionTypes() []ReactionType
+	ReactToComment(ctx context.Context, blogID, commentID, userID string, reactionType string) error
+	RemoveCommentReaction(ctx context.Context, blogID, commentID, userID string) error
+	// ReconcileReactionCounts recounts the reaction counters of every blog
+	// and comment from the reactions and returns how many had drifted.
+	ReconcileReactionCounts(ctx context.Context) (int64, error)
 
 	// Comments
 	AddComment(ctx context.Context, comment *Comment) (*Comment, error)
-	GetComments(ctx context.Context, blogID string) ([]*Comment, error)
-	RemoveComment(ctx context.Context,commentID string)(error)
+	// GetComments only lists comments of hidden or unpublished blogs for
+	// their author and admins.
+	GetComments(ctx context.Context, blogID, userID, role string, sort CommentSort, cursor string, limit int) ([]*Comment, string, error)
+	EditComment(ctx context.Context, blogID, commentID, userID, content string) (*Comment, error)
+	RemoveComment(ctx context.Context, commentID string, deletedBy string) error
+	RestoreComment(ctx context.Context, blogID, commentID string) (*Comment, error)
 	IsComAuthor(ctx context.Context, comId, userId string) (bool,error)
 	AddReadHistory(ctx context.Context, userID, blogID string) error
-	GetRecommendations(ctx context.Context, userID string) ([]*Blog, error)
+
+	// Moderation
+	SetBlogHidden(ctx context.Context, blogID string, hidden bool) error
+	SetCommentHidden(ctx context.Context, commentID string, hidden bool) error
 }
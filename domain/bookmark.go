@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultReadingListName is the list every user gets for bookmarks saved
+// without picking one. It is created on first use and cannot be deleted.
+const DefaultReadingListName = "Saved"
+
+type ReadingList struct {
+	ID          string
+	UserID      string
+	Name        string
+	Description string
+	IsDefault   bool
+	ItemCount   int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Bookmark is a blog saved by a user. A user saves a blog at most once, in
+// one of their reading lists, at the given position.
+type Bookmark struct {
+	ID        string
+	UserID    string
+	BlogID    string
+	ListID    string
+	Position  int
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Blog      *Blog
+}
+
+// BookmarkUpdate holds the optional changes to a bookmark; nil fields are
+// left untouched. Changing ListID moves the bookmark to the end of that list.
+type BookmarkUpdate struct {
+	ListID *string
+	Note   *string
+}
+
+// ReadingListUpdate holds the optional changes to a reading list; nil fields
+// are left untouched.
+type ReadingListUpdate struct {
+	Name        *string
+	Description *string
+}
+
+type IBookmarkRepository interface {
+	// Reading lists
+	CreateList(ctx context.Context, list *ReadingList) (*ReadingList, error)
+	GetList(ctx context.Context, userID, listID string) (*ReadingList, error)
+	GetDefaultList(ctx context.Context, userID string) (*ReadingList, error)
+	GetLists(ctx context.Context, userID string) ([]*ReadingList, error)
+	UpdateList(ctx context.Context, userID, listID string, updates map[string]interface{}) error
+	DeleteList(ctx context.Context, userID, listID string) error
+	// CountBookmarksByList maps each of the user's list ids to its item count.
+	CountBookmarksByList(ctx context.Context, userID string) (map[string]int, error)
+
+	// Bookmarks
+	AddBookmark(ctx context.Context, bookmark *Bookmark) (*Bookmark, error)
+	GetBookmark(ctx context.Context, userID, blogID string) (*Bookmark, error)
+	GetBookmarks(ctx context.Context, listID string, cursor string, limit int) ([]*Bookmark, string, error)
+	GetListBlogIDs(ctx context.Context, listID string) ([]string, error)
+	// NextPosition reserves the position at the end of the list. Concurrent
+	// callers never get the same position.
+	NextPosition(ctx context.Context, listID string) (int, error)
+	UpdateBookmark(ctx context.Context, userID, blogID string, updates map[string]interface{}) error
+	// SetPositions orders the list's bookmarks as blogIDs are ordered.
+	SetPositions(ctx context.Context, listID string, blogIDs []string) error
+	DeleteBookmark(ctx context.Context, userID, blogID string) error
+	// The bulk deletes return the removed bookmarks so counters can be fixed.
+	DeleteBookmarksInList(ctx context.Context, listID string) ([]*Bookmark, error)
+	DeleteBookmarksForBlog(ctx context.Context, blogID string) ([]*Bookmark, error)
+	DeleteBookmarksForUser(ctx context.Context, userID string) ([]*Bookmark, error)
+	DeleteListsForUser(ctx context.Context, userID string) error
+	CountBookmarksForBlog(ctx context.Context, blogID string) (int64, error)
+}
+
+type IBookmarkUseCase interface {
+	GetReadingLists(ctx context.Context, userID string) ([]*ReadingList, error)
+	CreateReadingList(ctx context.Context, userID, name, description string) (*ReadingList, error)
+	UpdateReadingList(ctx context.Context, userID, listID string, update ReadingListUpdate) (*ReadingList, error)
+	DeleteReadingList(ctx context.Context, userID, listID string) error
+	ReorderReadingList(ctx context.Context, userID, listID string, blogIDs []string) error
+
+	// An empty listID means the user's default list.
+	AddBookmark(ctx context.Context, userID, blogID, listID, note string) (*Bookmark, error)
+	GetBookmarks(ctx context.Context, userID, listID string, cursor string, limit int) ([]*Bookmark, string, error)
+	UpdateBookmark(ctx context.Context, userID, blogID string, update BookmarkUpdate) (*Bookmark, error)
+	RemoveBookmark(ctx context.Context, userID, blogID string) error
+}
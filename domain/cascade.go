@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// UserContentAction is what happens to a deleted user's blogs and comments
+// when the account is purged.
+type UserContentAction string
+
+const (
+	// DeleteUserContent removes the user's blogs and comments with the account.
+	DeleteUserContent UserContentAction = "delete"
+	// TransferUserContent keeps the content but moves it to the ghost account.
+	TransferUserContent UserContentAction = "transfer"
+)
+
+func (a UserContentAction) IsValid() bool {
+	return a == DeleteUserContent || a == TransferUserContent
+}
+
+// GhostUsername is the placeholder account that inherits transferred content.
+// It cannot log in.
+const GhostUsername = "deleted-user"
+
+// DeletedUser is a soft deleted account waiting for the purge.
+type DeletedUser struct {
+	ID            string
+	DeletedBy     string
+	DeletedAt     time.Time
+	ContentAction UserContentAction
+}
+
+// ICascadeService permanently removes records together with everything that
+// depends on them and recalculates the counters those dependents fed.
+type ICascadeService interface {
+	// PurgeBlogs removes soft deleted blogs with their comments, reactions,
+	// revisions, bookmarks and read history, and returns how many blogs went.
+	PurgeBlogs(ctx context.Context, blogIDs []string) (int64, error)
+	// PurgeComments removes soft deleted comments with their reactions and
+	// notifications, and returns how many comments went.
+	PurgeComments(ctx context.Context, commentIDs []string) (int64, error)
+	// PurgeUser removes a soft deleted account with its sessions, history,
+	// bookmarks, follows, notifications and reactions. Its blogs and comments
+	// are deleted or handed to the ghost account as user.ContentAction says.
+	PurgeUser(ctx context.Context, user *DeletedUser) error
+	// HideUserContent soft deletes the blogs and comments of a user deleted
+	// with DeleteUserContent, so they are gone before the purge.
+	HideUserContent(ctx context.Context, user *DeletedUser) error
+	// RestoreUserContent brings back what HideUserContent deleted.
+	RestoreUserContent(ctx context.Context, user *DeletedUser) error
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "context"
+
+type LiveEventType string
+
+const (
+	EventReaction        LiveEventType = "reaction"
+	EventComment         LiveEventType = "comment"
+	EventCommentEdited   LiveEventType = "comment_edited"
+	EventCommentDeleted  LiveEventType = "comment_deleted"
+	EventCommentReaction LiveEventType = "comment_reaction"
+	EventMetrics         LiveEventType = "metrics"
+	EventNotification    LiveEventType = "notification"
+	// EventBlogUnavailable tells open streams of a blog that it was deleted,
+	// hidden or unpublished. It is not passed on to clients; streams of
+	// readers who can no longer see the blog are closed.
+	EventBlogUnavailable LiveEventType = "blog_unavailable"
+)
+
+// LiveEvent is pushed to clients streaming a blog's activity or their own
+// notifications. Data is JSON encoded when published; subscribers receive it
+// back as a json.RawMessage.
+type LiveEvent struct {
+	Type LiveEventType
+	Data interface{}
+}
+
+// BlogEventTopic is the topic carrying reactions, comments and metric changes
+// of one blog.
+func BlogEventTopic(blogID string) string {
+	return "blog:" + blogID
+}
+
+// UserEventTopic is the topic carrying a user's notifications.
+func UserEventTopic(userID string) string {
+	return "user:" + userID
+}
+
+// IEventBroker fans events out to subscribers on every API instance.
+type IEventBroker interface {
+	Publish(ctx context.Context, topic string, event *LiveEvent) error
+	// Subscribe delivers the topic's events until ctx is done, then closes
+	// the channel. Events are dropped for subscribers that fall behind.
+	Subscribe(ctx context.Context, topic string) (<-chan *LiveEvent, error)
+	Close() error
+}
+
+type IEventUseCase interface {
+	// SubscribeBlog streams a blog's activity. Unpublished blogs can only be
+	// followed by their author and admins; the stream ends when the subscriber
+	// can no longer see the blog.
+	SubscribeBlog(ctx context.Context, blogID, userID, role string) (<-chan *LiveEvent, error)
+	SubscribeUser(ctx context.Context, userID string) (<-chan *LiveEvent, error)
+}
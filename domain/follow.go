@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type Follow struct {
+	FollowerID string
+	FolloweeID string
+	CreatedAt  time.Time
+}
+
+// FollowUser is the public part of a profile shown in follower lists.
+type FollowUser struct {
+	ID             string
+	Username       string
+	Bio            string
+	ProfilePicture string
+	FollowedAt     time.Time
+}
+
+type FeedReason string
+
+const (
+	FeedFollowing   FeedReason = "following"
+	FeedRecommended FeedReason = "recommended"
+)
+
+// FeedItem is a blog in a user's home feed and why it was picked.
+type FeedItem struct {
+	Blog   *Blog
+	Reason FeedReason
+}
+
+type IFollowRepository interface {
+	Follow(ctx context.Context, follow *Follow) error
+	Unfollow(ctx context.Context, followerID, followeeID string) error
+	IsFollowing(ctx context.Context, followerID, followeeID string) (bool, error)
+	// GetFollowers and GetFollowing page through the relations newest first.
+	GetFollowers(ctx context.Context, userID string, cursor string, limit int) ([]*Follow, string, error)
+	GetFollowing(ctx context.Context, userID string, cursor string, limit int) ([]*Follow, string, error)
+	GetFollowingIDs(ctx context.Context, userID string) ([]string, error)
+	// DeleteFollowsOfUser removes the user's relations in both directions and
+	// returns the other users involved.
+	DeleteFollowsOfUser(ctx context.Context, userID string) ([]string, error)
+	CountFollowers(ctx context.Context, userID string) (int64, error)
+	CountFollowing(ctx context.Context, userID string) (int64, error)
+}
+
+type IFollowUseCase interface {
+	Follow(ctx context.Context, followerID, followeeID string) error
+	Unfollow(ctx context.Context, followerID, followeeID string) error
+	GetFollowers(ctx context.Context, userID string, cursor string, limit int) ([]*FollowUser, string, error)
+	GetFollowing(ctx context.Context, userID string, cursor string, limit int) ([]*FollowUser, string, error)
+	// GetFeed mixes recent posts of followed authors with recommendations.
+	GetFeed(ctx context.Context, userID string, cursor string, limit int) ([]*FeedItem, string, error)
+}
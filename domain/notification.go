@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type NotificationType string
+
+const (
+	NotifyLike    NotificationType = "like"
+	NotifyDislike NotificationType = "dislike"
+	// NotifyReaction covers every reaction type other than like and dislike.
+	NotifyReaction NotificationType = "reaction"
+	NotifyComment  NotificationType = "comment"
+	NotifyReply    NotificationType = "reply"
+	NotifyFollow   NotificationType = "follow"
+)
+
+func (t NotificationType) IsValid() bool {
+	switch t {
+	case NotifyLike, NotifyDislike, NotifyReaction, NotifyComment, NotifyReply, NotifyFollow:
+		return true
+	}
+	return false
+}
+
+// NotificationEvent is something a user did that another user should hear
+// about. TargetID is the blog or comment acted on; it is empty for follows.
+type NotificationEvent struct {
+	RecipientID string
+	ActorID     string
+	Type        NotificationType
+	TargetID    string
+	Preview     string // blog title or comment excerpt shown with the notification
+}
+
+// Notification aggregates all unread events of one type on one target, so a
+// popular post yields "alice and 11 others liked your post" instead of twelve
+// entries. Once read, the next event starts a new notification.
+type Notification struct {
+	ID           string
+	UserID       string
+	Type         NotificationType
+	TargetID     string
+	Preview      string
+	ActorCount   int
+	RecentActors []string // up to the last three actors, oldest first
+	Summary      string
+	Read         bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type NotificationPreferences struct {
+	UserID    string
+	Muted     []NotificationType
+	UpdatedAt time.Time
+}
+
+type INotificationRepository interface {
+	// AddEvent folds the event into the recipient's unread notification for
+	// the same type and target, creating it if needed.
+	AddEvent(ctx context.Context, event *NotificationEvent, at time.Time) (*Notification, error)
+	GetNotifications(ctx context.Context, userID string, unreadOnly bool, cursor string, limit int) ([]*Notification, string, error)
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	MarkRead(ctx context.Context, userID, notificationID string) error
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+	GetPreferences(ctx context.Context, userID string) (*NotificationPreferences, error)
+	SavePreferences(ctx context.Context, prefs *NotificationPreferences) error
+	// DeleteNotificationsForUser removes the user's notifications and preferences.
+	DeleteNotificationsForUser(ctx context.Context, userID string) error
+	DeleteNotificationsForTargets(ctx context.Context, targetIDs []string) error
+}
+
+type INotificationUseCase interface {
+	// Notify records the event unless the recipient is the actor or has
+	// muted the type.
+	Notify(ctx context.Context, event *NotificationEvent) error
+	GetNotifications(ctx context.Context, userID string, unreadOnly bool, cursor string, limit int) ([]*Notification, string, error)
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	MarkRead(ctx context.Context, userID, notificationID string) error
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+	GetPreferences(ctx context.Context, userID string) (*NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID string, muted []NotificationType) (*NotificationPreferences, error)
+}
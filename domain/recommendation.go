@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// BlogRead is one entry of a user's read history.
+type BlogRead struct {
+	BlogID string
+	ReadAt time.Time
+}
+
+type IRecommendationUseCase interface {
+	// GetRecommendations pages through blogs picked for the user, leaving
+	// out what they wrote or already read. Users without history get
+	// trending blogs.
+	GetRecommendations(ctx context.Context, userID string, cursor string, limit int) ([]*Blog, string, error)
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ReportTargetType string
+
+const (
+	ReportBlog    ReportTargetType = "blog"
+	ReportComment ReportTargetType = "comment"
+)
+
+type ReportReason string
+
+const (
+	ReasonSpam           ReportReason = "spam"
+	ReasonHarassment     ReportReason = "harassment"
+	ReasonHateSpeech     ReportReason = "hate_speech"
+	ReasonMisinformation ReportReason = "misinformation"
+	ReasonSexualContent  ReportReason = "sexual_content"
+	ReasonOther          ReportReason = "other"
+)
+
+func (r ReportReason) IsValid() bool {
+	switch r {
+	case ReasonSpam, ReasonHarassment, ReasonHateSpeech, ReasonMisinformation, ReasonSexualContent, ReasonOther:
+		return true
+	}
+	return false
+}
+
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportDismissed ReportStatus = "dismissed"
+	ReportActioned  ReportStatus = "actioned"
+)
+
+func (s ReportStatus) IsValid() bool {
+	switch s {
+	case ReportOpen, ReportDismissed, ReportActioned:
+		return true
+	}
+	return false
+}
+
+// ModerationAction is what a moderator does about a report. Acting on one
+// report resolves every open report on the same target.
+type ModerationAction string
+
+const (
+	ActionDismiss ModerationAction = "dismiss" // the content is fine; reports are dismissed
+	ActionHide    ModerationAction = "hide"    // hide the content from readers; reports are actioned
+	ActionRestore ModerationAction = "restore" // show hidden content again; reports are dismissed
+	ActionDelete  ModerationAction = "delete"  // delete the content; reports are actioned
+)
+
+// Report is one user's flag on a blog or comment. BlogID is the blog the
+// target belongs to, so comment reports can link to their context.
+type Report struct {
+	ID         string
+	TargetType ReportTargetType
+	TargetID   string
+	BlogID     string
+	ReporterID string
+	Reason     ReportReason
+	Details    string
+	Status     ReportStatus
+	Action     ModerationAction
+	ResolvedBy string
+	ResolvedAt time.Time
+	Note       string // moderator's note on the resolution
+	CreatedAt  time.Time
+}
+
+type ReportFilter struct {
+	Status     ReportStatus
+	TargetType ReportTargetType
+}
+
+type IReportRepository interface {
+	CreateReport(ctx context.Context, report *Report) (*Report, error)
+	GetReportByID(ctx context.Context, reportID string) (*Report, error)
+	// ListReports pages through matching reports, oldest first.
+	ListReports(ctx context.Context, filter ReportFilter, cursor string, limit int) ([]*Report, string, error)
+	CountOpenReports(ctx context.Context, targetType ReportTargetType, targetID string) (int64, error)
+	// ResolveReports closes every open report on the target and returns how
+	// many were closed.
+	ResolveReports(ctx context.Context, targetType ReportTargetType, targetID string, resolution *Report) (int64, error)
+}
+
+type IModerationUseCase interface {
+	ReportBlog(ctx context.Context, reporterID, blogID string, reason ReportReason, details string) (*Report, error)
+	ReportComment(ctx context.Context, reporterID, blogID, commentID string, reason ReportReason, details string) (*Report, error)
+	ListReports(ctx context.Context, filter ReportFilter, cursor string, limit int) ([]*Report, string, error)
+	GetReport(ctx context.Context, reportID string) (*Report, error)
+	// ResolveReport applies the action to the report's target and returns the
+	// report as resolved.
+	ResolveReport(ctx context.Context, reportID, moderatorID string, action ModerationAction, note string) (*Report, error)
+	// UnhideBlog and UnhideComment show hidden content again, including
+	// content hidden by a report that has since been resolved.
+	UnhideBlog(ctx context.Context, blogID string) error
+	UnhideComment(ctx context.Context, blogID, commentID string) error
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+// PurgeReport counts what one purge run removed for good.
+type PurgeReport struct {
+	Blogs    int64
+	Comments int64
+	Users    int64
+}
+
+// IPurgeUseCase permanently removes soft deleted records once their
+// retention period is over.
+type IPurgeUseCase interface {
+	PurgeExpired(ctx context.Context) (*PurgeReport, error)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// BlogRevision is an immutable snapshot of a blog taken every time it is
+// edited. Changes holds only the fields that differ from the previous version.
+type BlogRevision struct {
+	ID           string
+	BlogID       string
+	Version      int
+	EditorID     string
+	Title        string
+	Content      string
+	Tags         []string
+	Changes      []FieldChange
+	RestoredFrom int // version this revision was restored from, 0 for a normal edit
+	CreatedAt    time.Time
+}
+
+type FieldChange struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = " "
+	DiffInsert DiffOp = "+"
+	DiffDelete DiffOp = "-"
+)
+
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+type RevisionDiff struct {
+	BlogID      string
+	From        int
+	To          int
+	Changes     []FieldChange
+	ContentDiff []DiffLine
+}
+
+type IBlogRevisionRepository interface {
+	CreateRevision(ctx context.Context, revision *BlogRevision) (*BlogRevision, error)
+	GetRevisions(ctx context.Context, blogID string) ([]*BlogRevision, error)
+	GetRevision(ctx context.Context, blogID string, version int) (*BlogRevision, error)
+	GetLatestRevision(ctx context.Context, blogID string) (*BlogRevision, error)
+	DeleteRevisionsForBlogs(ctx context.Context, blogIDs []string) error
+}
+
+var (
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrNothingToRestore = errors.New("revision matches the current version")
+	ErrConcurrentEdit   = errors.New("the blog was edited at the same time, try again")
+)
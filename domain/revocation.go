@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AccessTokenTTL is how long an access token is accepted after it was issued.
+const AccessTokenTTL = 30 * time.Minute
+
+// AccessClaims are the claims of a verified access token.
+type AccessClaims struct {
+	UserID    string
+	Username  string
+	Email     string
+	Role      string
+	Status    string
+	TokenID   string // jti
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// ITokenDenylist revokes access tokens before they expire, either one token
+// by its jti or every token of a user issued before some moment.
+type ITokenDenylist interface {
+	// Deny rejects the token with tokenID until it expires on its own.
+	Deny(ctx context.Context, tokenID string, expiresAt time.Time) error
+	// SetTokensValidAfter rejects the user's tokens issued before at.
+	SetTokensValidAfter(ctx context.Context, userID string, at time.Time) error
+	IsRevoked(ctx context.Context, claims *AccessClaims) (bool, error)
+}
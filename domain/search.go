@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type SearchSort string
+
+const (
+	SortRelevance  SearchSort = "relevance"
+	SortNewest     SearchSort = "newest"
+	SortOldest     SearchSort = "oldest"
+	SortMostLiked  SearchSort = "most_liked"
+	SortMostViewed SearchSort = "most_viewed"
+)
+
+// SearchQuery describes a blog search. Text is optional as long as at least
+// one filter is set; zero values mean "no filter".
+type SearchQuery struct {
+	Text     string
+	AuthorID string
+	Tags     []string // blogs must carry every one of these tags
+	From     time.Time
+	To       time.Time
+	MinLikes int
+	Sort     SearchSort
+	Cursor   string
+	Limit    int
+}
+
+type SearchResult struct {
+	Blog    *Blog
+	Score   float64
+	Snippet string // excerpt of the content with matched terms wrapped in <mark>
+}
+
+// SearchDocument is the part of a blog a search index needs to rank and
+// filter it.
+type SearchDocument struct {
+	ID        string
+	AuthorID  string
+	Title     string
+	Content   string
+	Tags      []string
+	CreatedAt time.Time
+}
+
+type SearchHit struct {
+	ID    string
+	Score float64
+}
+
+// ISearchIndex is a text search backend kept in sync by the blog usecase. It
+// only ever holds published blogs.
+type ISearchIndex interface {
+	// Index adds the document or replaces the one with the same ID.
+	Index(ctx context.Context, doc *SearchDocument) error
+	Remove(ctx context.Context, id string) error
+	// Search ranks the documents matching query.Text, best first, applying
+	// the author, tag and date filters. Like counts and sort orders other
+	// than relevance are not known to the index.
+	Search(ctx context.Context, query SearchQuery) ([]SearchHit, string, error)
+	// Rebuild replaces the whole index with docs.
+	Rebuild(ctx context.Context, docs []*SearchDocument) error
+	// Flush persists any pending changes.
+	Flush(ctx context.Context) error
+}
@@ -1,6 +1,15 @@
 package domain
 
+import "context"
+
 type IEmailServices interface {
 	SendActivationEmail(email, activationToken string) error
 	SendPasswordResetEmail(email, resetToken string) error
-}
\ No newline at end of file
+}
+
+// ITransactionManager runs fn atomically where the database supports it.
+// Repository calls made with the ctx passed to fn take part in the
+// transaction; fn may be retried, so it must be safe to run again.
+type ITransactionManager interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Algorithms access tokens can be signed with.
+const (
+	SigningEdDSA = "EdDSA"
+	SigningRS256 = "RS256"
+)
+
+// SigningKey is one key of the access token key ring. A key is published in
+// the JWKS before it starts signing and stays published until the last token
+// it signed has expired.
+type SigningKey struct {
+	ID          string // kid
+	Algorithm   string
+	Generation  int
+	PrivateKey  []byte // PKCS #8, sealed with the key encryption secret
+	CreatedAt   time.Time
+	ActivatesAt time.Time
+}
+
+type ISigningKeyRepository interface {
+	// ListSigningKeys returns every key, oldest generation first.
+	ListSigningKeys(ctx context.Context) ([]*SigningKey, error)
+	// CreateSigningKey fails with ErrSigningKeyExists when another instance
+	// already added a key of the same generation.
+	CreateSigningKey(ctx context.Context, key *SigningKey) error
+	DeleteSigningKeys(ctx context.Context, ids []string) error
+}
+
+// JSONWebKey is a public verification key as published in the JWKS.
+type JSONWebKey struct {
+	KeyType   string
+	KeyID     string
+	Use       string
+	Algorithm string
+	Curve     string // OKP keys
+	X         string // OKP keys
+	N         string // RSA keys
+	E         string // RSA keys
+}
+
+var ErrSigningKeyExists = errors.New("a signing key of this generation already exists")
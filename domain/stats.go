@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidStatsRange    = errors.New("invalid stats range; from must not be after to and the range is at most 366 days")
+	ErrInvalidStatsInterval = errors.New("invalid stats interval; use day, week or month")
+)
+
+// MaxStatsRange is the longest range, in days, one stats request covers.
+const MaxStatsRange = 366
+
+type StatsInterval string
+
+const (
+	StatsDaily   StatsInterval = "day"
+	StatsWeekly  StatsInterval = "week" // weeks start on Monday
+	StatsMonthly StatsInterval = "month"
+)
+
+func (i StatsInterval) IsValid() bool {
+	return i == StatsDaily || i == StatsWeekly || i == StatsMonthly
+}
+
+// StatsField is an activity counter kept in the daily buckets besides views.
+type StatsField string
+
+const (
+	StatsReactions StatsField = "reactions"
+	StatsComments  StatsField = "comments"
+)
+
+// StatsTotals are the counters of one bucket or range. Readers are unique
+// per day, so over a longer range they add up the daily readers.
+type StatsTotals struct {
+	Views     int
+	Readers   int
+	Reactions int
+	Comments  int
+}
+
+func (t *StatsTotals) Add(other StatsTotals) {
+	t.Views += other.Views
+	t.Readers += other.Readers
+	t.Reactions += other.Reactions
+	t.Comments += other.Comments
+}
+
+// DailyStats is the stored bucket of one blog and UTC day.
+type DailyStats struct {
+	BlogID string
+	Day    time.Time
+	StatsTotals
+}
+
+// StatsBucket is one interval of a series, starting at Start.
+type StatsBucket struct {
+	Start time.Time
+	StatsTotals
+}
+
+// StatsQuery selects the days, inclusive and in UTC, and how to group them.
+type StatsQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval StatsInterval
+}
+
+type BlogStats struct {
+	BlogID   string
+	Title    string
+	From     time.Time
+	To       time.Time
+	Interval StatsInterval
+	Totals   StatsTotals
+	Series   []*StatsBucket
+}
+
+type PostStats struct {
+	BlogID string
+	Title  string
+	StatsTotals
+}
+
+// StatsDashboard sums up all of an author's posts; Posts is ordered by views.
+type StatsDashboard struct {
+	From     time.Time
+	To       time.Time
+	Interval StatsInterval
+	Totals   StatsTotals
+	Series   []*StatsBucket
+	Posts    []*PostStats
+}
+
+type IStatsRepository interface {
+	// AddActivity adds n to the field in the blog's bucket for day.
+	AddActivity(ctx context.Context, blogID string, day time.Time, field StatsField, n int) error
+	// AddViews adds the views to their buckets and raises the readers to the
+	// counts given, which are the day's unique readers so far.
+	AddViews(ctx context.Context, views []*ViewCount) error
+	// MarkViewBatch records a flushed batch of views as written and reports
+	// false when it already was.
+	MarkViewBatch(ctx context.Context, batchID string) (bool, error)
+	// GetDailyStats returns the stored buckets of the blogs between from and
+	// to, both inclusive.
+	GetDailyStats(ctx context.Context, blogIDs []string, from, to time.Time) ([]*DailyStats, error)
+	DeleteStatsForBlogs(ctx context.Context, blogIDs []string) error
+}
+
+type IStatsUseCase interface {
+	// GetBlogStats is for the blog's author and admins only.
+	GetBlogStats(ctx context.Context, blogID, userID string, role Role, query StatsQuery) (*BlogStats, error)
+	GetDashboard(ctx context.Context, authorID string, query StatsQuery) (*StatsDashboard, error)
+}
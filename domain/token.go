@@ -6,12 +6,40 @@ import (
 	"time"
 )
 
+// RefreshToken is one link in a session's token family. Every refresh spends
+// the current token and issues the next one for the same session.
 type RefreshToken struct {
 	ID        string
 	Token     string
 	UserID    string
 	ExpiresAt time.Time
 	CreatedAt time.Time
+
+	SessionID        string
+	SessionStartedAt time.Time
+	Used             bool
+	Device           string
+	UserAgent        string
+	IP               string
+}
+
+// Session is a signed-in device, described by its newest unspent refresh token.
+type Session struct {
+	ID         string
+	Device     string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+	Current    bool
+}
+
+// SessionClient is what a login or refresh request tells about the device.
+type SessionClient struct {
+	Device    string
+	UserAgent string
+	IP        string
 }
 
 type TokenPair struct {
@@ -38,11 +66,18 @@ type ActivateUserToken struct {
 
 type IRefreshTokenRepository interface {
 	// Refresh Tokens
-	CreateRefreshToken(ctx context.Context, userID, refToken string) (*RefreshToken, error)
-	ReplaceRefreshToken(ctx context.Context, userID, refToken string) (*RefreshToken, error)
+	// CreateRefreshToken stores token, starting a new session when it has no SessionID.
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) (*RefreshToken, error)
 	GetRefreshToken(ctx context.Context, token string) (*RefreshToken, error)
-	DeleteRefreshToken(ctx context.Context, token string) error
+	// UseRefreshToken spends token. A token that was already spent is returned
+	// along with ErrRefreshTokenReused.
+	UseRefreshToken(ctx context.Context, token string) (*RefreshToken, error)
 	DeleteRefreshTokensForUser(ctx context.Context, userID string) error
+
+	// Sessions
+	ListSessions(ctx context.Context, userID string) ([]*Session, error)
+	DeleteSession(ctx context.Context, userID, sessionID string) error
+	DeleteOtherSessions(ctx context.Context, userID, sessionID string) (int64, error)
 }
 
 type IResetTokenRepository interface {
@@ -60,8 +95,42 @@ type IActivationTokenRepository interface {
 
 var (
 	ErrTokenNotFound = errors.New("token not found")
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrCurrentSessionUnknown = errors.New("current session could not be determined")
 	ErrInvalidBlogTitle = errors.New("invalid blog title")
 	ErrInvalidBlogContent = errors.New("invalid blog content")
+	ErrInvalidBlogStatus = errors.New("invalid blog status")
+	ErrInvalidPublishTime = errors.New("publish time must be in the future")
+	ErrBlogNotFound = errors.New("blog not found")
+	ErrReactionNotFound = errors.New("no reaction to remove")
+	ErrInvalidReactionType = errors.New("invalid reaction type")
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrInvalidParentComment = errors.New("parent comment does not belong to this blog")
+	ErrCommentTooDeep = errors.New("maximum reply depth reached")
+	ErrInvalidCommentSort = errors.New("invalid comment sort")
+	ErrParentCommentDeleted = errors.New("restore the parent comment first")
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+	ErrInvalidSearchSort = errors.New("invalid search sort")
+	ErrEmptySearch = errors.New("search needs a query or at least one filter")
+	ErrReadingListNotFound = errors.New("reading list not found")
+	ErrInvalidReadingListName = errors.New("reading list name must be between 1 and 100 characters")
+	ErrReadingListExists = errors.New("a reading list with this name already exists")
+	ErrDefaultReadingList = errors.New("the default reading list cannot be changed")
+	ErrBookmarkNotFound = errors.New("bookmark not found")
+	ErrAlreadyBookmarked = errors.New("blog is already bookmarked")
+	ErrInvalidBookmarkOrder = errors.New("order must list every bookmark in the reading list exactly once")
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidNotificationType = errors.New("invalid notification type")
+	ErrReportNotFound = errors.New("report not found")
+	ErrAlreadyReported = errors.New("you have already reported this content")
+	ErrReportResolved = errors.New("report is already resolved")
+	ErrCannotReportOwnContent = errors.New("you cannot report your own content")
+	ErrInvalidReportReason = errors.New("invalid report reason")
+	ErrInvalidReportStatus = errors.New("invalid report status")
+	ErrInvalidReportTarget = errors.New("invalid report target type")
+	ErrInvalidModerationAction = errors.New("invalid moderation action")
 	ErrInvalidPasswordResetToken = errors.New("invalid password reset token")
 	ErrPasswordResetTokenExpired = errors.New("password reset token expired")
 	ErrTokenUsed = errors.New("token already used")
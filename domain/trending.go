@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrInvalidTrendingWindow = errors.New("invalid trending window; use 24h, 7d or 30d")
+
+// TrendingWindow is the span of activity a trending ranking looks at. Older
+// activity fades faster the shorter the window.
+type TrendingWindow string
+
+const (
+	TrendingDay   TrendingWindow = "24h"
+	TrendingWeek  TrendingWindow = "7d"
+	TrendingMonth TrendingWindow = "30d"
+)
+
+var TrendingWindows = []TrendingWindow{TrendingDay, TrendingWeek, TrendingMonth}
+
+func (w TrendingWindow) IsValid() bool {
+	return w == TrendingDay || w == TrendingWeek || w == TrendingMonth
+}
+
+// ITrendingIndex ranks blogs by recent activity in every window at once.
+// Each bit of activity adds its weight, which then decays over time.
+type ITrendingIndex interface {
+	// Bump adds the weights, keyed by blog id, as of at.
+	Bump(ctx context.Context, weights map[string]float64, at time.Time) error
+	Remove(ctx context.Context, blogIDs ...string) error
+	// Top returns blog ids from the highest score down, skipping offset.
+	Top(ctx context.Context, window TrendingWindow, offset, count int) ([]string, error)
+	// Maintain drops blogs without activity inside the window and keeps
+	// the scores in range. It is run periodically.
+	Maintain(ctx context.Context, now time.Time) error
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// TwoFactorChallengeTTL is how long a login may wait for its second factor.
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// After MaxTwoFactorFailures codes without a right one the user's second
+	// factor is locked until TwoFactorLockout after the first of them.
+	MaxTwoFactorFailures = 5
+	TwoFactorLockout     = 15 * time.Minute
+	RecoveryCodeCount    = 10
+)
+
+// TwoFactor is a user's TOTP setup. Secrets are sealed; recovery codes are
+// only stored hashed.
+type TwoFactor struct {
+	UserID            string
+	Enabled           bool
+	Secret            []byte
+	PendingSecret     []byte // enrolled but not confirmed with a code yet
+	RecoveryCodesLeft int
+	EnabledAt         *time.Time
+}
+
+// TOTPEnrolment is what an authenticator app needs to add the account.
+type TOTPEnrolment struct {
+	Secret          string // base32, for typing in by hand
+	ProvisioningURI string // otpauth:// URI, usually shown as a QR code
+}
+
+type ITwoFactorRepository interface {
+	// GetTwoFactor returns ErrTwoFactorNotEnabled when the user never enrolled.
+	GetTwoFactor(ctx context.Context, userID string) (*TwoFactor, error)
+	SetPendingSecret(ctx context.Context, userID string, sealed []byte) error
+	// EnableTwoFactor turns on the pending secret if it is still sealed.
+	EnableTwoFactor(ctx context.Context, userID string, sealed []byte, recoveryHashes []string, step int64) error
+	// UseTwoFactorStep records a TOTP time step as used. It reports false
+	// when the step or a later one was used already, so codes cannot be
+	// replayed.
+	UseTwoFactorStep(ctx context.Context, userID string, step int64) (bool, error)
+	// UseRecoveryCode removes the code with the hash, reporting whether it
+	// was there.
+	UseRecoveryCode(ctx context.Context, userID, hash string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID string, recoveryHashes []string) error
+	// ReplaceSecret swaps the enabled secret for the same secret sealed
+	// again, unless it changed in the meantime.
+	ReplaceSecret(ctx context.Context, userID string, previous, sealed []byte) error
+	DeleteTwoFactor(ctx context.Context, userID string) error
+}
+
+type ITOTPService interface {
+	// NewSecret returns a fresh secret sealed for storage and how to enrol it.
+	NewSecret(accountName string) ([]byte, *TOTPEnrolment, error)
+	// Verify checks code against the sealed secret, allowing a step of
+	// clock drift either way, and returns the time step it matched.
+	Verify(sealed []byte, code string, now time.Time) (int64, bool)
+	// Reseal returns the secret sealed with the current key when it is still
+	// sealed with the legacy one, and false otherwise.
+	Reseal(sealed []byte) ([]byte, bool)
+}
+
+// ITwoFactorStore holds pending login challenges and counts wrong codes.
+type ITwoFactorStore interface {
+	CreateChallenge(ctx context.Context, userID string, ttl time.Duration) (string, error)
+	// ChallengeUser returns ErrInvalidTwoFactorChallenge for unknown or
+	// expired challenges.
+	ChallengeUser(ctx context.Context, challenge string) (string, error)
+	// DeleteChallenge reports whether the challenge was still there, so only
+	// one request can complete it.
+	DeleteChallenge(ctx context.Context, challenge string) (bool, error)
+	// RecordAttempt counts a code about to be checked and returns how many
+	// were tried since the last right one, this one included. The count
+	// expires window after its first attempt.
+	RecordAttempt(ctx context.Context, userID string, window time.Duration) (int64, error)
+	ClearFailures(ctx context.Context, userID string) error
+}
+
+// TwoFactorRequiredError is returned when the password was right but the
+// login still needs a second factor, given with the challenge.
+type TwoFactorRequiredError struct {
+	Challenge string
+	ExpiresAt time.Time
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "two-factor authentication required"
+}
+
+var (
+	ErrTwoFactorNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled      = errors.New("start two-factor enrolment first")
+	ErrInvalidTwoFactorCode      = errors.New("invalid two-factor code")
+	ErrTwoFactorLocked           = errors.New("too many wrong codes, try again later")
+	ErrInvalidTwoFactorChallenge = errors.New("login challenge is invalid or expired")
+)
@@ -16,8 +16,10 @@ const (
 type Status string
 
 const (
-	Active   Status = "active"
-	Inactive Status = "inactive"
+	Active    Status = "active"
+	Inactive  Status = "inactive"
+	Suspended Status = "suspended" // until StatusUntil, or until lifted when it is nil
+	Banned    Status = "banned"
 )
 
 type User struct {
@@ -28,6 +30,8 @@ type User struct {
 	PasswordHash string 
 	Role         Role
 	Status       Status   
+	StatusReason string
+	StatusUntil  *time.Time
 	CreatedAt    time.Time 
 	UpdatedAt    time.Time 
 
@@ -35,8 +39,43 @@ type User struct {
 	Bio            string
 	ProfilePicture string
 	ContactInfo    string
+
+	FollowerCount  int
+	FollowingCount int
+}
+
+// EffectiveStatus is the user's status at now; a suspension that ran out
+// counts as active.
+func (u *User) EffectiveStatus(now time.Time) Status {
+	if u.Status == Suspended && u.StatusUntil != nil && !now.Before(*u.StatusUntil) {
+		return Active
+	}
+	return u.Status
+}
+
+// AccountRestrictedError is returned when a suspended or banned user tries
+// to sign in.
+type AccountRestrictedError struct {
+	Status Status
+	Reason string
+	Until  *time.Time
 }
 
+func (e *AccountRestrictedError) Error() string {
+	if e.Status == Banned {
+		return "account is banned"
+	}
+	if e.Until != nil {
+		return "account is suspended until " + e.Until.UTC().Format(time.RFC3339)
+	}
+	return "account is suspended"
+}
+
+const (
+	FollowerCountField  UpdateMetricsField = "follower_count"
+	FollowingCountField UpdateMetricsField = "following_count"
+)
+
 type Login struct {
 	Email    string
 	Password string
@@ -51,16 +90,33 @@ type IUserRepository interface {
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
 	GetUserByUsernameAndEmail(ctx context.Context, username, email string) (*User, error)
-	GetUsers(ctx context.Context, page, limit int) ([]*User, int64, error)
+	GetUsers(ctx context.Context, cursor string, limit int) ([]*User, string, error)
+	GetUsersByIDs(ctx context.Context, ids []string) ([]*User, error)
 	UpdateUser(ctx context.Context, id string, updates map[string]interface{}) error
-	DeleteUser(ctx context.Context, id string) error
+	// DeleteUser soft deletes the user at the given time and records what
+	// the purge does with their content; lookups leave them out afterwards.
+	DeleteUser(ctx context.Context, id string, deletedBy string, action UserContentAction, at time.Time) error
+	// RestoreUser undoes DeleteUser and returns the deletion it undid.
+	RestoreUser(ctx context.Context, id string) (*DeletedUser, error)
+	// ListDeletedUsers returns up to limit users soft deleted before the
+	// cutoff, oldest deletion first.
+	ListDeletedUsers(ctx context.Context, before time.Time, limit int) ([]*DeletedUser, error)
+	PurgeUser(ctx context.Context, id string) error
+	// EnsureGhostUser returns the ghost account, creating it on first use.
+	EnsureGhostUser(ctx context.Context) (*User, error)
 
 	// Profile Management
 	UpdateProfile(ctx context.Context, userID string, updates map[string]interface{}) error
+	UpdateUserMetrics(ctx context.Context, userID string, field string, increment int) error
+	SetUserMetrics(ctx context.Context, userID string, metrics map[string]int) error
 
 	// Admin Actions
 	PromoteToAdmin(ctx context.Context, userID string) error
 	DemoteToUser(ctx context.Context, userID string) error
+	// RestrictUser suspends or bans the user.
+	RestrictUser(ctx context.Context, userID string, status Status, reason string, until *time.Time) error
+	// LiftRestriction makes a suspended or banned user active again.
+	LiftRestriction(ctx context.Context, userID string) error
 }
 
 type IUserUseCase interface {
@@ -70,8 +126,15 @@ type IUserUseCase interface {
 	// Admin Only
 	PromoteToAdmin(ctx context.Context, targetUserID string) error
 	DemoteToUser(ctx context.Context, targetUserID string) error
-	GetUsers(ctx context.Context, page, limit int) ([]*User, int64, error)
-	DeleteUser(ctx context.Context, id string) error
+	GetUsers(ctx context.Context, cursor string, limit int) ([]*User, string, error)
+	// DeleteUser soft deletes the account. With DeleteUserContent its blogs
+	// and comments are hidden right away; RestoreUser brings them back.
+	DeleteUser(ctx context.Context, id string, deletedBy string, action UserContentAction) error
+	RestoreUser(ctx context.Context, id string) error
+	// SuspendUser suspends the user until until, or until lifted when it is nil.
+	SuspendUser(ctx context.Context, id, actorID, reason string, until *time.Time) error
+	BanUser(ctx context.Context, id, actorID, reason string) error
+	UnsuspendUser(ctx context.Context, id string) error
 }
 
 var (
@@ -80,5 +143,13 @@ var (
 	ErrUserNotAuthorized = errors.New("user not authorized")
 	ErrUserNotFound    = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrCannotFollowSelf = errors.New("users cannot follow themselves")
+	ErrAlreadyFollowing = errors.New("already following this user")
+	ErrNotFollowing = errors.New("not following this user")
+	ErrInvalidContentAction = errors.New("content must be either 'delete' or 'transfer'")
+	ErrGhostAccount = errors.New("the ghost account cannot be changed")
+	ErrCannotRestrictSelf = errors.New("admins cannot suspend or ban themselves")
+	ErrInvalidSuspension = errors.New("suspension must end in the future")
+	ErrUserNotRestricted = errors.New("user is neither suspended nor banned")
 
 )
\ No newline at end of file
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ViewCount is the views one blog got on one UTC day that were not yet
+// written, with the day's unique readers so far.
+type ViewCount struct {
+	BlogID  string
+	Day     time.Time
+	Views   int
+	Readers int
+}
+
+// IViewCounter counts blog views outside the database. Views are buffered
+// and written to the blogs in batches by a background job.
+type IViewCounter interface {
+	// RecordView counts a view unless the viewer already viewed the blog
+	// within the dedupe window, and reports whether it was counted.
+	RecordView(ctx context.Context, blogID, viewer string) (bool, error)
+	// FlushViews hands the buffered views to apply in batches and forgets
+	// them once every batch is applied. After a failure the batches are
+	// handed over again on the next flush under the same batchID, so apply
+	// can skip the ones it already wrote. Only one instance flushes at a
+	// time; the others return right away.
+	FlushViews(ctx context.Context, apply func(ctx context.Context, batchID string, views []*ViewCount) error) (int64, error)
+}
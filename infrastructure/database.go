@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -64,6 +65,13 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "created_at", Value: 1}}, // For sorting users
 		},
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, // For cursor pagination of users
+		},
+		{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}}, // For the retention purge
+			Options: options.Index().SetSparse(true),
+		},
 	}
 	if _, err := usersCollection.Indexes().CreateMany(ctx, userIndexes); err != nil {
 		return fmt.Errorf("failed to create user indexes: %w", err)
@@ -72,6 +80,10 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 
 	// --- Blogs Collection Indexes ---
 	blogsCollection := db.Collection("blogs")
+	// A collection can only have one text index, so the old title-only one has to go first.
+	if err := blogsCollection.Indexes().DropOne(ctx, "text_title"); err != nil && !isIndexNotFound(err) {
+		return fmt.Errorf("failed to drop legacy text index: %w", err)
+	}
 	blogIndexes := []mongo.IndexModel{
 		{
 			Keys: bson.D{{Key: "author_id", Value: 1}}, // For fetching blogs by author
@@ -80,8 +92,9 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 			Keys: bson.D{{Key: "tags", Value: 1}}, // Multi-key index for tags array
 		},
 		{
-			Keys:    bson.D{{Key: "title", Value: "text"}}, // Text index for full-text search on title
-			Options: options.Index().SetName("text_title"),
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "tags", Value: "text"}, {Key: "content", Value: "text"}}, // Weighted text index for blog search
+			Options: options.Index().SetName("text_search").
+				SetWeights(bson.D{{Key: "title", Value: 10}, {Key: "tags", Value: 5}, {Key: "content", Value: 1}}),
 		},
 		{
 			Keys: bson.D{{Key: "created_at", Value: -1}}, // For sorting by recent blogs
@@ -95,12 +108,148 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "comment_count", Value: -1}}, // For sorting by most commented
 		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "publish_at", Value: 1}}, // For the scheduled publisher
+		},
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, // For cursor pagination of blog listings
+		},
+		{
+			Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, // For cursor pagination by author
+		},
+		{
+			Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "updated_at", Value: -1}}, // For keying cached feeds on the followed authors' latest change
+		},
+		{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}}, // For the retention purge
+			Options: options.Index().SetSparse(true),
+		},
 	}
 	if _, err := blogsCollection.Indexes().CreateMany(ctx, blogIndexes); err != nil {
 		return fmt.Errorf("failed to create blog indexes: %w", err)
 	}
+	// blogs from before reaction types only counted likes and dislikes in
+	// like_count and dislike_count; copy those into reaction_counts so the
+	// per type counts stay complete once other reactions arrive
+	legacyReactionCounts := bson.M{"$expr": bson.M{"$or": bson.A{
+		bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{"$reaction_counts.like", 0}}, bson.M{"$ifNull": bson.A{"$like_count", 0}}}},
+		bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{"$reaction_counts.dislike", 0}}, bson.M{"$ifNull": bson.A{"$dislike_count", 0}}}},
+	}}}
+	copyReactionCounts := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"reaction_counts.like":    bson.M{"$ifNull": bson.A{"$like_count", 0}},
+			"reaction_counts.dislike": bson.M{"$ifNull": bson.A{"$dislike_count", 0}},
+		}}},
+	}
+	if _, err := blogsCollection.UpdateMany(ctx, legacyReactionCounts, copyReactionCounts); err != nil {
+		return fmt.Errorf("failed to backfill blog reaction counts: %w", err)
+	}
+	// blogs from before bookmarks have no save count, which would drop them
+	// out of the save_count sort once paging passes the first page
+	if _, err := blogsCollection.UpdateMany(ctx, bson.M{"save_count": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"save_count": 0}}); err != nil {
+		return fmt.Errorf("failed to backfill blog save counts: %w", err)
+	}
 	log.Println("Blog indexes ensured.")
 
+	// --- Blog Revisions Collection Indexes ---
+	revisionsCollection := db.Collection("blog_revisions")
+	revisionIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true), // One document per blog version
+		},
+	}
+	if _, err := revisionsCollection.Indexes().CreateMany(ctx, revisionIndexes); err != nil {
+		return fmt.Errorf("failed to create blog revision indexes: %w", err)
+	}
+	log.Println("Blog revision indexes ensured.")
+
+	// --- Bookmark Collections Indexes ---
+	readingListIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true), // List names are unique per user
+		},
+	}
+	if _, err := db.Collection("reading_lists").Indexes().CreateMany(ctx, readingListIndexes); err != nil {
+		return fmt.Errorf("failed to create reading list indexes: %w", err)
+	}
+	bookmarkIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "blog_id", Value: 1}},
+			Options: options.Index().SetUnique(true), // A user saves a blog once
+		},
+		{
+			Keys: bson.D{{Key: "list_id", Value: 1}, {Key: "position", Value: 1}, {Key: "_id", Value: 1}}, // For paging a list in order
+		},
+		{
+			Keys: bson.D{{Key: "blog_id", Value: 1}}, // For cleaning up after deleted blogs
+		},
+	}
+	if _, err := db.Collection("bookmarks").Indexes().CreateMany(ctx, bookmarkIndexes); err != nil {
+		return fmt.Errorf("failed to create bookmark indexes: %w", err)
+	}
+	log.Println("Bookmark indexes ensured.")
+
+	// --- Follows Collection Indexes ---
+	followIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+			Options: options.Index().SetUnique(true), // Following someone twice is not possible
+		},
+		{
+			Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, // For listing who a user follows
+		},
+		{
+			Keys: bson.D{{Key: "followee_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, // For listing a user's followers
+		},
+	}
+	if _, err := db.Collection("follows").Indexes().CreateMany(ctx, followIndexes); err != nil {
+		return fmt.Errorf("failed to create follow indexes: %w", err)
+	}
+	log.Println("Follow indexes ensured.")
+
+	// --- Notifications Collection Indexes ---
+	notificationIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "type", Value: 1}, {Key: "target_id", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"read": false}), // One unread notification per type and target to aggregate into
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}, // For listing a user's notifications
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "read", Value: 1}}, // For unread counts and mark-all-read
+		},
+		{
+			Keys: bson.D{{Key: "target_id", Value: 1}}, // For dropping notifications about purged blogs
+		},
+	}
+	if _, err := db.Collection("notifications").Indexes().CreateMany(ctx, notificationIndexes); err != nil {
+		return fmt.Errorf("failed to create notification indexes: %w", err)
+	}
+	log.Println("Notification indexes ensured.")
+
+	// --- Reports Collection Indexes ---
+	reportIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "reporter_id", Value: 1}},
+			Options: options.Index().SetUnique(true), // A user reports the same content once
+		},
+		{
+			Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "status", Value: 1}}, // For counting and resolving open reports
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}, // For the moderation queue
+		},
+	}
+	if _, err := db.Collection("reports").Indexes().CreateMany(ctx, reportIndexes); err != nil {
+		return fmt.Errorf("failed to create report indexes: %w", err)
+	}
+	log.Println("Report indexes ensured.")
+
 	// --- Comments Collection Indexes ---
 	commentsCollection := db.Collection("comments")
 	commentIndexes := []mongo.IndexModel{
@@ -113,28 +262,107 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "created_at", Value: 1}}, // For sorting comments
 		},
+		{
+			Keys: bson.D{{Key: "blogid", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}, // For paginating a blog's comment threads
+		},
+		{
+			Keys: bson.D{{Key: "blogid", Value: 1}, {Key: "score", Value: -1}, {Key: "_id", Value: -1}}, // For paginating a blog's comments by score
+		},
+		{
+			Keys: bson.D{{Key: "ancestors", Value: 1}}, // For deleting a whole reply thread
+		},
+		{
+			Keys: bson.D{{Key: "authorid", Value: 1}}, // For removing or transferring a purged user's comments
+		},
+		{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}}, // For the retention purge
+			Options: options.Index().SetSparse(true),
+		},
 	}
 	if _, err := commentsCollection.Indexes().CreateMany(ctx, commentIndexes); err != nil {
 		return fmt.Errorf("failed to create comment indexes: %w", err)
 	}
+	// comments from before comment reactions have no score, which would
+	// drop them out of the top sort once paging passes zero
+	if _, err := commentsCollection.UpdateMany(ctx, bson.M{"score": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"score": 0}}); err != nil {
+		return fmt.Errorf("failed to backfill comment scores: %w", err)
+	}
+	// older comments stored their creation time as createdat, which the
+	// created_at sort and cursors would otherwise treat as missing
+	legacyCreatedAt := bson.M{"created_at": bson.M{"$exists": false}, "createdat": bson.M{"$exists": true}}
+	copyCreatedAt := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"created_at": "$createdat"}}},
+		{{Key: "$unset", Value: "createdat"}},
+	}
+	if _, err := commentsCollection.UpdateMany(ctx, legacyCreatedAt, copyCreatedAt); err != nil {
+		return fmt.Errorf("failed to backfill comment creation times: %w", err)
+	}
 	log.Println("Comment indexes ensured.")
 
 	// --- Reactions Collection Indexes ---
 	reactionsCollection := db.Collection("reactions")
+	// replaced by the index below, which also covers reactions on comments
+	if err := reactionsCollection.Indexes().DropOne(ctx, "blog_id_1_user_id_1"); err != nil && !isIndexNotFound(err) {
+		return fmt.Errorf("failed to drop the old reaction index: %w", err)
+	}
 	reactionIndexes := []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "user_id", Value: 1}},
-			Options: options.Index().SetUnique(true), // Ensures one reaction per user per blog
+			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "comment_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true), // Ensures one reaction per user per blog or comment
+		},
+		{
+			Keys: bson.D{{Key: "comment_id", Value: 1}}, // For counting and removing a comment's reactions
 		},
 		{
 			Keys: bson.D{{Key: "blog_id", Value: 1}}, // For fetching all reactions for a blog
 		},
+		{
+			Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "type", Value: 1}, {Key: "_id", Value: -1}}, // For listing who reacted with a type
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "_id", Value: -1}}, // For a user's recent likes in recommendations
+		},
 	}
 	if _, err := reactionsCollection.Indexes().CreateMany(ctx, reactionIndexes); err != nil {
 		return fmt.Errorf("failed to create reaction indexes: %w", err)
 	}
 	log.Println("Reaction indexes ensured.")
 
+	// --- Blog Stats Collection Indexes ---
+	statsCollection := db.Collection("blog_stats")
+	statsIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "day", Value: 1}},
+			Options: options.Index().SetUnique(true), // One bucket per blog and day
+		},
+	}
+	if _, err := statsCollection.Indexes().CreateMany(ctx, statsIndexes); err != nil {
+		return fmt.Errorf("failed to create blog stats indexes: %w", err)
+	}
+	viewBatchIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index
+		},
+	}
+	if _, err := db.Collection("view_batches").Indexes().CreateMany(ctx, viewBatchIndexes); err != nil {
+		return fmt.Errorf("failed to create view batch indexes: %w", err)
+	}
+	log.Println("Blog stats indexes ensured.")
+
+	// --- Signing Keys Collection Indexes ---
+	signingKeysCollection := db.Collection("signing_keys")
+	signingKeyIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "generation", Value: 1}},
+			Options: options.Index().SetUnique(true), // One key per rotation, however many instances rotate
+		},
+	}
+	if _, err := signingKeysCollection.Indexes().CreateMany(ctx, signingKeyIndexes); err != nil {
+		return fmt.Errorf("failed to create signing key indexes: %w", err)
+	}
+	log.Println("Signing key indexes ensured.")
+
 	// --- Refresh Tokens Collection Indexes ---
 	refreshTokensCollection := db.Collection("refreshTokens")
 	refreshTokenIndexes := []mongo.IndexModel{
@@ -143,7 +371,10 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 			Options: options.Index().SetUnique(true),
 		},
 		{
-			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}, // For listing a user's sessions
+		},
+		{
+			Keys: bson.D{{Key: "session_id", Value: 1}}, // For revoking a session's token family
 		},
 		{
 			Keys:    bson.D{{Key: "expires_at", Value: 1}},
@@ -186,6 +417,9 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "created_at", Value: -1}}, // For fetching recent history
 		},
+		{
+			Keys: bson.D{{Key: "reads.blog_id", Value: 1}}, // For dropping purged blogs from histories
+		},
 	}
 	if _, err := historyCollection.Indexes().CreateMany(ctx, historyIndexes); err != nil {
 		return fmt.Errorf("failed to create history indexes: %w", err)
@@ -212,3 +446,11 @@ func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
 
 	return nil
 }
+
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code == 27 || cmdErr.Name == "IndexNotFound" || cmdErr.Code == 26 // 26: namespace not found
+	}
+	return false
+}
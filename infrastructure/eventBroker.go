@@ -0,0 +1,122 @@
+package infrastructure
+
+import (
+	"blog-backend/domain"
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	eventChannelPrefix   = "events:"
+	subscriberBufferSize = 32
+)
+
+// redisEventBroker shares one Redis pub/sub connection between all local
+// subscribers. A Redis channel is subscribed while at least one local
+// subscriber wants it, so every instance receives exactly the topics its
+// clients are streaming.
+type redisEventBroker struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+
+	mu          sync.RWMutex
+	subscribers map[string]map[chan *domain.LiveEvent]struct{}
+}
+
+type eventEnvelope struct {
+	Type domain.LiveEventType `json:"type"`
+	Data json.RawMessage      `json:"data"`
+}
+
+func NewRedisEventBroker(client *redis.Client) domain.IEventBroker {
+	broker := &redisEventBroker{
+		client:      client,
+		pubsub:      client.Subscribe(context.Background()),
+		subscribers: make(map[string]map[chan *domain.LiveEvent]struct{}),
+	}
+	go broker.dispatch()
+	return broker
+}
+
+func (b *redisEventBroker) Publish(ctx context.Context, topic string, event *domain.LiveEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(eventEnvelope{Type: event.Type, Data: data})
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, eventChannelPrefix+topic, payload).Err()
+}
+
+func (b *redisEventBroker) Subscribe(ctx context.Context, topic string) (<-chan *domain.LiveEvent, error) {
+	channel := eventChannelPrefix + topic
+	events := make(chan *domain.LiveEvent, subscriberBufferSize)
+
+	b.mu.Lock()
+	subs, ok := b.subscribers[channel]
+	if !ok {
+		if err := b.pubsub.Subscribe(ctx, channel); err != nil {
+			b.mu.Unlock()
+			return nil, err
+		}
+		subs = make(map[chan *domain.LiveEvent]struct{})
+		b.subscribers[channel] = subs
+	}
+	subs[events] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.unsubscribe(channel, events)
+	}()
+	return events, nil
+}
+
+func (b *redisEventBroker) unsubscribe(channel string, events chan *domain.LiveEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs := b.subscribers[channel]
+	delete(subs, events)
+	close(events)
+	if len(subs) > 0 {
+		return
+	}
+	delete(b.subscribers, channel)
+	if err := b.pubsub.Unsubscribe(context.Background(), channel); err != nil {
+		log.Printf("Failed to unsubscribe from %s: %v", channel, err)
+	}
+}
+
+// dispatch hands every message from Redis to the local subscribers of its
+// channel until the broker is closed.
+func (b *redisEventBroker) dispatch() {
+	for msg := range b.pubsub.Channel() {
+		var envelope eventEnvelope
+		if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+			log.Printf("Dropping malformed event on %s: %v", msg.Channel, err)
+			continue
+		}
+		event := &domain.LiveEvent{Type: envelope.Type, Data: envelope.Data}
+
+		b.mu.RLock()
+		for events := range b.subscribers[msg.Channel] {
+			select {
+			case events <- event:
+			default:
+				// the client is not keeping up; it can refetch to catch up
+			}
+		}
+		b.mu.RUnlock()
+	}
+}
+
+func (b *redisEventBroker) Close() error {
+	return b.pubsub.Close()
+}
@@ -2,57 +2,372 @@ package infrastructure
 
 import (
 	"blog-backend/domain"
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
+const (
+	// keyPublishLead is how long a new key is in the JWKS before it signs,
+	// so instances and verifiers caching the key ring pick it up first.
+	keyPublishLead = 10 * time.Minute
+	// keyClockSkew is allowed between instances when retiring keys.
+	keyClockSkew = time.Minute
+)
+
+// JWTOptions configure how access tokens are signed and verified.
+type JWTOptions struct {
+	// KeySecret seals the private keys stored in the database.
+	KeySecret        string
+	Algorithm        string
+	Issuer           string
+	Audience         string
+	RotationInterval time.Duration
+}
+
+type signingKey struct {
+	id          string
+	method      jwt.SigningMethod
+	private     crypto.Signer
+	activatesAt time.Time
+	// verifyUntil is when the last token signed with the key expires; zero
+	// while no newer key has taken over
+	verifyUntil time.Time
+}
+
+// jWTService signs access tokens with the newest active key of a key ring
+// kept in the database. Every instance reloads the ring in RotateKeys, and
+// whichever instance finds the signing key due adds its successor.
 type jWTService struct {
-	secret []byte
+	repository domain.ISigningKeyRepository
+	sealer     cipher.AEAD
+	options    JWTOptions
+
+	mu   sync.RWMutex
+	keys []*signingKey // oldest first
 }
 
-func NewJWTService(secret string) domain.IJWTService {
-	return &jWTService{secret: []byte(secret)}
+// NewJWTService loads the key ring, creating the first key on first start.
+func NewJWTService(ctx context.Context, repository domain.ISigningKeyRepository, options JWTOptions) (domain.IJWTService, error) {
+	secret := sha256.Sum256([]byte(options.KeySecret))
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, err
+	}
+	sealer, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &jWTService{repository: repository, sealer: sealer, options: options}
+	if err := j.RotateKeys(ctx); err != nil {
+		return nil, err
+	}
+	return j, nil
 }
 
-func (j *jWTService) GenerateToken(userID,username, email, role string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID,
+func (j *jWTService) GenerateToken(userID,username, email, role, status string) (string, error) {
+	now := time.Now()
+	key := j.signingKey(now)
+	if key == nil {
+		return "", errors.New("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(key.method, jwt.MapClaims{
+		"iss":      j.options.Issuer,
+		"aud":      j.options.Audience,
+		"user_id":  userID,
 		"username": username,
 		"email":    email,
 		"role":     role,
-		"exp":      time.Now().Add(30 * time.Minute).Unix(),
+		"status":   status,
+		"jti":      uuid.New().String(),
+		// milliseconds, so a token issued right after the user's tokens
+		// were revoked is told apart from the revoked ones
+		"iat":      float64(now.UnixMilli()) / 1000,
+		"nbf":      now.Unix(),
+		"exp":      now.Add(domain.AccessTokenTTL).Unix(),
 	})
+	token.Header["kid"] = key.id
 
-	return token.SignedString([]byte(j.secret))
+	return token.SignedString(key.private)
 }
 
 
-func (s *jWTService) ParseToken(tokenString string) (string, string,error) {
+func (j *jWTService) ParseToken(tokenString string) (*domain.AccessClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key := j.verificationKey(kid, time.Now())
+		if key == nil {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return s.secret, nil
+		return key.private.Public(), nil
 	})
 
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID, ok := claims["user_id"].(string)
-		if !ok {
-			return "", "", fmt.Errorf("invalid user id in token")
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+	// Valid only checks the time claims that are present
+	for _, name := range []string{"exp", "iat", "nbf"} {
+		if _, ok := claims[name]; !ok {
+			return nil, fmt.Errorf("missing %s claim", name)
 		}
-		role, ok := claims["role"].(string)
-		if !ok {
-			return "", "", fmt.Errorf("invalid role in token")
+	}
+	if !claims.VerifyIssuer(j.options.Issuer, true) {
+		return nil, fmt.Errorf("invalid issuer")
+	}
+	if !claims.VerifyAudience(j.options.Audience, true) {
+		return nil, fmt.Errorf("invalid audience")
+	}
+
+	userID, ok := claims["user_id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid user id in token")
+	}
+	role, ok := claims["role"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid role in token")
+	}
+
+	result := &domain.AccessClaims{UserID: userID, Role: role}
+	result.Username, _ = claims["username"].(string)
+	result.Email, _ = claims["email"].(string)
+	result.Status, _ = claims["status"].(string)
+	result.TokenID, _ = claims["jti"].(string)
+	if iat, ok := claims["iat"].(float64); ok {
+		result.IssuedAt = time.UnixMilli(int64(math.Round(iat * 1000)))
+	}
+	if exp, ok := claims["exp"].(float64); ok {
+		result.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	return result, nil
+}
+
+func (j *jWTService) PublicKeys() []*domain.JSONWebKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	keys := make([]*domain.JSONWebKey, 0, len(j.keys))
+	for _, key := range j.keys {
+		jwk := &domain.JSONWebKey{KeyID: key.id, Use: "sig", Algorithm: key.method.Alg()}
+		switch public := key.private.Public().(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		default:
+			continue
 		}
-		
-		return userID, role, nil
+		keys = append(keys, jwk)
+	}
+	return keys
+}
+
+func (j *jWTService) RotateKeys(ctx context.Context) error {
+	now := time.Now()
+	stored, err := j.repository.ListSigningKeys(ctx)
+	if err != nil {
+		return err
 	}
 
-	return "", "", fmt.Errorf("invalid token")
-}
\ No newline at end of file
+	if j.rotationDue(stored, now) {
+		next, err := j.newKey(stored, now)
+		if err != nil {
+			return err
+		}
+		switch err := j.repository.CreateSigningKey(ctx, next); err {
+		case nil:
+			stored = append(stored, next)
+			log.Printf("Added signing key %s, signing from %s", next.ID, next.ActivatesAt.Format(time.RFC3339))
+		case domain.ErrSigningKeyExists:
+			// another instance rotated first
+			if stored, err = j.repository.ListSigningKeys(ctx); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+
+	// a key is done once its successor signs and its last token expired
+	var retired []string
+	for len(stored) > 1 && now.After(keyRetiresAt(stored[1])) {
+		retired = append(retired, stored[0].ID)
+		stored = stored[1:]
+	}
+	if err := j.repository.DeleteSigningKeys(ctx, retired); err != nil {
+		return err
+	}
+
+	keys := make([]*signingKey, 0, len(stored))
+	for i, s := range stored {
+		key, err := j.openKey(s)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", s.ID, err)
+		}
+		if i+1 < len(stored) {
+			key.verifyUntil = keyRetiresAt(stored[i+1])
+		}
+		keys = append(keys, key)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+	return nil
+}
+
+// keyRetiresAt is when the key before successor stops verifying.
+func keyRetiresAt(successor *domain.SigningKey) time.Time {
+	return successor.ActivatesAt.Add(domain.AccessTokenTTL + keyClockSkew)
+}
+
+// rotationDue reports whether the newest key should get a successor: it is
+// old enough, or the configured algorithm changed. A successor that is
+// still waiting to sign is not rotated again.
+func (j *jWTService) rotationDue(stored []*domain.SigningKey, now time.Time) bool {
+	if len(stored) == 0 {
+		return true
+	}
+	newest := stored[len(stored)-1]
+	if newest.ActivatesAt.After(now) {
+		return false
+	}
+	if newest.Algorithm != j.options.Algorithm {
+		return true
+	}
+	return !now.Before(newest.ActivatesAt.Add(j.options.RotationInterval - keyPublishLead))
+}
+
+// newKey generates the successor of the stored keys. The very first key
+// signs right away since nobody can know an older one.
+func (j *jWTService) newKey(stored []*domain.SigningKey, now time.Time) (*domain.SigningKey, error) {
+	generation, activatesAt := 1, now
+	if len(stored) > 0 {
+		generation = stored[len(stored)-1].Generation + 1
+		activatesAt = now.Add(keyPublishLead)
+	}
+
+	var private crypto.Signer
+	var err error
+	switch j.options.Algorithm {
+	case domain.SigningEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case domain.SigningRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", j.options.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	kid := make([]byte, 12)
+	if _, err := rand.Read(kid); err != nil {
+		return nil, err
+	}
+	id := base64.RawURLEncoding.EncodeToString(kid)
+
+	nonce := make([]byte, j.sealer.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &domain.SigningKey{
+		ID:          id,
+		Algorithm:   j.options.Algorithm,
+		Generation:  generation,
+		PrivateKey:  j.sealer.Seal(nonce, nonce, der, []byte(id)),
+		CreatedAt:   now,
+		ActivatesAt: activatesAt,
+	}, nil
+}
+
+func (j *jWTService) openKey(stored *domain.SigningKey) (*signingKey, error) {
+	size := j.sealer.NonceSize()
+	if len(stored.PrivateKey) < size {
+		return nil, errors.New("sealed key is too short")
+	}
+	der, err := j.sealer.Open(nil, stored.PrivateKey[:size], stored.PrivateKey[size:], []byte(stored.ID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot unseal with the current JWT_SECRET: %w", err)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{id: stored.ID, activatesAt: stored.ActivatesAt}
+	switch private := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.method, key.private = jwt.SigningMethodEdDSA, private
+	case *rsa.PrivateKey:
+		key.method, key.private = jwt.SigningMethodRS256, private
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if key.method.Alg() != stored.Algorithm {
+		return nil, fmt.Errorf("key does not match algorithm %s", stored.Algorithm)
+	}
+	return key, nil
+}
+
+// signingKey returns the newest key that may sign at now.
+func (j *jWTService) signingKey(now time.Time) *signingKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	for i := len(j.keys) - 1; i >= 0; i-- {
+		if !j.keys[i].activatesAt.After(now) {
+			return j.keys[i]
+		}
+	}
+	return nil
+}
+
+func (j *jWTService) verificationKey(kid string, now time.Time) *signingKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	for _, key := range j.keys {
+		if key.id == kid {
+			if !key.verifyUntil.IsZero() && now.After(key.verifyUntil) {
+				return nil
+			}
+			return key
+		}
+	}
+	return nil
+}
@@ -2,14 +2,14 @@ package middleware
 
 import (
 	"blog-backend/domain"
-	"fmt"
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewAuthMiddleware(jwtService domain.IJWTService) gin.HandlerFunc {
+func NewAuthMiddleware(jwtService domain.IJWTService, denylist domain.ITokenDenylist) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -27,15 +27,21 @@ func NewAuthMiddleware(jwtService domain.IJWTService) gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		userID, role, err := jwtService.ParseToken(tokenString)
+		claims, err := jwtService.ParseToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "details": err.Error()})
 			c.Abort()
 			return
 		}
+		if isRevoked(c, denylist, claims) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
 		// i saved the user id coz i need it for some bunch of me related api's
-		c.Set("x-user-id", userID)
-		c.Set("x-user-role", role)
+		c.Set("x-user-id", claims.UserID)
+		c.Set("x-user-role", claims.Role)
+		c.Set("x-user-status", claims.Status)
 		c.Next()
 	}
 }
@@ -52,30 +58,52 @@ func NewAdminMiddleware() gin.HandlerFunc{
 	}
 }
 
-func NewOptionalAuthMiddleware(jwtService domain.IJWTService) gin.HandlerFunc {
+func NewOptionalAuthMiddleware(jwtService domain.IJWTService, denylist domain.ITokenDenylist) gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
             tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-            userID, role, err := jwtService.ParseToken(tokenString)
-            if err == nil {
-                c.Set("x-user-id", userID)
-                c.Set("x-user-role", role)
+            claims, err := jwtService.ParseToken(tokenString)
+            if err == nil && !isRevoked(c, denylist, claims) {
+                c.Set("x-user-id", claims.UserID)
+                c.Set("x-user-role", claims.Role)
             }
         }
         c.Next()
     }
 }
 
+// isRevoked checks the denylist. When it cannot be reached the token is
+// let through, so a Redis outage does not lock everyone out.
+func isRevoked(c *gin.Context, denylist domain.ITokenDenylist, claims *domain.AccessClaims) bool {
+	revoked, err := denylist.IsRevoked(c, claims)
+	if err != nil {
+		log.Printf("Failed to check access token revocation for user %s: %v", claims.UserID, err)
+		return false
+	}
+	return revoked
+}
+
+// NewStatusCheckMiddleware lets only active accounts through. The status
+// comes from the signed token; tokens issued before a status change are
+// revoked with it, so the claim cannot be stale.
 func NewStatusCheckMiddleware() gin.HandlerFunc{
 	return func(c *gin.Context){
-		status, err := c.Cookie("status")
-		if err != nil || status != string(domain.Active){
-			fmt.Println(status)
-			c.JSON(http.StatusForbidden, gin.H{"error":"Account must be activated"})
+		status := c.GetString("x-user-status")
+		if status != string(domain.Active){
+			message := "Account must be activated"
+			switch domain.Status(status) {
+			case domain.Suspended:
+				message = "Account is suspended"
+			case domain.Banned:
+				message = "Account is banned"
+			case "":
+				message = "Token carries no account status, refresh it"
+			}
+			c.JSON(http.StatusForbidden, gin.H{"error": message})
 			c.Abort()
 			return
 		}
 		c.Next()
 	}
-}
\ No newline at end of file
+}
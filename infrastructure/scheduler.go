@@ -0,0 +1,26 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunPeriodically calls job every interval until ctx is cancelled. Errors are
+// logged and the job keeps running on the next tick.
+func RunPeriodically(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopping background job %s", name)
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				log.Printf("Background job %s failed: %v", name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,423 @@
+package infrastructure
+
+import (
+	"blog-backend/domain"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/gob"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BM25 parameters and per-field boosts, in line with the weights of the
+// MongoDB text index.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	titleBoost   = 3
+	tagsBoost    = 2
+	contentBoost = 1
+
+	searchDefaultLimit = 10
+	searchMaxLimit     = 100
+	searchIndexVersion = 1
+)
+
+// indexedDoc holds what is needed to filter a document and score it: its
+// boosted term frequencies and length.
+type indexedDoc struct {
+	AuthorID  string
+	Tags      []string
+	CreatedAt time.Time
+	Length    float64
+	Terms     map[string]float64
+}
+
+// searchIndexFile is the on-disk form of the index. Postings are derived
+// from the documents on load, which keeps the file small.
+type searchIndexFile struct {
+	Version int
+	Docs    map[string]*indexedDoc
+}
+
+// InvertedIndex is an in-process search index using BM25 ranking. It lives in
+// memory and is written to disk by Flush.
+//
+// Every process keeps its own copy: an API instance only indexes the writes
+// it handles itself. With several instances behind a load balancer their
+// indexes drift apart until the next reindex, so run search on one instance
+// or reindex regularly.
+type InvertedIndex struct {
+	mu          sync.RWMutex
+	path        string
+	docs        map[string]*indexedDoc
+	postings    map[string]map[string]float64 // term -> doc id -> boosted tf
+	totalLength float64
+	dirty       bool
+
+	// flushMu serialises flushes through the rename, so an older snapshot
+	// never lands on disk after a newer one.
+	flushMu sync.Mutex
+	// disk is the file as this index last read or wrote it; a different
+	// file means another process, such as cmd/reindex, replaced it.
+	disk      fileState
+	trackDisk bool
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewSearchIndex loads the index persisted at path. A missing file gives an
+// empty index that will be created on the first flush.
+func NewSearchIndex(path string) (domain.ISearchIndex, error) {
+	idx := newInvertedIndex(path)
+	idx.trackDisk = true
+	if _, err := idx.load(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// load replaces the index with the file at path, reporting whether there
+// was one. Callers other than the constructor hold flushMu.
+func (idx *InvertedIndex) load() (bool, error) {
+	info, err := os.Stat(idx.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	raw, err := os.ReadFile(idx.path)
+	if err != nil {
+		return false, err
+	}
+
+	var file searchIndexFile
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&file); err != nil {
+		return false, err
+	}
+	idx.disk = fileState{modTime: info.ModTime(), size: info.Size()}
+	if file.Version != searchIndexVersion {
+		// an index written by another version is useless; start over and
+		// let a reindex fill it again
+		return true, nil
+	}
+
+	idx.mu.Lock()
+	idx.reset(file.Docs)
+	idx.dirty = false
+	idx.mu.Unlock()
+	return true, nil
+}
+
+// NewEmptySearchIndex starts a blank index that overwrites whatever is stored
+// at path on its first flush.
+func NewEmptySearchIndex(path string) domain.ISearchIndex {
+	return newInvertedIndex(path)
+}
+
+func newInvertedIndex(path string) *InvertedIndex {
+	idx := &InvertedIndex{path: path}
+	idx.reset(map[string]*indexedDoc{})
+	return idx
+}
+
+func (idx *InvertedIndex) Index(ctx context.Context, doc *domain.SearchDocument) error {
+	entry := newIndexedDoc(doc)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(doc.ID)
+	idx.add(doc.ID, entry)
+	idx.dirty = true
+	return nil
+}
+
+func (idx *InvertedIndex) Remove(ctx context.Context, id string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.remove(id) {
+		idx.dirty = true
+	}
+	return nil
+}
+
+func (idx *InvertedIndex) Rebuild(ctx context.Context, docs []*domain.SearchDocument) error {
+	entries := make(map[string]*indexedDoc, len(docs))
+	for _, doc := range docs {
+		entries[doc.ID] = newIndexedDoc(doc)
+	}
+
+	idx.mu.Lock()
+	idx.reset(entries)
+	idx.dirty = true
+	idx.mu.Unlock()
+
+	return idx.Flush(ctx)
+}
+
+func (idx *InvertedIndex) Search(ctx context.Context, query domain.SearchQuery) ([]domain.SearchHit, string, error) {
+	offset, err := decodeSearchOffset(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = searchDefaultLimit
+	}
+	if limit > searchMaxLimit {
+		limit = searchMaxLimit
+	}
+
+	// Like MongoDB's $text, a document matches if it has any of the terms,
+	// and a leading '-' excludes documents containing that word.
+	var include, exclude []string
+	for _, word := range strings.Fields(query.Text) {
+		if strings.HasPrefix(word, "-") {
+			exclude = append(exclude, analyzeText(word[1:])...)
+		} else {
+			include = append(include, analyzeText(word)...)
+		}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := map[string]float64{}
+	n := float64(len(idx.docs))
+	avgLength := 1.0
+	if n > 0 && idx.totalLength > 0 {
+		avgLength = idx.totalLength / n
+	}
+	seen := map[string]bool{}
+	for _, term := range include {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		postings := idx.postings[term]
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range postings {
+			doc := idx.docs[id]
+			if !matchesFilters(doc, query) {
+				continue
+			}
+			norm := tf + bm25K1*(1-bm25B+bm25B*doc.Length/avgLength)
+			scores[id] += idf * tf * (bm25K1 + 1) / norm
+		}
+	}
+	for _, term := range exclude {
+		for id := range idx.postings[term] {
+			delete(scores, id)
+		}
+	}
+
+	hits := make([]domain.SearchHit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, domain.SearchHit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		ci, cj := idx.docs[hits[i].ID].CreatedAt, idx.docs[hits[j].ID].CreatedAt
+		if !ci.Equal(cj) {
+			return ci.After(cj)
+		}
+		return hits[i].ID > hits[j].ID
+	})
+
+	if offset >= len(hits) {
+		return []domain.SearchHit{}, "", nil
+	}
+	hits = hits[offset:]
+	next := ""
+	if len(hits) > limit {
+		hits = hits[:limit]
+		next = encodeSearchOffset(offset + limit)
+	}
+	return hits, next, nil
+}
+
+// Flush writes the index to disk if it changed since the last flush. The file
+// is replaced atomically so a crash never leaves a half-written index. When
+// another process replaced the file, it is loaded instead of overwritten.
+func (idx *InvertedIndex) Flush(ctx context.Context) error {
+	idx.flushMu.Lock()
+	defer idx.flushMu.Unlock()
+
+	if idx.trackDisk {
+		if info, err := os.Stat(idx.path); err == nil && (fileState{modTime: info.ModTime(), size: info.Size()}) != idx.disk {
+			_, err := idx.load()
+			return err
+		}
+	}
+
+	idx.mu.Lock()
+	if !idx.dirty {
+		idx.mu.Unlock()
+		return nil
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(searchIndexFile{Version: searchIndexVersion, Docs: idx.docs})
+	if err == nil {
+		idx.dirty = false
+	}
+	idx.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(idx.path, buf.Bytes()); err != nil {
+		idx.mu.Lock()
+		idx.dirty = true
+		idx.mu.Unlock()
+		return err
+	}
+	if info, err := os.Stat(idx.path); err == nil {
+		idx.disk = fileState{modTime: info.ModTime(), size: info.Size()}
+		idx.trackDisk = true
+	}
+	return nil
+}
+
+func newIndexedDoc(doc *domain.SearchDocument) *indexedDoc {
+	entry := &indexedDoc{
+		AuthorID:  doc.AuthorID,
+		Tags:      doc.Tags,
+		CreatedAt: doc.CreatedAt,
+		Terms:     map[string]float64{},
+	}
+	addTerms := func(text string, boost float64) {
+		for _, term := range analyzeText(text) {
+			entry.Terms[term] += boost
+			entry.Length += boost
+		}
+	}
+	addTerms(doc.Title, titleBoost)
+	addTerms(strings.Join(doc.Tags, " "), tagsBoost)
+	addTerms(doc.Content, contentBoost)
+	return entry
+}
+
+// reset replaces the documents and rebuilds the postings from them. Callers
+// must hold the write lock, except while constructing the index.
+func (idx *InvertedIndex) reset(docs map[string]*indexedDoc) {
+	idx.docs = map[string]*indexedDoc{}
+	idx.postings = map[string]map[string]float64{}
+	idx.totalLength = 0
+	for id, doc := range docs {
+		idx.add(id, doc)
+	}
+}
+
+func (idx *InvertedIndex) add(id string, doc *indexedDoc) {
+	idx.docs[id] = doc
+	idx.totalLength += doc.Length
+	for term, tf := range doc.Terms {
+		postings, ok := idx.postings[term]
+		if !ok {
+			postings = map[string]float64{}
+			idx.postings[term] = postings
+		}
+		postings[id] = tf
+	}
+}
+
+func (idx *InvertedIndex) remove(id string) bool {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return false
+	}
+	for term := range doc.Terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLength -= doc.Length
+	delete(idx.docs, id)
+	return true
+}
+
+func matchesFilters(doc *indexedDoc, query domain.SearchQuery) bool {
+	if query.AuthorID != "" && doc.AuthorID != query.AuthorID {
+		return false
+	}
+	if !query.From.IsZero() && doc.CreatedAt.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && doc.CreatedAt.After(query.To) {
+		return false
+	}
+	for _, want := range query.Tags {
+		found := false
+		for _, tag := range doc.Tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func encodeSearchOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeSearchOffset(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, domain.ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, domain.ErrInvalidCursor
+	}
+	return offset, nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
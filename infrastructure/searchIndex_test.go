@@ -0,0 +1,199 @@
+package infrastructure
+
+import (
+	"blog-backend/domain"
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var searchEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func searchDocs() []*domain.SearchDocument {
+	return []*domain.SearchDocument{
+		{ID: "title", AuthorID: "ann", Title: "Gardening basics", Content: "Soil and seeds.", Tags: []string{"garden"}, CreatedAt: searchEpoch},
+		{ID: "content", AuthorID: "bob", Title: "Weekend notes", Content: "Some gardening, some cooking.", Tags: []string{"life"}, CreatedAt: searchEpoch.Add(time.Hour)},
+		{ID: "pests", AuthorID: "ann", Title: "Gardening without pests", Content: "Keeping slugs away.", Tags: []string{"garden", "pests"}, CreatedAt: searchEpoch.Add(2 * time.Hour)},
+		{ID: "cooking", AuthorID: "bob", Title: "Cooking for one", Content: "Quick dinners.", Tags: []string{"food"}, CreatedAt: searchEpoch.Add(3 * time.Hour)},
+	}
+}
+
+func newTestSearchIndex(t *testing.T, path string) domain.ISearchIndex {
+	t.Helper()
+	idx := NewEmptySearchIndex(path)
+	if err := idx.Rebuild(context.Background(), searchDocs()); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	return idx
+}
+
+func hitIDs(hits []domain.SearchHit) []string {
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	return ids
+}
+
+func TestSearchRanking(t *testing.T) {
+	idx := newTestSearchIndex(t, filepath.Join(t.TempDir(), "search.idx"))
+
+	// "gardeners" stems to the same term as "gardening"; a title match
+	// outranks a content match, and the shorter title wins between two
+	hits, next, err := idx.Search(context.Background(), domain.SearchQuery{Text: "gardeners"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if want := []string{"title", "pests", "content"}; !reflect.DeepEqual(hitIDs(hits), want) {
+		t.Errorf("hits = %v, want %v", hitIDs(hits), want)
+	}
+	if next != "" {
+		t.Errorf("next = %q, want none", next)
+	}
+	for i := 1; i < len(hits); i++ {
+		if hits[i].Score > hits[i-1].Score {
+			t.Errorf("hit %d scores %v above hit %d at %v", i, hits[i].Score, i-1, hits[i-1].Score)
+		}
+	}
+}
+
+func TestSearchExclusion(t *testing.T) {
+	idx := newTestSearchIndex(t, filepath.Join(t.TempDir(), "search.idx"))
+
+	hits, _, err := idx.Search(context.Background(), domain.SearchQuery{Text: "gardening -slugs -Cooking"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if want := []string{"title"}; !reflect.DeepEqual(hitIDs(hits), want) {
+		t.Errorf("hits = %v, want %v", hitIDs(hits), want)
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	idx := newTestSearchIndex(t, filepath.Join(t.TempDir(), "search.idx"))
+
+	tests := []struct {
+		name  string
+		query domain.SearchQuery
+		want  []string
+	}{
+		{"author", domain.SearchQuery{Text: "gardening", AuthorID: "bob"}, []string{"content"}},
+		{"tags", domain.SearchQuery{Text: "gardening", Tags: []string{"garden", "pests"}}, []string{"pests"}},
+		{"from", domain.SearchQuery{Text: "gardening", From: searchEpoch.Add(time.Hour)}, []string{"pests", "content"}},
+		{"to", domain.SearchQuery{Text: "gardening", To: searchEpoch.Add(time.Hour)}, []string{"title", "content"}},
+		{"no match", domain.SearchQuery{Text: "gardening", AuthorID: "carol"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, _, err := idx.Search(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if !reflect.DeepEqual(hitIDs(hits), tt.want) {
+				t.Errorf("hits = %v, want %v", hitIDs(hits), tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchPaging(t *testing.T) {
+	idx := newTestSearchIndex(t, filepath.Join(t.TempDir(), "search.idx"))
+
+	var got []string
+	query := domain.SearchQuery{Text: "gardening", Limit: 2}
+	for page := 0; ; page++ {
+		if page > 2 {
+			t.Fatal("paging did not stop")
+		}
+		hits, next, err := idx.Search(context.Background(), query)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		got = append(got, hitIDs(hits)...)
+		if next == "" {
+			break
+		}
+		query.Cursor = next
+	}
+	if want := []string{"title", "pests", "content"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+
+	hits, next, err := idx.Search(context.Background(), domain.SearchQuery{Text: "gardening", Cursor: encodeSearchOffset(10)})
+	if err != nil || len(hits) != 0 || next != "" {
+		t.Errorf("past the end = %v, %q, %v; want no hits", hitIDs(hits), next, err)
+	}
+	for _, cursor := range []string{"!", encodeSearchOffset(-1)} {
+		if _, _, err := idx.Search(context.Background(), domain.SearchQuery{Text: "gardening", Cursor: cursor}); err != domain.ErrInvalidCursor {
+			t.Errorf("cursor %q: err = %v, want %v", cursor, err, domain.ErrInvalidCursor)
+		}
+	}
+}
+
+func TestSearchIndexFlushRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "search", "search.idx")
+	idx := newTestSearchIndex(t, path)
+	if err := idx.Remove(ctx, "cooking"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := idx.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	loaded, err := NewSearchIndex(path)
+	if err != nil {
+		t.Fatalf("NewSearchIndex: %v", err)
+	}
+	query := domain.SearchQuery{Text: "gardening cooking"}
+	want, _, _ := idx.Search(ctx, query)
+	got, _, err := loaded.Search(ctx, query)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reloaded hits = %v, want %v", got, want)
+	}
+
+	// a reindex run elsewhere replaces the file; the running index picks it
+	// up on its next flush instead of writing its own copy over it
+	reindexed := NewEmptySearchIndex(path)
+	replacement := []*domain.SearchDocument{{ID: "new", Title: "Beekeeping", CreatedAt: searchEpoch}}
+	if err := reindexed.Rebuild(ctx, replacement); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if err := loaded.Index(ctx, &domain.SearchDocument{ID: "stale", Title: "Gardening again", CreatedAt: searchEpoch}); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if err := loaded.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	assertSearchIDs(t, "running", loaded, "gardening beekeeping", []string{"new"})
+
+	reopened, err := NewSearchIndex(path)
+	if err != nil {
+		t.Fatalf("NewSearchIndex: %v", err)
+	}
+	assertSearchIDs(t, "reopened", reopened, "gardening beekeeping", []string{"new"})
+}
+
+func TestNewSearchIndexMissingFile(t *testing.T) {
+	idx, err := NewSearchIndex(filepath.Join(t.TempDir(), "missing.idx"))
+	if err != nil {
+		t.Fatalf("NewSearchIndex: %v", err)
+	}
+	assertSearchIDs(t, "empty", idx, "gardening", []string{})
+}
+
+func assertSearchIDs(t *testing.T, name string, idx domain.ISearchIndex, text string, want []string) {
+	t.Helper()
+	hits, _, err := idx.Search(context.Background(), domain.SearchQuery{Text: text})
+	if err != nil {
+		t.Fatalf("%s: Search: %v", name, err)
+	}
+	if !reflect.DeepEqual(hitIDs(hits), want) {
+		t.Errorf("%s: hits = %v, want %v", name, hitIDs(hits), want)
+	}
+}
@@ -0,0 +1,239 @@
+package infrastructure
+
+import (
+	"strings"
+	"unicode"
+)
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "from": true, "has": true, "have": true, "he": true,
+	"her": true, "his": true, "i": true, "if": true, "in": true, "into": true, "is": true,
+	"it": true, "its": true, "of": true, "on": true, "or": true, "our": true, "she": true,
+	"so": true, "that": true, "the": true, "their": true, "them": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "to": true, "was": true,
+	"we": true, "were": true, "what": true, "when": true, "which": true, "who": true,
+	"will": true, "with": true, "you": true, "your": true,
+}
+
+// analyzeText splits text into lower-cased, stemmed terms with stop words
+// removed. Documents and queries go through the same analysis so that e.g.
+// "Running" in a query matches "runs" in a post.
+func analyzeText(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if stopWords[word] {
+			continue
+		}
+		terms = append(terms, stem(word))
+	}
+	return terms
+}
+
+// stem reduces an English word to its stem using the Porter algorithm. Words
+// that are short or not plain ASCII letters are returned unchanged.
+func stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	w := step1a(word)
+	w = step1b(w)
+	w = step1c(w)
+	w = replaceSuffix(w, step2Suffixes, 0)
+	w = replaceSuffix(w, step3Suffixes, 0)
+	w = step4(w)
+	w = step5(w)
+	return w
+}
+
+func isConsonant(w string, i int) bool {
+	switch w[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !isConsonant(w, i-1)
+	}
+	return true
+}
+
+// measure counts the vowel-consonant sequences in w, the m in [C](VC)^m[V].
+func measure(w string) int {
+	m := 0
+	prevVowel := false
+	for i := 0; i < len(w); i++ {
+		c := isConsonant(w, i)
+		if c && prevVowel {
+			m++
+		}
+		prevVowel = !c
+	}
+	return m
+}
+
+func hasVowel(w string) bool {
+	for i := 0; i < len(w); i++ {
+		if !isConsonant(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+func endsDoubleConsonant(w string) bool {
+	n := len(w)
+	return n >= 2 && w[n-1] == w[n-2] && isConsonant(w, n-1)
+}
+
+// endsCVC reports whether w ends consonant-vowel-consonant where the last
+// consonant is not w, x or y, as in "hop" but not "snow".
+func endsCVC(w string) bool {
+	n := len(w)
+	if n < 3 || !isConsonant(w, n-3) || isConsonant(w, n-2) || !isConsonant(w, n-1) {
+		return false
+	}
+	switch w[n-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+func step1a(w string) string {
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ies"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"):
+		return w
+	case strings.HasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func step1b(w string) string {
+	if strings.HasSuffix(w, "eed") {
+		if measure(w[:len(w)-3]) > 0 {
+			return w[:len(w)-1]
+		}
+		return w
+	}
+
+	var s string
+	switch {
+	case strings.HasSuffix(w, "ed") && hasVowel(w[:len(w)-2]):
+		s = w[:len(w)-2]
+	case strings.HasSuffix(w, "ing") && hasVowel(w[:len(w)-3]):
+		s = w[:len(w)-3]
+	default:
+		return w
+	}
+
+	switch {
+	case strings.HasSuffix(s, "at"), strings.HasSuffix(s, "bl"), strings.HasSuffix(s, "iz"):
+		return s + "e"
+	case endsDoubleConsonant(s):
+		switch s[len(s)-1] {
+		case 'l', 's', 'z':
+			return s
+		}
+		return s[:len(s)-1]
+	case measure(s) == 1 && endsCVC(s):
+		return s + "e"
+	}
+	return s
+}
+
+func step1c(w string) string {
+	if strings.HasSuffix(w, "y") && hasVowel(w[:len(w)-1]) {
+		return w[:len(w)-1] + "i"
+	}
+	return w
+}
+
+type suffixRule struct {
+	suffix      string
+	replacement string
+}
+
+// Longer suffixes come before the shorter ones they end with, since only the
+// longest matching rule is considered.
+var step2Suffixes = []suffixRule{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"},
+	{"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"},
+	{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"},
+	{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}, {"logi", "log"},
+}
+
+var step3Suffixes = []suffixRule{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+var step4Suffixes = []string{
+	"ement", "ment", "ance", "ence", "able", "ible", "ant", "ent", "ism", "ate",
+	"iti", "ous", "ive", "ize", "ion", "al", "er", "ic", "ou",
+}
+
+// replaceSuffix applies the longest rule whose suffix w ends with, provided
+// the remaining stem measures more than minMeasure.
+func replaceSuffix(w string, rules []suffixRule, minMeasure int) string {
+	best := -1
+	for i, rule := range rules {
+		if strings.HasSuffix(w, rule.suffix) && (best < 0 || len(rule.suffix) > len(rules[best].suffix)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return w
+	}
+	s := w[:len(w)-len(rules[best].suffix)]
+	if measure(s) > minMeasure {
+		return s + rules[best].replacement
+	}
+	return w
+}
+
+func step4(w string) string {
+	best := ""
+	for _, suffix := range step4Suffixes {
+		if strings.HasSuffix(w, suffix) && len(suffix) > len(best) {
+			best = suffix
+		}
+	}
+	if best == "" {
+		return w
+	}
+	s := w[:len(w)-len(best)]
+	if measure(s) <= 1 {
+		return w
+	}
+	if best == "ion" && !strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "t") {
+		return w
+	}
+	return s
+}
+
+func step5(w string) string {
+	if strings.HasSuffix(w, "e") {
+		s := w[:len(w)-1]
+		if m := measure(s); m > 1 || (m == 1 && !endsCVC(s)) {
+			w = s
+		}
+	}
+	if strings.HasSuffix(w, "ll") && measure(w) > 1 {
+		w = w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"blog-backend/domain"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTokenDenylist keeps a key per denied jti and a "valid after" time per
+// user. Both only have to outlive the tokens they reject, so they expire
+// with them.
+type redisTokenDenylist struct {
+	client *redis.Client
+}
+
+func NewRedisTokenDenylist(client *redis.Client) domain.ITokenDenylist {
+	return &redisTokenDenylist{client: client}
+}
+
+func deniedTokenKey(tokenID string) string {
+	return "auth:denied:" + tokenID
+}
+
+func tokensValidAfterKey(userID string) string {
+	return "auth:valid-after:" + userID
+}
+
+func (d *redisTokenDenylist) Deny(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if tokenID == "" || ttl <= 0 {
+		return nil
+	}
+	return d.client.Set(ctx, deniedTokenKey(tokenID), 1, ttl).Err()
+}
+
+func (d *redisTokenDenylist) SetTokensValidAfter(ctx context.Context, userID string, at time.Time) error {
+	// a token issued before at is expired by the time the key is
+	return d.client.Set(ctx, tokensValidAfterKey(userID), at.UnixMilli(), domain.AccessTokenTTL+time.Minute).Err()
+}
+
+func (d *redisTokenDenylist) IsRevoked(ctx context.Context, claims *domain.AccessClaims) (bool, error) {
+	pipe := d.client.Pipeline()
+	var denied *redis.IntCmd
+	if claims.TokenID != "" {
+		denied = pipe.Exists(ctx, deniedTokenKey(claims.TokenID))
+	}
+	validAfter := pipe.Get(ctx, tokensValidAfterKey(claims.UserID))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return false, err
+	}
+
+	if denied != nil && denied.Val() > 0 {
+		return true, nil
+	}
+	raw, err := validAfter.Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	after, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return claims.IssuedAt.UnixMilli() < after, nil
+}
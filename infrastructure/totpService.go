@@ -0,0 +1,151 @@
+package infrastructure
+
+import (
+	"blog-backend/domain"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// TOTP parameters every authenticator app supports (RFC 6238 defaults).
+const (
+	totpPeriod     = 30
+	totpDigits     = 6
+	totpSecretSize = 20
+	totpDrift      = 1 // steps accepted either side of now
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type totpService struct {
+	issuer string
+	sealer cipher.AEAD
+	// legacy opens secrets sealed before the current key was configured.
+	legacy cipher.AEAD
+}
+
+// NewTOTPService names accounts after issuer in authenticator apps and seals
+// secrets with a key derived from secret. Secrets sealed with a key derived
+// from legacySecret can still be opened and resealed; an empty legacySecret,
+// or one equal to secret, means there are none.
+func NewTOTPService(issuer, secret, legacySecret string) (domain.ITOTPService, error) {
+	sealer, err := newTOTPSealer(secret)
+	if err != nil {
+		return nil, err
+	}
+	ts := &totpService{issuer: issuer, sealer: sealer}
+	if legacySecret != "" && legacySecret != secret {
+		if ts.legacy, err = newTOTPSealer(legacySecret); err != nil {
+			return nil, err
+		}
+	}
+	return ts, nil
+}
+
+func newTOTPSealer(secret string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("totp:" + secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (ts *totpService) NewSecret(accountName string) ([]byte, *domain.TOTPEnrolment, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, ts.sealer.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	sealed := ts.sealer.Seal(nonce, nonce, secret, nil)
+
+	encoded := totpEncoding.EncodeToString(secret)
+	query := url.Values{}
+	query.Set("secret", encoded)
+	query.Set("issuer", ts.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + ts.issuer + ":" + accountName,
+		RawQuery: query.Encode(),
+	}
+
+	return sealed, &domain.TOTPEnrolment{Secret: encoded, ProvisioningURI: uri.String()}, nil
+}
+
+func (ts *totpService) Verify(sealed []byte, code string, now time.Time) (int64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	secret, _, ok := ts.open(sealed)
+	if !ok {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	// latest step first, so the step recorded as used is the newest match
+	for step := current + totpDrift; step >= current-totpDrift; step-- {
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func (ts *totpService) Reseal(sealed []byte) ([]byte, bool) {
+	secret, legacy, ok := ts.open(sealed)
+	if !ok || !legacy {
+		return nil, false
+	}
+	nonce := make([]byte, ts.sealer.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, false
+	}
+	return ts.sealer.Seal(nonce, nonce, secret, nil), true
+}
+
+// open unseals with the current key, then the legacy one, reporting whether
+// it took the legacy key.
+func (ts *totpService) open(sealed []byte) ([]byte, bool, bool) {
+	for i, sealer := range []cipher.AEAD{ts.sealer, ts.legacy} {
+		if sealer == nil {
+			continue
+		}
+		size := sealer.NonceSize()
+		if len(sealed) < size {
+			return nil, false, false
+		}
+		if secret, err := sealer.Open(nil, sealed[:size], sealed[size:], nil); err == nil {
+			return secret, i == 1, true
+		}
+	}
+	return nil, false, false
+}
+
+// totpCode is the HOTP value of secret at counter step (RFC 4226).
+func totpCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
@@ -0,0 +1,119 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed from RFC 6238 Appendix B.
+var rfc6238Secret = []byte("12345678901234567890")
+
+func sealTOTPSecret(t *testing.T, key string, secret []byte) []byte {
+	t.Helper()
+	sealer, err := newTOTPSealer(key)
+	if err != nil {
+		t.Fatalf("newTOTPSealer: %v", err)
+	}
+	nonce := make([]byte, sealer.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	return sealer.Seal(nonce, nonce, secret, nil)
+}
+
+func newTestTOTPService(t *testing.T, secret, legacySecret string) *totpService {
+	t.Helper()
+	ts, err := NewTOTPService("Blog", secret, legacySecret)
+	if err != nil {
+		t.Fatalf("NewTOTPService: %v", err)
+	}
+	return ts.(*totpService)
+}
+
+// The RFC 6238 Appendix B SHA-1 values, truncated to six digits.
+func TestTOTPReferenceVectors(t *testing.T) {
+	ts := newTestTOTPService(t, "key", "")
+	sealed := sealTOTPSecret(t, "key", rfc6238Secret)
+
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		step := tt.unix / totpPeriod
+		if got := totpCode(rfc6238Secret, step); got != tt.code {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, tt.code)
+		}
+		got, ok := ts.Verify(sealed, tt.code, time.Unix(tt.unix, 0))
+		if !ok || got != step {
+			t.Errorf("Verify(T=%d) = %d, %v; want %d, true", tt.unix, got, ok, step)
+		}
+	}
+}
+
+func TestTOTPVerifyDrift(t *testing.T) {
+	ts := newTestTOTPService(t, "key", "")
+	sealed := sealTOTPSecret(t, "key", rfc6238Secret)
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / totpPeriod
+
+	for offset := int64(-totpDrift - 1); offset <= totpDrift+1; offset++ {
+		step := current + offset
+		got, ok := ts.Verify(sealed, totpCode(rfc6238Secret, step), now)
+		inWindow := offset >= -totpDrift && offset <= totpDrift
+		if ok != inWindow {
+			t.Errorf("step %+d: ok = %v, want %v", offset, ok, inWindow)
+		}
+		if inWindow && got != step {
+			t.Errorf("step %+d: matched step %d, want %d", offset, got, step)
+		}
+	}
+
+	for _, code := range []string{"", "28708", "2870820", "abcdef"} {
+		if _, ok := ts.Verify(sealed, code, now); ok {
+			t.Errorf("Verify(%q) accepted", code)
+		}
+	}
+	if _, ok := ts.Verify(sealed[:4], totpCode(rfc6238Secret, current), now); ok {
+		t.Error("Verify accepted a truncated secret")
+	}
+}
+
+func TestTOTPLegacySecret(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code := "050471"
+	legacySealed := sealTOTPSecret(t, "old-key", rfc6238Secret)
+
+	if _, ok := newTestTOTPService(t, "new-key", "").Verify(legacySealed, code, now); ok {
+		t.Fatal("opened a legacy secret without the legacy key")
+	}
+
+	ts := newTestTOTPService(t, "new-key", "old-key")
+	if _, ok := ts.Verify(legacySealed, code, now); !ok {
+		t.Fatal("Verify rejected a secret sealed with the legacy key")
+	}
+	resealed, ok := ts.Reseal(legacySealed)
+	if !ok {
+		t.Fatal("Reseal did not reseal a legacy secret")
+	}
+
+	// once resealed the legacy key is no longer needed
+	current := newTestTOTPService(t, "new-key", "")
+	if _, ok := current.Verify(resealed, code, now); !ok {
+		t.Error("Verify rejected the resealed secret")
+	}
+	if _, ok := ts.Reseal(resealed); ok {
+		t.Error("Reseal resealed a secret already under the current key")
+	}
+	if _, ok := ts.Reseal(sealTOTPSecret(t, "other-key", rfc6238Secret)); ok {
+		t.Error("Reseal accepted a secret sealed with an unknown key")
+	}
+}
@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"blog-backend/domain"
+	"context"
+	"log"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type mongoTransactionManager struct {
+	client *mongo.Client
+
+	mu        sync.Mutex
+	detected  bool
+	supported bool
+}
+
+// NewMongoTransactionManager runs work in a Mongo transaction on replica sets
+// and sharded clusters. A standalone server has no transactions, so there the
+// work runs as plain writes.
+func NewMongoTransactionManager(client *mongo.Client) domain.ITransactionManager {
+	return &mongoTransactionManager{client: client}
+}
+
+func (tm *mongoTransactionManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !tm.transactionsSupported(ctx) {
+		return fn(ctx)
+	}
+
+	session, err := tm.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, fn(ctx)
+	})
+	return err
+}
+
+// transactionsSupported asks the server what kind of deployment it is. The
+// answer is kept once the server has replied; a failed probe is retried on
+// the next call.
+func (tm *mongoTransactionManager) transactionsSupported(ctx context.Context) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if tm.detected {
+		return tm.supported
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := tm.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		log.Printf("Could not detect transaction support, running without a transaction: %v", err)
+		return false
+	}
+	tm.detected = true
+	tm.supported = hello.SetName != "" || hello.Msg == "isdbgrid"
+	if !tm.supported {
+		log.Println("MongoDB is a standalone server; cascades run without transactions")
+	}
+	return tm.supported
+}
@@ -0,0 +1,134 @@
+package infrastructure
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// trendingWindow sets how long activity counts towards a window and how
+// quickly its weight halves.
+type trendingWindow struct {
+	span     time.Duration
+	halfLife time.Duration
+}
+
+var trendingWindows = map[domain.TrendingWindow]trendingWindow{
+	domain.TrendingDay:   {span: 24 * time.Hour, halfLife: 6 * time.Hour},
+	domain.TrendingWeek:  {span: 7 * 24 * time.Hour, halfLife: 36 * time.Hour},
+	domain.TrendingMonth: {span: 30 * 24 * time.Hour, halfLife: 6 * 24 * time.Hour},
+}
+
+// Rather than decaying every score, a bump adds its weight scaled up by
+// 2^(age of the window's epoch / half life), which ranks the same. Maintain
+// moves the epoch forward and scales the scores down before they grow too
+// large.
+var bumpTrending = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local halfLife = tonumber(ARGV[2])
+local epoch = tonumber(redis.call("GET", KEYS[3]))
+if not epoch then
+	epoch = now
+	redis.call("SET", KEYS[3], now)
+end
+local scale = math.pow(2, (now - epoch) / halfLife)
+for i = 3, #ARGV, 2 do
+	redis.call("ZINCRBY", KEYS[1], string.format("%.17g", tonumber(ARGV[i + 1]) * scale), ARGV[i])
+	redis.call("ZADD", KEYS[2], now, ARGV[i])
+end
+return 0`)
+
+var maintainTrending = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local halfLife = tonumber(ARGV[2])
+local idle = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", "(" .. ARGV[3])
+for i = 1, #idle, 500 do
+	local chunk = {unpack(idle, i, math.min(i + 499, #idle))}
+	redis.call("ZREM", KEYS[1], unpack(chunk))
+	redis.call("ZREM", KEYS[2], unpack(chunk))
+end
+local epoch = tonumber(redis.call("GET", KEYS[3]))
+if epoch then
+	local halves = math.floor((now - epoch) / halfLife)
+	if halves >= tonumber(ARGV[4]) then
+		local factor = math.pow(2, -halves)
+		local members = redis.call("ZRANGE", KEYS[1], 0, -1, "WITHSCORES")
+		for i = 1, #members, 2 do
+			redis.call("ZADD", KEYS[1], string.format("%.17g", tonumber(members[i + 1]) * factor), members[i])
+		end
+		redis.call("SET", KEYS[3], epoch + halves * halfLife)
+	end
+end
+return #idle`)
+
+// rebaseAfter is how many half lives pass before the scores are scaled
+// down; 2^32 leaves plenty of float precision.
+const rebaseAfter = 32
+
+type redisTrendingIndex struct {
+	client *redis.Client
+}
+
+func NewRedisTrendingIndex(client *redis.Client) domain.ITrendingIndex {
+	return &redisTrendingIndex{client: client}
+}
+
+func trendingKeys(window domain.TrendingWindow) []string {
+	base := "trending:" + string(window)
+	return []string{base, base + ":active", base + ":epoch"}
+}
+
+func (ti *redisTrendingIndex) Bump(ctx context.Context, weights map[string]float64, at time.Time) error {
+	if len(weights) == 0 {
+		return nil
+	}
+	for window, settings := range trendingWindows {
+		args := make([]interface{}, 0, 2+2*len(weights))
+		args = append(args, at.Unix(), int64(settings.halfLife.Seconds()))
+		for blogID, weight := range weights {
+			args = append(args, blogID, weight)
+		}
+		if err := bumpTrending.Run(ctx, ti.client, trendingKeys(window), args...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ti *redisTrendingIndex) Remove(ctx context.Context, blogIDs ...string) error {
+	if len(blogIDs) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(blogIDs))
+	for i, id := range blogIDs {
+		members[i] = id
+	}
+	pipe := ti.client.TxPipeline()
+	for window := range trendingWindows {
+		keys := trendingKeys(window)
+		pipe.ZRem(ctx, keys[0], members...)
+		pipe.ZRem(ctx, keys[1], members...)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (ti *redisTrendingIndex) Top(ctx context.Context, window domain.TrendingWindow, offset, count int) ([]string, error) {
+	if _, ok := trendingWindows[window]; !ok {
+		return nil, domain.ErrInvalidTrendingWindow
+	}
+	return ti.client.ZRevRange(ctx, trendingKeys(window)[0], int64(offset), int64(offset+count-1)).Result()
+}
+
+func (ti *redisTrendingIndex) Maintain(ctx context.Context, now time.Time) error {
+	for window, settings := range trendingWindows {
+		cutoff := now.Add(-settings.span).Unix()
+		args := []interface{}{now.Unix(), int64(settings.halfLife.Seconds()), cutoff, rebaseAfter}
+		if err := maintainTrending.Run(ctx, ti.client, trendingKeys(window), args...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"blog-backend/domain"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTwoFactorStore keeps login challenges under a hash of the challenge,
+// so a dump of Redis cannot complete anyone's login.
+type redisTwoFactorStore struct {
+	client *redis.Client
+}
+
+func NewRedisTwoFactorStore(client *redis.Client) domain.ITwoFactorStore {
+	return &redisTwoFactorStore{client: client}
+}
+
+func twoFactorChallengeKey(challenge string) string {
+	sum := sha256.Sum256([]byte(challenge))
+	return "auth:2fa:challenge:" + hex.EncodeToString(sum[:])
+}
+
+func twoFactorFailuresKey(userID string) string {
+	return "auth:2fa:failures:" + userID
+}
+
+func (s *redisTwoFactorStore) CreateChallenge(ctx context.Context, userID string, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	challenge := base64.RawURLEncoding.EncodeToString(raw)
+	if err := s.client.Set(ctx, twoFactorChallengeKey(challenge), userID, ttl).Err(); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+func (s *redisTwoFactorStore) ChallengeUser(ctx context.Context, challenge string) (string, error) {
+	userID, err := s.client.Get(ctx, twoFactorChallengeKey(challenge)).Result()
+	if err == redis.Nil {
+		return "", domain.ErrInvalidTwoFactorChallenge
+	}
+	return userID, err
+}
+
+func (s *redisTwoFactorStore) DeleteChallenge(ctx context.Context, challenge string) (bool, error) {
+	deleted, err := s.client.Del(ctx, twoFactorChallengeKey(challenge)).Result()
+	return deleted > 0, err
+}
+
+// RecordAttempt counts before the code is checked, so concurrent guesses
+// cannot all get past the limit; the window starts with the first one.
+func (s *redisTwoFactorStore) RecordAttempt(ctx context.Context, userID string, window time.Duration) (int64, error) {
+	key := twoFactorFailuresKey(userID)
+	pipe := s.client.TxPipeline()
+	attempts := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return attempts.Val(), nil
+}
+
+func (s *redisTwoFactorStore) ClearFailures(ctx context.Context, userID string) error {
+	return s.client.Del(ctx, twoFactorFailuresKey(userID)).Err()
+}
@@ -0,0 +1,209 @@
+package infrastructure
+
+import (
+	"blog-backend/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	pendingViewsKey  = "views:pending"
+	viewFlushLockKey = "views:flush:lock"
+	viewFlushLockTTL = 2 * time.Minute
+	viewFlushBatch   = 500
+	viewDayLayout    = "2006-01-02"
+	// every flush moves the pending views to a hash of its own, listed in
+	// flushingViewsSet until all of it is written
+	flushingViewsPrefix = "views:flushing:"
+	flushingViewsSet    = "views:flushes"
+	// the single hash flushes used before they had one of their own
+	legacyFlushingViewsKey = "views:flushing"
+	// readers of a day are kept until its last views are surely flushed
+	viewReadersTTL = 48 * time.Hour
+)
+
+// releaseLock deletes the lock only while it still holds our token, so a
+// flush that outlived the lock cannot release another instance's.
+var releaseLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// redisViewCounter dedupes views with a short lived key per blog and viewer
+// and sums the counted views per blog and day in a hash. The day's unique
+// readers go into a HyperLogLog per blog and day. A flush renames the hash to
+// a key of its own first, so views recorded meanwhile go into a fresh one.
+type redisViewCounter struct {
+	client *redis.Client
+	window time.Duration
+}
+
+// NewRedisViewCounter counts a viewer at most once per blog within window.
+func NewRedisViewCounter(client *redis.Client, window time.Duration) domain.IViewCounter {
+	return &redisViewCounter{client: client, window: window}
+}
+
+func (vc *redisViewCounter) RecordView(ctx context.Context, blogID, viewer string) (bool, error) {
+	seenKey := fmt.Sprintf("views:seen:%s:%s", blogID, viewer)
+	fresh, err := vc.client.SetNX(ctx, seenKey, 1, vc.window).Result()
+	if err != nil || !fresh {
+		return false, err
+	}
+
+	day := time.Now().UTC().Format(viewDayLayout)
+	readersKey := viewReadersKey(blogID, day)
+	pipe := vc.client.TxPipeline()
+	pipe.PFAdd(ctx, readersKey, viewer)
+	pipe.Expire(ctx, readersKey, viewReadersTTL)
+	pipe.HIncrBy(ctx, pendingViewsKey, blogID+":"+day, 1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func viewReadersKey(blogID, day string) string {
+	return fmt.Sprintf("views:readers:%s:%s", blogID, day)
+}
+
+func (vc *redisViewCounter) FlushViews(ctx context.Context, apply func(ctx context.Context, batchID string, views []*domain.ViewCount) error) (int64, error) {
+	token, err := lockToken()
+	if err != nil {
+		return 0, err
+	}
+	locked, err := vc.client.SetNX(ctx, viewFlushLockKey, token, viewFlushLockTTL).Result()
+	if err != nil || !locked {
+		return 0, err
+	}
+	defer releaseLock.Run(context.Background(), vc.client, []string{viewFlushLockKey}, token)
+
+	legacy, err := vc.client.Exists(ctx, legacyFlushingViewsKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if legacy > 0 {
+		if err := vc.client.SAdd(ctx, flushingViewsSet, legacyFlushingViewsKey).Err(); err != nil {
+			return 0, err
+		}
+	}
+	// views left over from failed flushes go first
+	keys, err := vc.client.SMembers(ctx, flushingViewsSet).Result()
+	if err != nil {
+		return 0, err
+	}
+	// only a flush removes the pending hash, and we hold the lock
+	pending, err := vc.client.Exists(ctx, pendingViewsKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if pending > 0 {
+		key := flushingViewsPrefix + token
+		pipe := vc.client.TxPipeline()
+		pipe.Rename(ctx, pendingViewsKey, key)
+		pipe.SAdd(ctx, flushingViewsSet, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return 0, err
+		}
+		keys = append(keys, key)
+	}
+
+	var flushed int64
+	for _, key := range keys {
+		n, err := vc.flushHash(ctx, key, apply)
+		flushed += n
+		if err != nil {
+			return flushed, err
+		}
+	}
+	return flushed, nil
+}
+
+// flushHash hands one flush's views to apply and deletes the hash once all of
+// them are applied. Batches are cut from the sorted fields and named after
+// the hash and their first field, so a retry hands over the same batches
+// under the same ids.
+func (vc *redisViewCounter) flushHash(ctx context.Context, key string, apply func(ctx context.Context, batchID string, views []*domain.ViewCount) error) (int64, error) {
+	stored, err := vc.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	fields := make([]string, 0, len(stored))
+	for field := range stored {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var flushed int64
+	for start := 0; start < len(fields); start += viewFlushBatch {
+		batch := fields[start:min(start+viewFlushBatch, len(fields))]
+		views, err := vc.viewCounts(ctx, stored, batch)
+		if err != nil {
+			return flushed, err
+		}
+		if err := apply(ctx, key+":"+batch[0], views); err != nil {
+			return flushed, err
+		}
+		for _, view := range views {
+			flushed += int64(view.Views)
+		}
+	}
+
+	// also drops fields that held no usable count
+	pipe := vc.client.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.SRem(ctx, flushingViewsSet, key)
+	_, err = pipe.Exec(ctx)
+	return flushed, err
+}
+
+// viewCounts turns the hash fields, blog id and day, into view counts with
+// the day's readers. Fields with a bad count or day are skipped.
+func (vc *redisViewCounter) viewCounts(ctx context.Context, stored map[string]string, fields []string) ([]*domain.ViewCount, error) {
+	views := make([]*domain.ViewCount, 0, len(fields))
+	readers := make([]*redis.IntCmd, 0, len(fields))
+	pipe := vc.client.Pipeline()
+	for _, field := range fields {
+		blogID, dayValue, ok := strings.Cut(field, ":")
+		if !ok {
+			// buffered before views were kept per day
+			dayValue = time.Now().UTC().Format(viewDayLayout)
+		}
+		day, err := time.Parse(viewDayLayout, dayValue)
+		if err != nil {
+			continue
+		}
+		count, err := strconv.Atoi(stored[field])
+		if err != nil || count <= 0 {
+			continue
+		}
+		views = append(views, &domain.ViewCount{BlogID: blogID, Day: day, Views: count})
+		readers = append(readers, pipe.PFCount(ctx, viewReadersKey(blogID, dayValue)))
+	}
+	if len(views) == 0 {
+		return views, nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	for i, view := range views {
+		view.Readers = int(readers[i].Val())
+	}
+	return views, nil
+}
+
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -64,14 +63,49 @@ func (br *blogRepository) GetBlogByID(ctx context.Context, id string) (*domain.B
 		return nil, err
 	}
 	var blog BlogResponseDTO
-	filter := bson.M{"_id": oid}
+	filter := notDeleted(bson.M{"_id": oid})
 	err = collection.FindOne(ctx, filter).Decode(&blog)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrBlogNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return DtoToDomain(&blog), nil
 }
+// GetBlogsByIDs returns the blogs that exist among ids, in no particular order.
+func (br *blogRepository) GetBlogsByIDs(ctx context.Context, ids []string) ([]*domain.Blog, error) {
+	collection := br.database.Collection(br.collection)
+	oids := make([]bson.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		oid, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		oids = append(oids, oid)
+	}
+	if len(oids) == 0 {
+		return []*domain.Blog{}, nil
+	}
+
+	cursor, err := collection.Find(ctx, notDeleted(bson.M{"_id": bson.M{"$in": oids}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []BlogResponseDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	blogs := make([]*domain.Blog, len(dtos))
+	for i, dto := range dtos {
+		blogs[i] = DtoToDomain(&dto)
+	}
+	return blogs, nil
+}
+
 func (br *blogRepository) UpdateBlog(ctx context.Context, id string, userID string, updates map[string]interface{}) error {
 	collection := br.database.Collection(br.collection)
 	oid, err := bson.ObjectIDFromHex(id)
@@ -79,8 +113,11 @@ func (br *blogRepository) UpdateBlog(ctx context.Context, id string, userID stri
 		return err
 	}
 	var blog BlogResponseDTO
-	filter := bson.M{"_id": oid}
+	filter := notDeleted(bson.M{"_id": oid})
 	err = collection.FindOne(ctx, filter).Decode(&blog)
+	if err == mongo.ErrNoDocuments {
+		return domain.ErrBlogNotFound
+	}
 	if err != nil {
 		return err
 	}
@@ -105,98 +142,457 @@ func (br *blogRepository) UpdateBlog(ctx context.Context, id string, userID stri
 	_, err = collection.UpdateOne(ctx, filter, update)
 	return err
 }
-func (br *blogRepository) DeleteBlog(ctx context.Context, id string) error {
+// DeleteBlog soft deletes the blog; PurgeBlogs removes it for good later.
+func (br *blogRepository) DeleteBlog(ctx context.Context, id string, deletedBy string) error {
 	collection := br.database.Collection(br.collection)
 	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrBlogNotFound
+	}
+	res, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), softDeleteUpdate(deletedBy, time.Now()))
 	if err != nil {
 		return err
 	}
-	_, err = collection.DeleteOne(ctx, bson.M{"_id": oid})
-	return err
+	if res.MatchedCount == 0 {
+		return domain.ErrBlogNotFound
+	}
+	return nil
 }
 
-// Blog Listing
-func (br *blogRepository) ListBlogs(ctx context.Context, page, limit int, field string) ([]*domain.Blog, int64, error) {
-	if page <= 0 {
-		page = 1
+func (br *blogRepository) RestoreBlog(ctx context.Context, id string) error {
+	collection := br.database.Collection(br.collection)
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrBlogNotFound
+	}
+	filter := bson.M{"_id": oid, "deleted_at": bson.M{"$ne": nil}}
+	res, err := collection.UpdateOne(ctx, filter, restoreUpdate())
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrBlogNotFound
+	}
+	return nil
+}
+
+func (br *blogRepository) ListDeletedBlogIDs(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	collection := br.database.Collection(br.collection)
+	cursor, err := collection.Find(ctx, deletedBeforeFilter(before), deletedIDsOptions(limit))
+	if err != nil {
+		return nil, err
+	}
+	var dtos []idOnlyDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	return idsToHex(dtos), nil
+}
+
+func (br *blogRepository) FilterDeletedBlogIDs(ctx context.Context, ids []string) ([]string, error) {
+	collection := br.database.Collection(br.collection)
+	oids, err := hexToObjectIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(oids) == 0 {
+		return nil, nil
+	}
+	filter := bson.M{"_id": bson.M{"$in": oids}, "deleted_at": bson.M{"$ne": nil}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var dtos []idOnlyDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	return idsToHex(dtos), nil
+}
+
+// PurgeBlogs permanently removes the given blogs, skipping any that were
+// restored in the meantime. Blogs go one at a time so the caller learns
+// exactly which ones are gone.
+func (br *blogRepository) PurgeBlogs(ctx context.Context, ids []string) ([]string, error) {
+	collection := br.database.Collection(br.collection)
+	oids, err := hexToObjectIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	purged := make([]bson.ObjectID, 0, len(oids))
+	for _, oid := range oids {
+		res, err := collection.DeleteOne(ctx, bson.M{"_id": oid, "deleted_at": bson.M{"$ne": nil}})
+		if err != nil {
+			return objectIDsToHex(purged), err
+		}
+		if res.DeletedCount > 0 {
+			purged = append(purged, oid)
+		}
+	}
+	return objectIDsToHex(purged), nil
+}
+
+func (br *blogRepository) DeleteBlogsByAuthor(ctx context.Context, authorID, deletedBy string, at time.Time) ([]string, error) {
+	oid, err := bson.ObjectIDFromHex(authorID)
+	if err != nil {
+		return nil, err
+	}
+	return br.updateBlogsOf(ctx, notDeleted(bson.M{"author_id": oid}), softDeleteUpdate(deletedBy, at))
+}
+
+func (br *blogRepository) RestoreBlogsByAuthor(ctx context.Context, authorID string, at time.Time) ([]string, error) {
+	oid, err := bson.ObjectIDFromHex(authorID)
+	if err != nil {
+		return nil, err
+	}
+	return br.updateBlogsOf(ctx, bson.M{"author_id": oid, "deleted_at": at}, restoreUpdate())
+}
+
+// updateBlogsOf applies update to the blogs matching filter and returns the
+// IDs of the blogs it matched.
+func (br *blogRepository) updateBlogsOf(ctx context.Context, filter bson.M, update bson.M) ([]string, error) {
+	collection := br.database.Collection(br.collection)
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var dtos []idOnlyDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
 	}
-	if limit <= 0 {
-		limit = 10
+	if len(dtos) == 0 {
+		return []string{}, nil
+	}
+	ids := make([]bson.ObjectID, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.ID
+	}
+	filter["_id"] = bson.M{"$in": ids}
+	if _, err := collection.UpdateMany(ctx, filter, update); err != nil {
+		return nil, err
+	}
+	return idsToHex(dtos), nil
+}
+
+func (br *blogRepository) ListBlogIDsByAuthor(ctx context.Context, authorID string) ([]string, error) {
+	collection := br.database.Collection(br.collection)
+	oid, err := bson.ObjectIDFromHex(authorID)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, bson.M{"author_id": oid}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var dtos []idOnlyDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	return idsToHex(dtos), nil
+}
+
+func (br *blogRepository) TransferBlogs(ctx context.Context, fromAuthorID, toAuthorID string) (int64, error) {
+	collection := br.database.Collection(br.collection)
+	from, err := bson.ObjectIDFromHex(fromAuthorID)
+	if err != nil {
+		return 0, err
+	}
+	to, err := bson.ObjectIDFromHex(toAuthorID)
+	if err != nil {
+		return 0, err
+	}
+	res, err := collection.UpdateMany(ctx, bson.M{"author_id": from}, bson.M{"$set": bson.M{"author_id": to}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (br *blogRepository) SetBlogMetrics(ctx context.Context, blogID string, metrics map[string]int) error {
+	collection := br.database.Collection(br.collection)
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.ErrBlogNotFound
+	}
+	set := bson.M{}
+	for field, value := range metrics {
+		set[field] = value
+	}
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrBlogNotFound
+	}
+	return nil
+}
+
+// Blog Listing
+func (br *blogRepository) ListBlogs(ctx context.Context, cursorToken string, limit int, field string) ([]*domain.Blog, string, error) {
+	limit = normalizeLimit(limit)
+	if !isBlogSortField(field) {
+		field = "created_at"
 	}
 	collection := br.database.Collection(br.collection)
-	skip := int64((page - 1) * limit)
-	lim := int64(limit)
-	findOptions := options.Find()
-	findOptions.SetSkip(skip)
-	findOptions.SetLimit(lim)
-	findOptions.SetSort(bson.D{{Key: field, Value: -1}})
 
-	cursor, err := collection.Find(ctx, bson.M{}, findOptions)
+	filter := publishedFilter()
+	findOptions, err := paginate(filter, cursorToken, field, -1, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", err
 	}
 	defer cursor.Close(ctx)
 	var blogResDTOs []BlogResponseDTO
 	if err = cursor.All(ctx, &blogResDTOs); err != nil {
-		return nil, 0, err
+		return nil, "", err
 	}
-	blogs := make([]*domain.Blog, len(blogResDTOs))
-	for i, dto := range blogResDTOs {
-		blogs[i] = DtoToDomain(&dto)
+
+	blogs, next := blogPage(blogResDTOs, limit, field)
+	return blogs, next, nil
+}
+// ListBlogsByAuthor returns the author's blogs in the given status; an empty
+// status returns every blog regardless of lifecycle.
+func (br *blogRepository) ListBlogsByAuthor(ctx context.Context, authorID string, status domain.BlogStatus, cursorToken string, limit int) ([]*domain.Blog, string, error) {
+	limit = normalizeLimit(limit)
+	collection := br.database.Collection(br.collection)
+	oid, err := bson.ObjectIDFromHex(authorID)
+	if err != nil {
+		return nil, "", err
 	}
-	total, err := collection.CountDocuments(ctx, bson.M{})
+	filter := notDeleted(bson.M{"author_id": oid})
+	switch status {
+	case "":
+	case domain.BlogPublished:
+		for k, v := range publishedFilter() {
+			filter[k] = v
+		}
+	default:
+		filter["status"] = string(status)
+	}
+	findOptions, err := paginate(filter, cursorToken, "created_at", -1, limit)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", err
+	}
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var blogResDTOs []BlogResponseDTO
+	if err = cursor.All(ctx, &blogResDTOs); err != nil {
+		return nil, "", err
 	}
 
-	return blogs, total, nil
+	blogs, next := blogPage(blogResDTOs, limit, "created_at")
+	return blogs, next, nil
 }
-func (br *blogRepository) ListBlogsByAuthor(ctx context.Context, authorID string) ([]*domain.Blog, error) {
+
+func (br *blogRepository) ListBlogsByAuthors(ctx context.Context, authorIDs []string, cursorToken string, limit int) ([]*domain.Blog, string, error) {
+	limit = normalizeLimit(limit)
 	collection := br.database.Collection(br.collection)
-	filter := bson.M{"author_id": authorID}
-	cursor, err := collection.Find(ctx, filter)
+	oids := make([]bson.ObjectID, 0, len(authorIDs))
+	for _, id := range authorIDs {
+		oid, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, "", err
+		}
+		oids = append(oids, oid)
+	}
+
+	filter := publishedFilter()
+	filter["author_id"] = bson.M{"$in": oids}
+	findOptions, err := paginate(filter, cursorToken, "created_at", -1, limit)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, "", err
 	}
 	defer cursor.Close(ctx)
 
 	var blogResDTOs []BlogResponseDTO
 	if err = cursor.All(ctx, &blogResDTOs); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	blogs := make([]*domain.Blog, len(blogResDTOs))
-	for i, dto := range blogResDTOs {
-		blogs[i] = DtoToDomain(&dto)
+	blogs, next := blogPage(blogResDTOs, limit, "created_at")
+	return blogs, next, nil
+}
+
+func (br *blogRepository) LatestBlogUpdate(ctx context.Context, authorIDs []string) (time.Time, error) {
+	collection := br.database.Collection(br.collection)
+	oids, err := hexToObjectIDs(authorIDs)
+	if err != nil {
+		return time.Time{}, err
 	}
 
-	return blogs, nil
+	filter := publishedFilter()
+	filter["author_id"] = bson.M{"$in": oids}
+	findOptions := options.FindOne().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetProjection(bson.M{"updated_at": 1})
+	var latest struct {
+		UpdatedAt time.Time `bson:"updated_at"`
+	}
+	err = collection.FindOne(ctx, filter, findOptions).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return latest.UpdatedAt, nil
 }
-func (br *blogRepository) SearchBlogs(ctx context.Context, query string) ([]*domain.Blog, error) {
+
+// SearchBlogs runs a weighted full-text search over title, tags and content
+// combined with the query's filters. Relevance ordering is paged by offset,
+// every other ordering by keyset.
+func (br *blogRepository) SearchBlogs(ctx context.Context, query domain.SearchQuery) ([]*domain.SearchResult, string, error) {
+	limit := normalizeLimit(query.Limit)
 	collection := br.database.Collection(br.collection)
-	filter := bson.M{
-		"$or": []bson.M{
-			{"title": bson.M{"$regex": query, "$options": "i"}},
-			{"tags": bson.M{"$regex": query, "$options": "i"}},
-		},
+
+	filter := publishedFilter()
+	if query.Text != "" {
+		filter["$text"] = bson.M{"$search": query.Text}
 	}
-	cursor, err := collection.Find(ctx, filter)
+	if query.AuthorID != "" {
+		oid, err := bson.ObjectIDFromHex(query.AuthorID)
+		if err != nil {
+			return nil, "", err
+		}
+		filter["author_id"] = oid
+	}
+	if len(query.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": query.Tags}
+	}
+	if !query.From.IsZero() || !query.To.IsZero() {
+		createdAt := bson.M{}
+		if !query.From.IsZero() {
+			createdAt["$gte"] = query.From
+		}
+		if !query.To.IsZero() {
+			createdAt["$lte"] = query.To
+		}
+		filter["created_at"] = createdAt
+	}
+	if query.MinLikes > 0 {
+		filter["like_count"] = bson.M{"$gte": query.MinLikes}
+	}
+
+	sortBy := query.Sort
+	if sortBy == domain.SortRelevance && query.Text == "" {
+		sortBy = domain.SortNewest
+	}
+
+	var findOptions *options.FindOptionsBuilder
+	offset := 0
+	field := "created_at"
+	if sortBy == domain.SortRelevance {
+		var err error
+		if offset, err = decodeOffsetCursor(query.Cursor); err != nil {
+			return nil, "", err
+		}
+		findOptions = options.Find().
+			SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "_id", Value: -1}}).
+			SetSkip(int64(offset)).
+			SetLimit(int64(limit + 1))
+	} else {
+		direction := -1
+		switch sortBy {
+		case domain.SortOldest:
+			direction = 1
+		case domain.SortMostLiked:
+			field = "like_count"
+		case domain.SortMostViewed:
+			field = "view_count"
+		}
+		var err error
+		if findOptions, err = paginate(filter, query.Cursor, field, direction, limit); err != nil {
+			return nil, "", err
+		}
+	}
+	if query.Text != "" {
+		findOptions.SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}})
+	}
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer cursor.Close(ctx)
 
-	var blogResDTOs []BlogResponseDTO
-	if err = cursor.All(ctx, &blogResDTOs); err != nil {
-		return nil, err
+	var searchDTOs []blogSearchDTO
+	if err = cursor.All(ctx, &searchDTOs); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(searchDTOs) > limit {
+		searchDTOs = searchDTOs[:limit]
+		if sortBy == domain.SortRelevance {
+			next = encodeOffsetCursor(offset + limit)
+		} else {
+			last := searchDTOs[limit-1]
+			next = encodePageCursor(blogSortValue(&last.BlogResponseDTO, field), last.ID)
+		}
+	}
+
+	results := make([]*domain.SearchResult, len(searchDTOs))
+	for i, dto := range searchDTOs {
+		results[i] = &domain.SearchResult{
+			Blog:  DtoToDomain(&dto.BlogResponseDTO),
+			Score: dto.Score,
+		}
+	}
+	return results, next, nil
+}
+
+func isBlogSortField(field string) bool {
+	switch field {
+	case "created_at", "updated_at", "view_count", "like_count", "dislike_count", "comment_count", "save_count":
+		return true
 	}
+	return false
+}
 
-	blogs := make([]*domain.Blog, len(blogResDTOs))
-	for i, dto := range blogResDTOs {
+// blogPage converts a page fetched with paginate to domain blogs and returns
+// the cursor for the following page, or "" when this was the last one.
+func blogPage(dtos []BlogResponseDTO, limit int, field string) ([]*domain.Blog, string) {
+	next := ""
+	if len(dtos) > limit {
+		dtos = dtos[:limit]
+		last := dtos[limit-1]
+		next = encodePageCursor(blogSortValue(&last, field), last.ID)
+	}
+	blogs := make([]*domain.Blog, len(dtos))
+	for i, dto := range dtos {
 		blogs[i] = DtoToDomain(&dto)
 	}
+	return blogs, next
+}
 
-	return blogs, nil
+func blogSortValue(dto *BlogResponseDTO, field string) interface{} {
+	switch field {
+	case "updated_at":
+		return dto.UpdatedAt
+	case "view_count":
+		return dto.ViewCount
+	case "like_count":
+		return dto.LikeCount
+	case "dislike_count":
+		return dto.DislikeCount
+	case "comment_count":
+		return dto.CommentCount
+	case "save_count":
+		return dto.SaveCount
+	}
+	return dto.CreatedAt
 }
 
 func (br *blogRepository) UpdateBlogMetrics(ctx context.Context, blogID string, field string, reaction int) error {
@@ -205,16 +601,230 @@ func (br *blogRepository) UpdateBlogMetrics(ctx context.Context, blogID string,
 	if err != nil {
 		return err
 	}
-	filter := bson.M{"_id": oid}
+	filter := notDeleted(bson.M{"_id": oid})
 	updates := bson.M{"$inc": bson.M{field: reaction}}
 	res, err := collection.UpdateOne(ctx, filter, updates)
+	if err != nil {
+		return err
+	}
 	if res.ModifiedCount == 0 {
 		return fmt.Errorf("no blog found with id %s", blogID)
 	}
 
+	return nil
+}
+
+func (br *blogRepository) AddViewCounts(ctx context.Context, views map[string]int) error {
+	collection := br.database.Collection(br.collection)
+	models := make([]mongo.WriteModel, 0, len(views))
+	for blogID, count := range views {
+		oid, err := bson.ObjectIDFromHex(blogID)
+		if err != nil {
+			continue
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(notDeleted(bson.M{"_id": oid})).
+			SetUpdate(bson.M{"$inc": bson.M{string(domain.ViewCountField): count}}))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
 	return err
 }
 
+func (br *blogRepository) IncrementBlogMetrics(ctx context.Context, blogID string, increments map[string]int) error {
+	collection := br.database.Collection(br.collection)
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.ErrBlogNotFound
+	}
+	inc := bson.M{}
+	for field, increment := range increments {
+		if increment != 0 {
+			inc[field] = increment
+		}
+	}
+	if len(inc) == 0 {
+		return nil
+	}
+	res, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), bson.M{"$inc": inc})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrBlogNotFound
+	}
+	return nil
+}
+
+func (br *blogRepository) ListReactionCounters(ctx context.Context, afterID string, limit int) ([]*domain.ReactionCounters, error) {
+	collection := br.database.Collection(br.collection)
+	filter := bson.M{}
+	if afterID != "" {
+		oid, err := bson.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, domain.ErrInvalidCursor
+		}
+		filter["_id"] = bson.M{"$gt": oid}
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1, "like_count": 1, "dislike_count": 1, "reaction_counts": 1})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	var dtos []struct {
+		ID             bson.ObjectID  `bson:"_id"`
+		LikeCount      int            `bson:"like_count"`
+		DislikeCount   int            `bson:"dislike_count"`
+		ReactionCounts map[string]int `bson:"reaction_counts"`
+	}
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	counters := make([]*domain.ReactionCounters, len(dtos))
+	for i, dto := range dtos {
+		counts := make(map[domain.ReactionType]int, len(dto.ReactionCounts))
+		for reactionType, count := range dto.ReactionCounts {
+			counts[domain.ReactionType(reactionType)] = count
+		}
+		counters[i] = &domain.ReactionCounters{
+			ID:           dto.ID.Hex(),
+			Counts:       counts,
+			LikeCount:    dto.LikeCount,
+			DislikeCount: dto.DislikeCount,
+		}
+	}
+	return counters, nil
+}
+
+func (br *blogRepository) SetReactionCounts(ctx context.Context, blogID string, counts map[domain.ReactionType]int) error {
+	stored := make(map[string]int, len(counts))
+	for reactionType, count := range counts {
+		if count > 0 {
+			stored[string(reactionType)] = count
+		}
+	}
+	collection := br.database.Collection(br.collection)
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.ErrBlogNotFound
+	}
+	update := bson.M{"$set": bson.M{
+		string(domain.ReactionCountsField): stored,
+		string(domain.LikeCountField):      counts[domain.Like],
+		string(domain.DislikeCountField):   counts[domain.Dislike],
+	}}
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrBlogNotFound
+	}
+	return nil
+}
+
+// PublishDueBlogs flips every scheduled blog whose publish time has passed to
+// published and reports which ones were changed.
+func (br *blogRepository) PublishDueBlogs(ctx context.Context, now time.Time) ([]string, error) {
+	collection := br.database.Collection(br.collection)
+	filter := notDeleted(bson.M{
+		"status":     string(domain.BlogScheduled),
+		"publish_at": bson.M{"$lte": now},
+	})
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var due []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &due); err != nil {
+		return nil, err
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+
+	oids := make([]bson.ObjectID, len(due))
+	ids := make([]string, len(due))
+	for i, d := range due {
+		oids[i] = d.ID
+		ids[i] = d.ID.Hex()
+	}
+	// keep the status condition so a blog unscheduled in the meantime stays put
+	filter["_id"] = bson.M{"$in": oids}
+	// a blog that was published before keeps its first publish date
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"status":       string(domain.BlogPublished),
+		"published_at": bson.M{"$ifNull": bson.A{"$published_at", now}},
+		"updated_at":   now,
+	}}}}
+	if _, err := collection.UpdateMany(ctx, filter, update); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (br *blogRepository) ListRecentBlogsByTags(ctx context.Context, tags []string, limit int) ([]*domain.Blog, error) {
+	collection := br.database.Collection(br.collection)
+	if len(tags) == 0 {
+		return []*domain.Blog{}, nil
+	}
+	filter := publishedFilter()
+	filter["tags"] = bson.M{"$in": tags}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	var dtos []BlogResponseDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	blogs := make([]*domain.Blog, len(dtos))
+	for i, dto := range dtos {
+		blogs[i] = DtoToDomain(&dto)
+	}
+	return blogs, nil
+}
+
+// publishedFilter matches blogs visible to readers. Blogs written before the
+// lifecycle existed have no status field and are treated as published.
+// Blogs hidden by moderators or soft deleted are left out.
+func publishedFilter() bson.M {
+	return notDeleted(bson.M{
+		"status": bson.M{"$in": []interface{}{string(domain.BlogPublished), nil}},
+		"hidden": bson.M{"$ne": true},
+	})
+}
+
+func (br *blogRepository) SetBlogHidden(ctx context.Context, blogID string, hidden bool) error {
+	collection := br.database.Collection(br.collection)
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.ErrBlogNotFound
+	}
+
+	res, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), bson.M{"$set": bson.M{"hidden": hidden}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrBlogNotFound
+	}
+	return nil
+}
+
 // i added this function because we didn't have a function that evaluate blog authers k
 func (br *blogRepository) IsAuthor(ctx context.Context, blogID, userID string) (bool, error) {
 	collection := br.database.Collection(br.collection)
@@ -226,7 +836,7 @@ func (br *blogRepository) IsAuthor(ctx context.Context, blogID, userID string) (
 	if err != nil {
 		return false, err
 	}
-	filter := bson.M{"_id": oid, "author_id": ouid}
+	filter := notDeleted(bson.M{"_id": oid, "author_id": ouid})
 	count, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return false, err
@@ -236,6 +846,9 @@ func (br *blogRepository) IsAuthor(ctx context.Context, blogID, userID string) (
 }
 
 
+// maxReadHistory is how many reads a user's history keeps.
+const maxReadHistory = 500
+
 func (h *historyRepository) AddReadHistory(ctx context.Context, userID, blogID string, blogTags []string) error {
     collection := h.database.Collection(h.collection)
 
@@ -246,8 +859,12 @@ func (h *historyRepository) AddReadHistory(ctx context.Context, userID, blogID s
     readUpdate := bson.M{
         "$push": bson.M{
             "reads": bson.M{
-                "blog_id":    blogID,
-                "created_at": time.Now(),
+                // only the latest reads are kept
+                "$each": []bson.M{{
+                    "blog_id":    blogID,
+                    "created_at": time.Now(),
+                }},
+                "$slice": -maxReadHistory,
             },
         },
         "$setOnInsert": bson.M{
@@ -288,66 +905,48 @@ func (h *historyRepository) AddReadHistory(ctx context.Context, userID, blogID s
 }
 
 
-func (h *historyRepository) GetRecommendations(ctx context.Context, userID string) ([]*domain.Blog, error) {
-    collection := h.database.Collection(h.collection)
-    blogRepo := NewBlogRepositoryFromDB(h.database) // same DB assumed
-
-    // Fetch only the tags array from the user's history
-    var onlyTags struct {
-        Tags []domain.TagsCount `bson:"tags"`
-    }
-    err := collection.FindOne(
-        ctx,
-        bson.M{"user_id": userID},
-        options.FindOne().SetProjection(bson.M{"tags": 1}),
-    ).Decode(&onlyTags)
-    if err != nil {
-        if err == mongo.ErrNoDocuments {
-            return []*domain.Blog{}, nil // no history -> empty
-        }
-        return nil, fmt.Errorf("failed to get read history: %v", err)
-    }
-
-    tags := onlyTags.Tags
-    if len(tags) == 0 {
-        return []*domain.Blog{}, nil
-    }
-
-    // sort tags by count desc and take top 3
-    sort.Slice(tags, func(i, j int) bool {
-        return tags[i].Count > tags[j].Count
-    })
-    if len(tags) > 3 {
-        tags = tags[:3]
-    }
-
-    // collect blogs for each top tag (deduplicate)
-    blogSet := make(map[string]*domain.Blog)
-    for _, t := range tags {
-        blogs, err := blogRepo.SearchBlogs(ctx, t.Tag)
-        if err != nil {
-            return nil, fmt.Errorf("failed to search blogs for tag %s: %v", t.Tag, err)
-        }
-        for _, b := range blogs {
-            blogSet[b.ID] = b
-        }
-    }
+func (h *historyRepository) GetRecentReads(ctx context.Context, userID string, limit int) ([]*domain.BlogRead, error) {
+	collection := h.database.Collection(h.collection)
+	var history struct {
+		Reads []struct {
+			BlogID    string    `bson:"blog_id"`
+			CreatedAt time.Time `bson:"created_at"`
+		} `bson:"reads"`
+	}
+	findOptions := options.FindOne().SetProjection(bson.M{"reads": bson.M{"$slice": -limit}})
+	err := collection.FindOne(ctx, bson.M{"user_id": userID}, findOptions).Decode(&history)
+	if err == mongo.ErrNoDocuments {
+		return []*domain.BlogRead{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get read history: %v", err)
+	}
 
-    // convert to slice and sort by view count desc
-    blogs := make([]*domain.Blog, 0, len(blogSet))
-    for _, b := range blogSet {
-        blogs = append(blogs, b)
-    }
-    sort.Slice(blogs, func(i, j int) bool {
-        return blogs[i].ViewCount > blogs[j].ViewCount
-    })
+	// reads are stored oldest first
+	reads := make([]*domain.BlogRead, len(history.Reads))
+	for i, read := range history.Reads {
+		reads[len(reads)-1-i] = &domain.BlogRead{BlogID: read.BlogID, ReadAt: read.CreatedAt}
+	}
+	return reads, nil
+}
 
-    // limit to top 3
-    if len(blogs) > 3 {
-        blogs = blogs[:3]
-    }
+// RemoveBlogsFromHistory drops every read of the given blogs from all users'
+// histories. Tag counts are left alone; they only steer recommendations.
+func (h *historyRepository) RemoveBlogsFromHistory(ctx context.Context, blogIDs []string) error {
+	if len(blogIDs) == 0 {
+		return nil
+	}
+	collection := h.database.Collection(h.collection)
+	filter := bson.M{"reads.blog_id": bson.M{"$in": blogIDs}}
+	update := bson.M{"$pull": bson.M{"reads": bson.M{"blog_id": bson.M{"$in": blogIDs}}}}
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
 
-    return blogs, nil
+func (h *historyRepository) DeleteHistoryForUser(ctx context.Context, userID string) error {
+	collection := h.database.Collection(h.collection)
+	_, err := collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
 }
 
 
@@ -357,12 +956,23 @@ type BlogResponseDTO struct {
 	Content      string        `bson:"content" binding:"required"`
 	AuthorID     bson.ObjectID `bson:"author_id" binding:"required"`
 	Tags         []string      `bson:"tags" binding:"required"`
+	Status       string        `bson:"status"`
+	PublishAt    time.Time     `bson:"publish_at,omitempty"`
+	PublishedAt  time.Time     `bson:"published_at,omitempty"`
 	CreatedAt    time.Time     `bson:"created_at"`
 	UpdatedAt    time.Time     `bson:"updated_at"`
 	ViewCount    int           `bson:"view_count"`
 	LikeCount    int           `bson:"like_count"`
 	DislikeCount int           `bson:"dislike_count"`
 	CommentCount int           `bson:"comment_count"`
+	SaveCount    int           `bson:"save_count"`
+	ReactionCounts map[string]int `bson:"reaction_counts,omitempty"`
+	Hidden       bool          `bson:"hidden"`
+}
+
+type blogSearchDTO struct {
+	BlogResponseDTO `bson:",inline"`
+	Score           float64 `bson:"score"`
 }
 
 type BlogDTO struct {
@@ -370,12 +980,16 @@ type BlogDTO struct {
 	Content      string        `bson:"content" binding:"required"`
 	AuthorID     bson.ObjectID `bson:"author_id" binding:"required"`
 	Tags         []string      `bson:"tags" binding:"required"`
+	Status       string        `bson:"status"`
+	PublishAt    time.Time     `bson:"publish_at,omitempty"`
+	PublishedAt  time.Time     `bson:"published_at,omitempty"`
 	CreatedAt    time.Time     `bson:"created_at"`
 	UpdatedAt    time.Time     `bson:"updated_at"`
 	ViewCount    int           `bson:"view_count"`
 	LikeCount    int           `bson:"like_count"`
 	DislikeCount int           `bson:"dislike_count"`
 	CommentCount int           `bson:"comment_count"`
+	SaveCount    int           `bson:"save_count"`
 }
 
 func DomainToDto(blog *domain.Blog) (*BlogDTO, error) {
@@ -389,34 +1003,67 @@ func DomainToDto(blog *domain.Blog) (*BlogDTO, error) {
 		Content:      blog.Content,
 		AuthorID:     oid,
 		Tags:         blog.Tags,
+		Status:       string(blog.Status),
+		PublishAt:    blog.PublishAt,
+		PublishedAt:  blog.PublishedAt,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 		ViewCount:    blog.ViewCount,
 		LikeCount:    blog.LikeCount,
 		DislikeCount: blog.DislikeCount,
 		CommentCount: blog.CommentCount,
+		SaveCount:    blog.SaveCount,
 	}, err
 }
 
 func DtoToDomain(blogDTO *BlogResponseDTO) *domain.Blog {
+	status := domain.BlogStatus(blogDTO.Status)
+	if status == "" {
+		status = domain.BlogPublished
+	}
 	return &domain.Blog{
 		ID:           blogDTO.ID.Hex(),
 		Title:        blogDTO.Title,
 		Content:      blogDTO.Content,
 		AuthorID:     blogDTO.AuthorID.Hex(),
 		Tags:         blogDTO.Tags,
+		Status:       status,
+		PublishAt:    blogDTO.PublishAt,
+		PublishedAt:  blogDTO.PublishedAt,
 		CreatedAt:    blogDTO.CreatedAt,
 		UpdatedAt:    blogDTO.UpdatedAt,
 		ViewCount:    blogDTO.ViewCount,
 		LikeCount:    blogDTO.LikeCount,
 		DislikeCount: blogDTO.DislikeCount,
 		CommentCount: blogDTO.CommentCount,
+		SaveCount:    blogDTO.SaveCount,
+		ReactionCounts: reactionCountsToDomain(blogDTO.ReactionCounts, blogDTO.LikeCount, blogDTO.DislikeCount),
+		Hidden:       blogDTO.Hidden,
+	}
+}
+
+// reactionCountsToDomain converts the stored per type counts. Blogs from
+// before per type counts existed only have the like and dislike counters.
+func reactionCountsToDomain(stored map[string]int, likes, dislikes int) map[domain.ReactionType]int {
+	counts := make(map[domain.ReactionType]int, len(stored)+2)
+	for reactionType, count := range stored {
+		if count > 0 {
+			counts[domain.ReactionType(reactionType)] = count
+		}
+	}
+	// a blog not yet backfilled by EnsureIndexes only has the legacy counters
+	if _, ok := stored[string(domain.Like)]; !ok && likes > 0 {
+		counts[domain.Like] = likes
+	}
+	if _, ok := stored[string(domain.Dislike)]; !ok && dislikes > 0 {
+		counts[domain.Dislike] = dislikes
 	}
+	return counts
 }
 
 type HistoryDTO struct{
-	UserID 		string   `bson: "user_id" binding: "required"` 
-	BlogID		 string 	`bson: "blog_id" binding: "required"`
+	UserID 		string   `bson:"user_id" binding:"required"` 
+	BlogID		 string 	`bson:"blog_id" binding:"required"`
 	CreatedAt time.Time		`bson:"created_at"`
-	Tags     []domain.TagsCount	`bson: "tags"`
+	Tags     []domain.TagsCount	`bson:"tags"`
 }
@@ -0,0 +1,559 @@
+package repository
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type bookmarkRepository struct {
+	database           *mongo.Database
+	listCollection     string
+	bookmarkCollection string
+}
+
+func NewBookmarkRepositoryFromDB(db *mongo.Database) domain.IBookmarkRepository {
+	return &bookmarkRepository{
+		database:           db,
+		listCollection:     "reading_lists",
+		bookmarkCollection: "bookmarks",
+	}
+}
+
+func (r *bookmarkRepository) CreateList(ctx context.Context, list *domain.ReadingList) (*domain.ReadingList, error) {
+	collection := r.database.Collection(r.listCollection)
+	userID, err := bson.ObjectIDFromHex(list.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := readingListDTO{
+		UserID:      userID,
+		Name:        list.Name,
+		Description: list.Description,
+		IsDefault:   list.IsDefault,
+		CreatedAt:   list.CreatedAt,
+		UpdatedAt:   list.UpdatedAt,
+	}
+	insertedResult, err := collection.InsertOne(ctx, dto)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, domain.ErrReadingListExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	list.ID = insertedResult.InsertedID.(bson.ObjectID).Hex()
+	return list, nil
+}
+
+func (r *bookmarkRepository) GetList(ctx context.Context, userID, listID string) (*domain.ReadingList, error) {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	oid, err := bson.ObjectIDFromHex(listID)
+	if err != nil {
+		return nil, domain.ErrReadingListNotFound
+	}
+	return r.findList(ctx, bson.M{"_id": oid, "user_id": uid})
+}
+
+func (r *bookmarkRepository) GetDefaultList(ctx context.Context, userID string) (*domain.ReadingList, error) {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.findList(ctx, bson.M{"user_id": uid, "is_default": true})
+}
+
+func (r *bookmarkRepository) findList(ctx context.Context, filter bson.M) (*domain.ReadingList, error) {
+	collection := r.database.Collection(r.listCollection)
+	var dto readingListDTO
+	err := collection.FindOne(ctx, filter).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrReadingListNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return readingListDTOToDomain(&dto), nil
+}
+
+// GetLists returns the user's reading lists, the default one first.
+func (r *bookmarkRepository) GetLists(ctx context.Context, userID string) ([]*domain.ReadingList, error) {
+	collection := r.database.Collection(r.listCollection)
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "is_default", Value: -1}, {Key: "created_at", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"user_id": uid}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []readingListDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	lists := make([]*domain.ReadingList, len(dtos))
+	for i, dto := range dtos {
+		lists[i] = readingListDTOToDomain(&dto)
+	}
+	return lists, nil
+}
+
+func (r *bookmarkRepository) UpdateList(ctx context.Context, userID, listID string, updates map[string]interface{}) error {
+	collection := r.database.Collection(r.listCollection)
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	oid, err := bson.ObjectIDFromHex(listID)
+	if err != nil {
+		return domain.ErrReadingListNotFound
+	}
+
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": oid, "user_id": uid}, bson.M{"$set": updates})
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrReadingListExists
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrReadingListNotFound
+	}
+	return nil
+}
+
+func (r *bookmarkRepository) DeleteList(ctx context.Context, userID, listID string) error {
+	collection := r.database.Collection(r.listCollection)
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	oid, err := bson.ObjectIDFromHex(listID)
+	if err != nil {
+		return domain.ErrReadingListNotFound
+	}
+
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": oid, "user_id": uid})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrReadingListNotFound
+	}
+	return nil
+}
+
+func (r *bookmarkRepository) CountBookmarksByList(ctx context.Context, userID string) (map[string]int, error) {
+	collection := r.database.Collection(r.bookmarkCollection)
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": uid}}},
+		{{Key: "$group", Value: bson.M{"_id": "$list_id", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ListID bson.ObjectID `bson:"_id"`
+		Count  int           `bson:"count"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.ListID.Hex()] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *bookmarkRepository) AddBookmark(ctx context.Context, bookmark *domain.Bookmark) (*domain.Bookmark, error) {
+	collection := r.database.Collection(r.bookmarkCollection)
+	dto, err := bookmarkDomainToDTO(bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	insertedResult, err := collection.InsertOne(ctx, dto)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, domain.ErrAlreadyBookmarked
+	}
+	if err != nil {
+		return nil, err
+	}
+	bookmark.ID = insertedResult.InsertedID.(bson.ObjectID).Hex()
+	return bookmark, nil
+}
+
+func (r *bookmarkRepository) GetBookmark(ctx context.Context, userID, blogID string) (*domain.Bookmark, error) {
+	collection := r.database.Collection(r.bookmarkCollection)
+	filter, err := bookmarkFilter(userID, blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	var dto bookmarkDTO
+	err = collection.FindOne(ctx, filter).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrBookmarkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bookmarkDTOToDomain(&dto), nil
+}
+
+// GetBookmarks returns one page of a reading list in its saved order.
+func (r *bookmarkRepository) GetBookmarks(ctx context.Context, listID string, cursorToken string, limit int) ([]*domain.Bookmark, string, error) {
+	limit = normalizeLimit(limit)
+	collection := r.database.Collection(r.bookmarkCollection)
+	oid, err := bson.ObjectIDFromHex(listID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	filter := bson.M{"list_id": oid}
+	findOptions, err := paginate(filter, cursorToken, "position", 1, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []bookmarkDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(dtos) > limit {
+		dtos = dtos[:limit]
+		last := dtos[limit-1]
+		next = encodePageCursor(last.Position, last.ID)
+	}
+	bookmarks := make([]*domain.Bookmark, len(dtos))
+	for i, dto := range dtos {
+		bookmarks[i] = bookmarkDTOToDomain(&dto)
+	}
+	return bookmarks, next, nil
+}
+
+func (r *bookmarkRepository) GetListBlogIDs(ctx context.Context, listID string) ([]string, error) {
+	collection := r.database.Collection(r.bookmarkCollection)
+	oid, err := bson.ObjectIDFromHex(listID)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().SetProjection(bson.M{"blog_id": 1})
+	cursor, err := collection.Find(ctx, bson.M{"list_id": oid}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		BlogID bson.ObjectID `bson:"blog_id"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.BlogID.Hex()
+	}
+	return ids, nil
+}
+
+// NextPosition takes the next value of the list's position counter. The
+// counter only grows, so positions may have gaps but never repeat.
+func (r *bookmarkRepository) NextPosition(ctx context.Context, listID string) (int, error) {
+	collection := r.database.Collection(r.listCollection)
+	oid, err := bson.ObjectIDFromHex(listID)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := bson.M{"_id": oid, "next_position": bson.M{"$exists": true}}
+	update := bson.M{"$inc": bson.M{"next_position": 1}}
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	var dto readingListDTO
+	err = collection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		// lists created before the counter existed start it after their last bookmark
+		if err := r.seedNextPosition(ctx, oid); err != nil {
+			return 0, err
+		}
+		err = collection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(&dto)
+	}
+	if err == mongo.ErrNoDocuments {
+		return 0, domain.ErrReadingListNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return dto.NextPosition, nil
+}
+
+func (r *bookmarkRepository) seedNextPosition(ctx context.Context, listID bson.ObjectID) error {
+	next := 0
+	var last bookmarkDTO
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "position", Value: -1}})
+	err := r.database.Collection(r.bookmarkCollection).FindOne(ctx, bson.M{"list_id": listID}, findOptions).Decode(&last)
+	if err == nil {
+		next = last.Position + 1
+	} else if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	// a concurrent caller may have seeded it already, which is fine
+	_, err = r.database.Collection(r.listCollection).UpdateOne(ctx,
+		bson.M{"_id": listID, "next_position": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"next_position": next}})
+	return err
+}
+
+func (r *bookmarkRepository) UpdateBookmark(ctx context.Context, userID, blogID string, updates map[string]interface{}) error {
+	collection := r.database.Collection(r.bookmarkCollection)
+	filter, err := bookmarkFilter(userID, blogID)
+	if err != nil {
+		return err
+	}
+	if listID, ok := updates["list_id"].(string); ok {
+		oid, err := bson.ObjectIDFromHex(listID)
+		if err != nil {
+			return err
+		}
+		updates["list_id"] = oid
+	}
+
+	res, err := collection.UpdateOne(ctx, filter, bson.M{"$set": updates})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrBookmarkNotFound
+	}
+	return nil
+}
+
+func (r *bookmarkRepository) SetPositions(ctx context.Context, listID string, blogIDs []string) error {
+	collection := r.database.Collection(r.bookmarkCollection)
+	oid, err := bson.ObjectIDFromHex(listID)
+	if err != nil {
+		return err
+	}
+	if len(blogIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, len(blogIDs))
+	for i, blogID := range blogIDs {
+		bid, err := bson.ObjectIDFromHex(blogID)
+		if err != nil {
+			return err
+		}
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"list_id": oid, "blog_id": bid}).
+			SetUpdate(bson.M{"$set": bson.M{"position": i, "updated_at": now}})
+	}
+	_, err = collection.BulkWrite(ctx, models)
+	return err
+}
+
+func (r *bookmarkRepository) DeleteBookmark(ctx context.Context, userID, blogID string) error {
+	collection := r.database.Collection(r.bookmarkCollection)
+	filter, err := bookmarkFilter(userID, blogID)
+	if err != nil {
+		return err
+	}
+
+	res, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrBookmarkNotFound
+	}
+	return nil
+}
+
+func (r *bookmarkRepository) DeleteBookmarksInList(ctx context.Context, listID string) ([]*domain.Bookmark, error) {
+	oid, err := bson.ObjectIDFromHex(listID)
+	if err != nil {
+		return nil, err
+	}
+	return r.deleteBookmarks(ctx, bson.M{"list_id": oid})
+}
+
+func (r *bookmarkRepository) DeleteBookmarksForBlog(ctx context.Context, blogID string) ([]*domain.Bookmark, error) {
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+	return r.deleteBookmarks(ctx, bson.M{"blog_id": oid})
+}
+
+func (r *bookmarkRepository) DeleteBookmarksForUser(ctx context.Context, userID string) ([]*domain.Bookmark, error) {
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.deleteBookmarks(ctx, bson.M{"user_id": oid})
+}
+
+// DeleteListsForUser removes all of the user's reading lists, the default one
+// included. Only used once the user's bookmarks are gone.
+func (r *bookmarkRepository) DeleteListsForUser(ctx context.Context, userID string) error {
+	collection := r.database.Collection(r.listCollection)
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, bson.M{"user_id": oid})
+	return err
+}
+
+func (r *bookmarkRepository) CountBookmarksForBlog(ctx context.Context, blogID string) (int64, error) {
+	collection := r.database.Collection(r.bookmarkCollection)
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return 0, err
+	}
+	return collection.CountDocuments(ctx, bson.M{"blog_id": oid})
+}
+
+func (r *bookmarkRepository) deleteBookmarks(ctx context.Context, filter bson.M) ([]*domain.Bookmark, error) {
+	collection := r.database.Collection(r.bookmarkCollection)
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var dtos []bookmarkDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	if len(dtos) == 0 {
+		return []*domain.Bookmark{}, nil
+	}
+
+	// delete exactly what was read so the returned bookmarks match what is gone
+	ids := make([]bson.ObjectID, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.ID
+	}
+	if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return nil, err
+	}
+
+	bookmarks := make([]*domain.Bookmark, len(dtos))
+	for i, dto := range dtos {
+		bookmarks[i] = bookmarkDTOToDomain(&dto)
+	}
+	return bookmarks, nil
+}
+
+func bookmarkFilter(userID, blogID string) (bson.M, error) {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	bid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, domain.ErrBookmarkNotFound
+	}
+	return bson.M{"user_id": uid, "blog_id": bid}, nil
+}
+
+type readingListDTO struct {
+	ID           bson.ObjectID `bson:"_id,omitempty"`
+	UserID       bson.ObjectID `bson:"user_id"`
+	Name         string        `bson:"name"`
+	Description  string        `bson:"description"`
+	IsDefault    bool          `bson:"is_default"`
+	NextPosition int           `bson:"next_position"`
+	CreatedAt    time.Time     `bson:"created_at"`
+	UpdatedAt    time.Time     `bson:"updated_at"`
+}
+
+func readingListDTOToDomain(dto *readingListDTO) *domain.ReadingList {
+	return &domain.ReadingList{
+		ID:          dto.ID.Hex(),
+		UserID:      dto.UserID.Hex(),
+		Name:        dto.Name,
+		Description: dto.Description,
+		IsDefault:   dto.IsDefault,
+		CreatedAt:   dto.CreatedAt,
+		UpdatedAt:   dto.UpdatedAt,
+	}
+}
+
+type bookmarkDTO struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	UserID    bson.ObjectID `bson:"user_id"`
+	BlogID    bson.ObjectID `bson:"blog_id"`
+	ListID    bson.ObjectID `bson:"list_id"`
+	Position  int           `bson:"position"`
+	Note      string        `bson:"note"`
+	CreatedAt time.Time     `bson:"created_at"`
+	UpdatedAt time.Time     `bson:"updated_at"`
+}
+
+func bookmarkDomainToDTO(bookmark *domain.Bookmark) (*bookmarkDTO, error) {
+	userID, err := bson.ObjectIDFromHex(bookmark.UserID)
+	if err != nil {
+		return nil, err
+	}
+	blogID, err := bson.ObjectIDFromHex(bookmark.BlogID)
+	if err != nil {
+		return nil, err
+	}
+	listID, err := bson.ObjectIDFromHex(bookmark.ListID)
+	if err != nil {
+		return nil, err
+	}
+	return &bookmarkDTO{
+		UserID:    userID,
+		BlogID:    blogID,
+		ListID:    listID,
+		Position:  bookmark.Position,
+		Note:      bookmark.Note,
+		CreatedAt: bookmark.CreatedAt,
+		UpdatedAt: bookmark.UpdatedAt,
+	}, nil
+}
+
+func bookmarkDTOToDomain(dto *bookmarkDTO) *domain.Bookmark {
+	return &domain.Bookmark{
+		ID:        dto.ID.Hex(),
+		UserID:    dto.UserID.Hex(),
+		BlogID:    dto.BlogID.Hex(),
+		ListID:    dto.ListID.Hex(),
+		Position:  dto.Position,
+		Note:      dto.Note,
+		CreatedAt: dto.CreatedAt,
+		UpdatedAt: dto.UpdatedAt,
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type commentRepository struct {
@@ -23,18 +24,84 @@ func NewCommentRepositoryFromDB(db *mongo.Database) domain.ICommentRepository {
 
 func (cr *commentRepository) AddComment(ctx context.Context, comment *domain.Comment) (*domain.Comment, error) {
 	collection := cr.database.Collection(cr.collection)
-	_, err := collection.InsertOne(ctx, comment)
+	insertedResult, err := collection.InsertOne(ctx, CommentDomainToDto(comment))
 	if err != nil {
 		return nil, err
 	}
+	comment.ID = insertedResult.InsertedID.(bson.ObjectID).Hex()
 	return comment, nil
 }
 
-func (cr *commentRepository) GetCommentsForBlog(ctx context.Context, blogID string) ([]*domain.Comment, error) {
+func (cr *commentRepository) GetCommentByID(ctx context.Context, commentID string) (*domain.Comment, error) {
+	collection := cr.database.Collection(cr.collection)
+	oid, err := bson.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var dto CommentResDTO
+	err = collection.FindOne(ctx, notDeleted(bson.M{"_id": oid})).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return CommentDtoToDomain(&dto), nil
+}
+
+// GetCommentsForBlog returns one page of top-level comments, oldest first or
+// highest score first.
+func (cr *commentRepository) GetCommentsForBlog(ctx context.Context, blogID string, sort domain.CommentSort, cursorToken string, limit int) ([]*domain.Comment, string, error) {
+	limit = normalizeLimit(limit)
 	collection := cr.database.Collection(cr.collection)
 	
-	filter := bson.M{"blogid": blogID}
-	cursor, err := collection.Find(ctx, filter)
+	filter := notDeleted(bson.M{"blogid": blogID, "parent_id": bson.M{"$in": []interface{}{nil, ""}}})
+	field, direction := "created_at", 1
+	if sort == domain.CommentSortTop {
+		field, direction = "score", -1
+	}
+	findOptions, err := paginate(filter, cursorToken, field, direction, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+	var commentResDTO []CommentResDTO
+	if err = cursor.All(ctx, &commentResDTO); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(commentResDTO) > limit {
+		commentResDTO = commentResDTO[:limit]
+		last := commentResDTO[limit-1]
+		if sort == domain.CommentSortTop {
+			next = encodePageCursor(last.Score, last.ID)
+		} else {
+			next = encodePageCursor(last.CreatedAt, last.ID)
+		}
+	}
+	comments := make([]*domain.Comment, len(commentResDTO))
+	for i, dto := range commentResDTO {
+		comments[i] = CommentDtoToDomain(&dto)
+	}
+	return comments, next, nil
+}
+
+// GetReplies returns every reply below the given top-level comments, oldest first.
+func (cr *commentRepository) GetReplies(ctx context.Context, rootIDs []string) ([]*domain.Comment, error) {
+	collection := cr.database.Collection(cr.collection)
+	if len(rootIDs) == 0 {
+		return []*domain.Comment{}, nil
+	}
+
+	filter := notDeleted(bson.M{"ancestors": bson.M{"$in": rootIDs}})
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -50,26 +117,432 @@ func (cr *commentRepository) GetCommentsForBlog(ctx context.Context, blogID stri
 	return comments, nil
 }
 
-func (cr *commentRepository) DeleteComment(ctx context.Context, commentID string) error {
+func (cr *commentRepository) UpdateCommentContent(ctx context.Context, commentID, content string) error {
+	collection := cr.database.Collection(cr.collection)
+	oid, err := bson.ObjectIDFromHex(commentID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"content":   content,
+		"edited":    true,
+		"edited_at": time.Now(),
+	}}
+	res, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+func (cr *commentRepository) SetCommentHidden(ctx context.Context, commentID string, hidden bool) error {
+	collection := cr.database.Collection(cr.collection)
+	oid, err := bson.ObjectIDFromHex(commentID)
+	if err != nil {
+		return domain.ErrCommentNotFound
+	}
+
+	res, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), bson.M{"$set": bson.M{"hidden": hidden}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+func (cr *commentRepository) IncrementReplyCount(ctx context.Context, commentID string, increment int) error {
 	collection := cr.database.Collection(cr.collection)
 	oid, err := bson.ObjectIDFromHex(commentID)
 	if err != nil {
 		return err
 	}
-	_, err = collection.DeleteOne(ctx,bson.M{"_id":oid})
+	_, err = collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), bson.M{"$inc": bson.M{"reply_count": increment}})
 	return err
 }
+
+// DeleteComment soft deletes the comment and its live replies with one
+// shared timestamp, so RestoreComment can bring back exactly that thread.
+func (cr *commentRepository) DeleteComment(ctx context.Context, commentID string, deletedBy string) (int64, error) {
+	collection := cr.database.Collection(cr.collection)
+	oid, err := bson.ObjectIDFromHex(commentID)
+	if err != nil {
+		return 0, err
+	}
+	filter := notDeleted(bson.M{"$or": []bson.M{
+		{"_id": oid},
+		{"ancestors": commentID},
+	}})
+	res, err := collection.UpdateMany(ctx, filter, softDeleteUpdate(deletedBy, time.Now()))
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (cr *commentRepository) RestoreComment(ctx context.Context, blogID, commentID string) (*domain.Comment, int64, error) {
+	collection := cr.database.Collection(cr.collection)
+	oid, err := bson.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, 0, domain.ErrCommentNotFound
+	}
+
+	var dto struct {
+		CommentResDTO `bson:",inline"`
+		DeletedAt     time.Time `bson:"deleted_at"`
+	}
+	err = collection.FindOne(ctx, bson.M{"_id": oid, "blogid": blogID, "deleted_at": bson.M{"$ne": nil}}).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return nil, 0, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if dto.ParentID != "" {
+		if _, err := cr.GetCommentByID(ctx, dto.ParentID); err == domain.ErrCommentNotFound {
+			return nil, 0, domain.ErrParentCommentDeleted
+		} else if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// replies deleted on their own earlier carry another timestamp and stay deleted
+	filter := bson.M{
+		"deleted_at": dto.DeletedAt,
+		"$or": []bson.M{
+			{"_id": oid},
+			{"ancestors": commentID},
+		},
+	}
+	res, err := collection.UpdateMany(ctx, filter, restoreUpdate())
+	if err != nil {
+		return nil, 0, err
+	}
+	return CommentDtoToDomain(&dto.CommentResDTO), res.ModifiedCount, nil
+}
+
+func (cr *commentRepository) ListDeletedCommentIDs(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	collection := cr.database.Collection(cr.collection)
+	cursor, err := collection.Find(ctx, deletedBeforeFilter(before), deletedIDsOptions(limit))
+	if err != nil {
+		return nil, err
+	}
+	var dtos []idOnlyDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	return idsToHex(dtos), nil
+}
+
+// PurgeComments permanently removes the comments that are still soft deleted.
+func (cr *commentRepository) PurgeComments(ctx context.Context, ids []string) (int64, error) {
+	collection := cr.database.Collection(cr.collection)
+	oids, err := hexToObjectIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+	if len(oids) == 0 {
+		return 0, nil
+	}
+	res, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": oids}, "deleted_at": bson.M{"$ne": nil}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// DeleteCommentsForBlogs permanently removes every comment on the blogs.
+func (cr *commentRepository) DeleteCommentsForBlogs(ctx context.Context, blogIDs []string) (int64, error) {
+	if len(blogIDs) == 0 {
+		return 0, nil
+	}
+	collection := cr.database.Collection(cr.collection)
+	res, err := collection.DeleteMany(ctx, bson.M{"blogid": bson.M{"$in": blogIDs}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+func (cr *commentRepository) DeleteCommentsByAuthor(ctx context.Context, authorID string) (*domain.CommentRemoval, error) {
+	collection := cr.database.Collection(cr.collection)
+	findOptions := options.Find().SetProjection(bson.M{"_id": 1, "blogid": 1, "parent_id": 1})
+	cursor, err := collection.Find(ctx, bson.M{"authorid": authorID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	var dtos []CommentResDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	removal := &domain.CommentRemoval{CommentIDs: []string{}, BlogIDs: []string{}, ParentIDs: []string{}}
+	if len(dtos) == 0 {
+		return removal, nil
+	}
+
+	ids := make([]string, len(dtos))
+	blogs := map[string]bool{}
+	for i, dto := range dtos {
+		ids[i] = dto.ID.Hex()
+		blogs[dto.BlogID] = true
+	}
+	filter := bson.M{"$or": []bson.M{
+		{"authorid": authorID},
+		{"ancestors": bson.M{"$in": ids}},
+	}}
+	// replies by other users go with the threads, so collect them too
+	cursor, err = collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var removedDTOs []idOnlyDTO
+	if err = cursor.All(ctx, &removedDTOs); err != nil {
+		return nil, err
+	}
+	if _, err := collection.DeleteMany(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	removal.CommentIDs = idsToHex(removedDTOs)
+	removed := make(map[string]bool, len(removal.CommentIDs))
+	for _, id := range removal.CommentIDs {
+		removed[id] = true
+	}
+	for id := range blogs {
+		removal.BlogIDs = append(removal.BlogIDs, id)
+	}
+	// parents inside a removed thread are gone themselves
+	seen := map[string]bool{}
+	for _, dto := range dtos {
+		if dto.ParentID != "" && !removed[dto.ParentID] && !seen[dto.ParentID] {
+			seen[dto.ParentID] = true
+			removal.ParentIDs = append(removal.ParentIDs, dto.ParentID)
+		}
+	}
+	return removal, nil
+}
+
+func (cr *commentRepository) SoftDeleteCommentsByAuthor(ctx context.Context, authorID, deletedBy string, at time.Time) (*domain.CommentRemoval, error) {
+	return cr.updateThreadsOf(ctx, authorID, bson.M{"deleted_at": nil}, softDeleteUpdate(deletedBy, at))
+}
+
+func (cr *commentRepository) RestoreCommentsByAuthor(ctx context.Context, authorID string, at time.Time) (*domain.CommentRemoval, error) {
+	return cr.updateThreadsOf(ctx, authorID, bson.M{"deleted_at": at}, restoreUpdate())
+}
+
+// updateThreadsOf applies update to the author's comments matching state and
+// to the replies below them in the same state.
+func (cr *commentRepository) updateThreadsOf(ctx context.Context, authorID string, state bson.M, update bson.M) (*domain.CommentRemoval, error) {
+	collection := cr.database.Collection(cr.collection)
+	projection := options.Find().SetProjection(bson.M{"_id": 1, "blogid": 1, "parent_id": 1})
+
+	authorFilter := bson.M{"authorid": authorID}
+	for key, value := range state {
+		authorFilter[key] = value
+	}
+	cursor, err := collection.Find(ctx, authorFilter, projection)
+	if err != nil {
+		return nil, err
+	}
+	var own []idOnlyDTO
+	if err = cursor.All(ctx, &own); err != nil {
+		return nil, err
+	}
+	removal := &domain.CommentRemoval{CommentIDs: []string{}, BlogIDs: []string{}, ParentIDs: []string{}}
+	if len(own) == 0 {
+		return removal, nil
+	}
+
+	filter := bson.M{"$or": []bson.M{
+		{"authorid": authorID},
+		{"ancestors": bson.M{"$in": idsToHex(own)}},
+	}}
+	for key, value := range state {
+		filter[key] = value
+	}
+	cursor, err = collection.Find(ctx, filter, projection)
+	if err != nil {
+		return nil, err
+	}
+	var dtos []CommentResDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	if _, err := collection.UpdateMany(ctx, filter, update); err != nil {
+		return nil, err
+	}
+
+	touched := make(map[string]bool, len(dtos))
+	for _, dto := range dtos {
+		touched[dto.ID.Hex()] = true
+		removal.CommentIDs = append(removal.CommentIDs, dto.ID.Hex())
+	}
+	blogs := map[string]bool{}
+	parents := map[string]bool{}
+	for _, dto := range dtos {
+		if !blogs[dto.BlogID] {
+			blogs[dto.BlogID] = true
+			removal.BlogIDs = append(removal.BlogIDs, dto.BlogID)
+		}
+		if dto.ParentID != "" && !touched[dto.ParentID] && !parents[dto.ParentID] {
+			parents[dto.ParentID] = true
+			removal.ParentIDs = append(removal.ParentIDs, dto.ParentID)
+		}
+	}
+	return removal, nil
+}
+
+func (cr *commentRepository) TransferComments(ctx context.Context, fromAuthorID, toAuthorID string) (int64, error) {
+	collection := cr.database.Collection(cr.collection)
+	res, err := collection.UpdateMany(ctx, bson.M{"authorid": fromAuthorID}, bson.M{"$set": bson.M{"authorid": toAuthorID}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (cr *commentRepository) CountComments(ctx context.Context, blogID string) (int64, error) {
+	collection := cr.database.Collection(cr.collection)
+	return collection.CountDocuments(ctx, notDeleted(bson.M{"blogid": blogID}))
+}
+
+func (cr *commentRepository) CountReplies(ctx context.Context, commentID string) (int64, error) {
+	collection := cr.database.Collection(cr.collection)
+	return collection.CountDocuments(ctx, notDeleted(bson.M{"parent_id": commentID}))
+}
+
+func (cr *commentRepository) SetReplyCount(ctx context.Context, commentID string, count int) error {
+	collection := cr.database.Collection(cr.collection)
+	oid, err := bson.ObjectIDFromHex(commentID)
+	if err != nil {
+		return domain.ErrCommentNotFound
+	}
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": bson.M{"reply_count": count}})
+	return err
+}
+
+func (cr *commentRepository) IncrementReactionCounts(ctx context.Context, commentID string, increments map[domain.ReactionType]int) error {
+	collection := cr.database.Collection(cr.collection)
+	oid, err := bson.ObjectIDFromHex(commentID)
+	if err != nil {
+		return domain.ErrCommentNotFound
+	}
+	inc := bson.M{}
+	score := 0
+	for reactionType, increment := range increments {
+		if increment == 0 {
+			continue
+		}
+		inc["reaction_counts."+string(reactionType)] = increment
+		score += commentScore(reactionType, increment)
+	}
+	if len(inc) == 0 {
+		return nil
+	}
+	if score != 0 {
+		inc["score"] = score
+	}
+	res, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), bson.M{"$inc": inc})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+func (cr *commentRepository) SetReactionCounts(ctx context.Context, commentID string, counts map[domain.ReactionType]int) error {
+	collection := cr.database.Collection(cr.collection)
+	oid, err := bson.ObjectIDFromHex(commentID)
+	if err != nil {
+		return domain.ErrCommentNotFound
+	}
+	stored := make(map[string]int, len(counts))
+	score := 0
+	for reactionType, count := range counts {
+		if count > 0 {
+			stored[string(reactionType)] = count
+			score += commentScore(reactionType, count)
+		}
+	}
+	update := bson.M{"$set": bson.M{"reaction_counts": stored, "score": score}}
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+// commentScore is what count reactions of one type add to a comment's score.
+func commentScore(reactionType domain.ReactionType, count int) int {
+	if reactionType == domain.Dislike {
+		return -count
+	}
+	return count
+}
+
+func (cr *commentRepository) ListReactionCounters(ctx context.Context, afterID string, limit int) ([]*domain.ReactionCounters, error) {
+	collection := cr.database.Collection(cr.collection)
+	filter := bson.M{}
+	if afterID != "" {
+		oid, err := bson.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, domain.ErrInvalidCursor
+		}
+		filter["_id"] = bson.M{"$gt": oid}
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1, "reaction_counts": 1})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	var dtos []struct {
+		ID             bson.ObjectID  `bson:"_id"`
+		ReactionCounts map[string]int `bson:"reaction_counts"`
+	}
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	counters := make([]*domain.ReactionCounters, len(dtos))
+	for i, dto := range dtos {
+		counts := make(map[domain.ReactionType]int, len(dto.ReactionCounts))
+		for reactionType, count := range dto.ReactionCounts {
+			counts[domain.ReactionType(reactionType)] = count
+		}
+		counters[i] = &domain.ReactionCounters{
+			ID:           dto.ID.Hex(),
+			Counts:       counts,
+			LikeCount:    counts[domain.Like],
+			DislikeCount: counts[domain.Dislike],
+		}
+	}
+	return counters, nil
+}
+
 func (cr *commentRepository) IsComAuthor(ctx context.Context, comId, userId string) (bool,error) {
 	collection := cr.database.Collection(cr.collection)
 	oid, err := bson.ObjectIDFromHex(comId)
 	if err != nil {
 		return false, err
 	}
-	ouid,err := bson.ObjectIDFromHex(userId)
-	if err != nil {
+	if _, err := bson.ObjectIDFromHex(userId); err != nil {
 		return false, err
 	}
-	filter := bson.M{"_id": oid, "authorid": ouid}
+	// author ids are stored as the hex string the comment was created with
+	filter := notDeleted(bson.M{"_id": oid, "authorid": userId})
 	count, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return false, err
@@ -77,18 +550,71 @@ func (cr *commentRepository) IsComAuthor(ctx context.Context, comId, userId stri
 
 	return count > 0, nil
 }
+
+type CommentDTO struct {
+	BlogID     string    `bson:"blogid"`
+	AuthorID   string    `bson:"authorid"`
+	ParentID   string    `bson:"parent_id,omitempty"`
+	Ancestors  []string  `bson:"ancestors"`
+	Depth      int       `bson:"depth"`
+	ReplyCount int       `bson:"reply_count"`
+	Content    string    `bson:"content"`
+	Edited     bool      `bson:"edited"`
+	EditedAt   time.Time `bson:"edited_at,omitempty"`
+	Score      int       `bson:"score"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
 type CommentResDTO struct {
+	ID         bson.ObjectID `bson:"_id" json:"id"`
 	BlogID    string `bson:"blogid" json:"blogid"`
 	AuthorID  string `bson:"authorid" json:"authorid"`
+	ParentID   string    `bson:"parent_id" json:"parent_id"`
+	Ancestors  []string  `bson:"ancestors" json:"ancestors"`
+	Depth      int       `bson:"depth" json:"depth"`
+	ReplyCount int       `bson:"reply_count" json:"reply_count"`
 	Content   string `bson:"content" json:"content"`
+	Edited     bool      `bson:"edited" json:"edited"`
+	EditedAt   time.Time `bson:"edited_at" json:"edited_at"`
+	Hidden     bool      `bson:"hidden" json:"hidden"`
+	ReactionCounts map[string]int `bson:"reaction_counts" json:"reaction_counts"`
+	Score      int       `bson:"score" json:"score"`
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 }
   func CommentDtoToDomain(dto *CommentResDTO) *domain.Comment {
 	return &domain.Comment{
+		ID:         dto.ID.Hex(),
 		BlogID:    dto.BlogID,
 		AuthorID:  dto.AuthorID,
+		ParentID:   dto.ParentID,
+		Ancestors:  dto.Ancestors,
+		Depth:      dto.Depth,
+		ReplyCount: dto.ReplyCount,
 		Content:   dto.Content,
+		Edited:     dto.Edited,
+		EditedAt:   dto.EditedAt,
+		Hidden:     dto.Hidden,
+		ReactionCounts: reactionCountsToDomain(dto.ReactionCounts, 0, 0),
+		Score:      dto.Score,
 		CreatedAt: dto.CreatedAt,
 	}
 }
 
+func CommentDomainToDto(comment *domain.Comment) *CommentDTO {
+	ancestors := comment.Ancestors
+	if ancestors == nil {
+		ancestors = []string{}
+	}
+	return &CommentDTO{
+		BlogID:     comment.BlogID,
+		AuthorID:   comment.AuthorID,
+		ParentID:   comment.ParentID,
+		Ancestors:  ancestors,
+		Depth:      comment.Depth,
+		ReplyCount: comment.ReplyCount,
+		Content:    comment.Content,
+		Edited:     comment.Edited,
+		EditedAt:   comment.EditedAt,
+		CreatedAt:  comment.CreatedAt,
+	}
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type followRepository struct {
+	database   *mongo.Database
+	collection string
+}
+
+func NewFollowRepositoryFromDB(db *mongo.Database) domain.IFollowRepository {
+	return &followRepository{
+		database:   db,
+		collection: "follows",
+	}
+}
+
+func (fr *followRepository) Follow(ctx context.Context, follow *domain.Follow) error {
+	collection := fr.database.Collection(fr.collection)
+	filter, err := followFilter(follow.FollowerID, follow.FolloweeID)
+	if err != nil {
+		return err
+	}
+
+	dto := followDTO{
+		FollowerID: filter["follower_id"].(bson.ObjectID),
+		FolloweeID: filter["followee_id"].(bson.ObjectID),
+		CreatedAt:  follow.CreatedAt,
+	}
+	_, err = collection.InsertOne(ctx, dto)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrAlreadyFollowing
+	}
+	return err
+}
+
+func (fr *followRepository) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	collection := fr.database.Collection(fr.collection)
+	filter, err := followFilter(followerID, followeeID)
+	if err != nil {
+		return err
+	}
+
+	res, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFollowing
+	}
+	return nil
+}
+
+func (fr *followRepository) IsFollowing(ctx context.Context, followerID, followeeID string) (bool, error) {
+	collection := fr.database.Collection(fr.collection)
+	filter, err := followFilter(followerID, followeeID)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (fr *followRepository) GetFollowers(ctx context.Context, userID string, cursor string, limit int) ([]*domain.Follow, string, error) {
+	return fr.listFollows(ctx, "followee_id", userID, cursor, limit)
+}
+
+func (fr *followRepository) GetFollowing(ctx context.Context, userID string, cursor string, limit int) ([]*domain.Follow, string, error) {
+	return fr.listFollows(ctx, "follower_id", userID, cursor, limit)
+}
+
+func (fr *followRepository) listFollows(ctx context.Context, field, userID string, cursorToken string, limit int) ([]*domain.Follow, string, error) {
+	limit = normalizeLimit(limit)
+	collection := fr.database.Collection(fr.collection)
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	filter := bson.M{field: oid}
+	findOptions, err := paginate(filter, cursorToken, "created_at", -1, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []followDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(dtos) > limit {
+		dtos = dtos[:limit]
+		last := dtos[limit-1]
+		next = encodePageCursor(last.CreatedAt, last.ID)
+	}
+	follows := make([]*domain.Follow, len(dtos))
+	for i, dto := range dtos {
+		follows[i] = followDTOToDomain(&dto)
+	}
+	return follows, next, nil
+}
+
+func (fr *followRepository) GetFollowingIDs(ctx context.Context, userID string) ([]string, error) {
+	collection := fr.database.Collection(fr.collection)
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().SetProjection(bson.M{"followee_id": 1})
+	cursor, err := collection.Find(ctx, bson.M{"follower_id": oid}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []followDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.FolloweeID.Hex()
+	}
+	return ids, nil
+}
+
+func (fr *followRepository) DeleteFollowsOfUser(ctx context.Context, userID string) ([]string, error) {
+	collection := fr.database.Collection(fr.collection)
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"$or": []bson.M{{"follower_id": oid}, {"followee_id": oid}}}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var dtos []followDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	if _, err := collection.DeleteMany(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	others := make([]string, 0, len(dtos))
+	for _, dto := range dtos {
+		if dto.FollowerID == oid {
+			others = append(others, dto.FolloweeID.Hex())
+		} else {
+			others = append(others, dto.FollowerID.Hex())
+		}
+	}
+	return others, nil
+}
+
+func (fr *followRepository) CountFollowers(ctx context.Context, userID string) (int64, error) {
+	return fr.count(ctx, "followee_id", userID)
+}
+
+func (fr *followRepository) CountFollowing(ctx context.Context, userID string) (int64, error) {
+	return fr.count(ctx, "follower_id", userID)
+}
+
+func (fr *followRepository) count(ctx context.Context, field, userID string) (int64, error) {
+	collection := fr.database.Collection(fr.collection)
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, err
+	}
+	return collection.CountDocuments(ctx, bson.M{field: oid})
+}
+
+func followFilter(followerID, followeeID string) (bson.M, error) {
+	follower, err := bson.ObjectIDFromHex(followerID)
+	if err != nil {
+		return nil, err
+	}
+	followee, err := bson.ObjectIDFromHex(followeeID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return bson.M{"follower_id": follower, "followee_id": followee}, nil
+}
+
+type followDTO struct {
+	ID         bson.ObjectID `bson:"_id,omitempty"`
+	FollowerID bson.ObjectID `bson:"follower_id"`
+	FolloweeID bson.ObjectID `bson:"followee_id"`
+	CreatedAt  time.Time     `bson:"created_at"`
+}
+
+func followDTOToDomain(dto *followDTO) *domain.Follow {
+	return &domain.Follow{
+		FollowerID: dto.FollowerID.Hex(),
+		FolloweeID: dto.FolloweeID.Hex(),
+		CreatedAt:  dto.CreatedAt,
+	}
+}
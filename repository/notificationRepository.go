@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// recentActorCount is how many of the latest actors a notification shows.
+	recentActorCount = 3
+	// storedActorCount caps the actor list kept on a notification so popular
+	// posts cannot grow it without bound; actor_count keeps the full tally.
+	storedActorCount = 50
+)
+
+type notificationRepository struct {
+	database              *mongo.Database
+	collection            string
+	preferencesCollection string
+}
+
+func NewNotificationRepositoryFromDB(db *mongo.Database) domain.INotificationRepository {
+	return &notificationRepository{
+		database:              db,
+		collection:            "notifications",
+		preferencesCollection: "notification_preferences",
+	}
+}
+
+// notificationProjection returns the actor count and the latest actors
+// instead of the stored actor list. Notifications from before actor_count
+// fall back to the size of their list.
+func notificationProjection() bson.M {
+	return bson.M{
+		"user_id":       1,
+		"type":          1,
+		"target_id":     1,
+		"preview":       1,
+		"read":          1,
+		"created_at":    1,
+		"updated_at":    1,
+		"actor_count":   bson.M{"$ifNull": bson.A{"$actor_count", bson.M{"$size": "$actor_ids"}}},
+		"recent_actors": bson.M{"$slice": bson.A{"$actor_ids", -recentActorCount}},
+	}
+}
+
+func (nr *notificationRepository) AddEvent(ctx context.Context, event *domain.NotificationEvent, at time.Time) (*domain.Notification, error) {
+	collection := nr.database.Collection(nr.collection)
+	userID, err := bson.ObjectIDFromHex(event.RecipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"user_id":   userID,
+		"type":      string(event.Type),
+		"target_id": event.TargetID,
+		"read":      false,
+	}
+	// re-adding an actor moves them to the end so they count as the latest;
+	// only actors not already in the list add to the count, so one who has
+	// dropped off the capped list is counted again if they come back
+	actorIDs := bson.M{"$ifNull": bson.A{"$actor_ids", bson.A{}}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"actor_count": bson.M{"$add": bson.A{
+				bson.M{"$ifNull": bson.A{"$actor_count", bson.M{"$size": actorIDs}}},
+				bson.M{"$cond": bson.A{bson.M{"$in": bson.A{event.ActorID, actorIDs}}, 0, 1}},
+			}},
+			"actor_ids": bson.M{"$slice": bson.A{
+				bson.M{"$concatArrays": bson.A{
+					bson.M{"$filter": bson.M{
+						"input": actorIDs,
+						"cond":  bson.M{"$ne": bson.A{"$$this", event.ActorID}},
+					}},
+					bson.A{event.ActorID},
+				}},
+				-storedActorCount,
+			}},
+			"preview":    event.Preview,
+			"updated_at": at,
+			"created_at": bson.M{"$ifNull": bson.A{"$created_at", at}},
+		}}},
+	}
+	findOptions := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After).
+		SetProjection(notificationProjection())
+
+	var dto notificationDTO
+	err = collection.FindOneAndUpdate(ctx, filter, update, findOptions).Decode(&dto)
+	if mongo.IsDuplicateKeyError(err) {
+		// lost an upsert race with a concurrent event; the group exists now
+		err = collection.FindOneAndUpdate(ctx, filter, update, findOptions).Decode(&dto)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return notificationDTOToDomain(&dto), nil
+}
+
+// GetNotifications pages through the user's notifications, most recently
+// active first.
+func (nr *notificationRepository) GetNotifications(ctx context.Context, userID string, unreadOnly bool, cursorToken string, limit int) ([]*domain.Notification, string, error) {
+	limit = normalizeLimit(limit)
+	collection := nr.database.Collection(nr.collection)
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	filter := bson.M{"user_id": oid}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	findOptions, err := paginate(filter, cursorToken, "updated_at", -1, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	cursor, err := collection.Find(ctx, filter, findOptions.SetProjection(notificationProjection()))
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []notificationDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(dtos) > limit {
+		dtos = dtos[:limit]
+		last := dtos[limit-1]
+		next = encodePageCursor(last.UpdatedAt, last.ID)
+	}
+	notifications := make([]*domain.Notification, len(dtos))
+	for i, dto := range dtos {
+		notifications[i] = notificationDTOToDomain(&dto)
+	}
+	return notifications, next, nil
+}
+
+func (nr *notificationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	collection := nr.database.Collection(nr.collection)
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, err
+	}
+	return collection.CountDocuments(ctx, bson.M{"user_id": oid, "read": false})
+}
+
+func (nr *notificationRepository) MarkRead(ctx context.Context, userID, notificationID string) error {
+	collection := nr.database.Collection(nr.collection)
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	oid, err := bson.ObjectIDFromHex(notificationID)
+	if err != nil {
+		return domain.ErrNotificationNotFound
+	}
+
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": oid, "user_id": uid}, bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (nr *notificationRepository) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	collection := nr.database.Collection(nr.collection)
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := collection.UpdateMany(ctx, bson.M{"user_id": uid, "read": false}, bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// GetPreferences returns the user's saved preferences, or the defaults
+// (nothing muted) if they never changed them.
+func (nr *notificationRepository) GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	collection := nr.database.Collection(nr.preferencesCollection)
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var dto notificationPreferencesDTO
+	err = collection.FindOne(ctx, bson.M{"_id": uid}).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return &domain.NotificationPreferences{UserID: userID, Muted: []domain.NotificationType{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	muted := make([]domain.NotificationType, len(dto.Muted))
+	for i, t := range dto.Muted {
+		muted[i] = domain.NotificationType(t)
+	}
+	return &domain.NotificationPreferences{UserID: userID, Muted: muted, UpdatedAt: dto.UpdatedAt}, nil
+}
+
+func (nr *notificationRepository) SavePreferences(ctx context.Context, prefs *domain.NotificationPreferences) error {
+	collection := nr.database.Collection(nr.preferencesCollection)
+	uid, err := bson.ObjectIDFromHex(prefs.UserID)
+	if err != nil {
+		return err
+	}
+
+	muted := make([]string, len(prefs.Muted))
+	for i, t := range prefs.Muted {
+		muted[i] = string(t)
+	}
+	update := bson.M{"$set": bson.M{"muted": muted, "updated_at": prefs.UpdatedAt}}
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": uid}, update, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+func (nr *notificationRepository) DeleteNotificationsForUser(ctx context.Context, userID string) error {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	if _, err := nr.database.Collection(nr.collection).DeleteMany(ctx, bson.M{"user_id": uid}); err != nil {
+		return err
+	}
+	_, err = nr.database.Collection(nr.preferencesCollection).DeleteOne(ctx, bson.M{"_id": uid})
+	return err
+}
+
+func (nr *notificationRepository) DeleteNotificationsForTargets(ctx context.Context, targetIDs []string) error {
+	if len(targetIDs) == 0 {
+		return nil
+	}
+	_, err := nr.database.Collection(nr.collection).DeleteMany(ctx, bson.M{"target_id": bson.M{"$in": targetIDs}})
+	return err
+}
+
+type notificationDTO struct {
+	ID           bson.ObjectID `bson:"_id"`
+	UserID       bson.ObjectID `bson:"user_id"`
+	Type         string        `bson:"type"`
+	TargetID     string        `bson:"target_id"`
+	Preview      string        `bson:"preview"`
+	ActorCount   int           `bson:"actor_count"`
+	RecentActors []string      `bson:"recent_actors"`
+	Read         bool          `bson:"read"`
+	CreatedAt    time.Time     `bson:"created_at"`
+	UpdatedAt    time.Time     `bson:"updated_at"`
+}
+
+type notificationPreferencesDTO struct {
+	UserID    bson.ObjectID `bson:"_id"`
+	Muted     []string      `bson:"muted"`
+	UpdatedAt time.Time     `bson:"updated_at"`
+}
+
+func notificationDTOToDomain(dto *notificationDTO) *domain.Notification {
+	return &domain.Notification{
+		ID:           dto.ID.Hex(),
+		UserID:       dto.UserID.Hex(),
+		Type:         domain.NotificationType(dto.Type),
+		TargetID:     dto.TargetID,
+		Preview:      dto.Preview,
+		ActorCount:   dto.ActorCount,
+		RecentActors: dto.RecentActors,
+		Read:         dto.Read,
+		CreatedAt:    dto.CreatedAt,
+		UpdatedAt:    dto.UpdatedAt,
+	}
+}
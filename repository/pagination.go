@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"blog-backend/domain"
+	"encoding/base64"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// pageCursor is the decoded form of the opaque next_cursor handed to clients:
+// the sort key of the last item on a page plus its _id to break ties.
+type pageCursor struct {
+	Value interface{}   `bson:"v"`
+	ID    bson.ObjectID `bson:"id"`
+}
+
+func encodePageCursor(value interface{}, id bson.ObjectID) string {
+	raw, err := bson.Marshal(pageCursor{Value: value, ID: id})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodePageCursor(token string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := bson.Unmarshal(raw, &c); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
+// paginate restricts filter to documents after token in the given sort order
+// (-1 descending, 1 ascending) and returns find options that fetch one
+// document more than limit, so callers can tell whether another page exists.
+func paginate(filter bson.M, token, field string, direction, limit int) (*options.FindOptionsBuilder, error) {
+	if token != "" {
+		after, err := decodePageCursor(token)
+		if err != nil {
+			return nil, err
+		}
+		op := "$gt"
+		if direction < 0 {
+			op = "$lt"
+		}
+		var condition bson.M
+		if field == "_id" {
+			condition = bson.M{"_id": bson.M{op: after.ID}}
+		} else {
+			condition = bson.M{"$or": []bson.M{
+				{field: bson.M{op: after.Value}},
+				{field: after.Value, "_id": bson.M{op: after.ID}},
+			}}
+		}
+		and, _ := filter["$and"].([]bson.M)
+		filter["$and"] = append(and, condition)
+	}
+
+	sort := bson.D{{Key: field, Value: direction}}
+	if field != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: direction})
+	}
+	return options.Find().SetSort(sort).SetLimit(int64(limit + 1)), nil
+}
+
+// Relevance ordered results have no stable sort key to resume from, so their
+// cursor simply records how many results were already returned.
+func encodeOffsetCursor(offset int) string {
+	return encodePageCursor(offset, bson.NilObjectID)
+}
+
+func decodeOffsetCursor(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	c, err := decodePageCursor(token)
+	if err != nil {
+		return 0, err
+	}
+	var offset int
+	switch value := c.Value.(type) {
+	case int32:
+		offset = int(value)
+	case int64:
+		offset = int(value)
+	default:
+		return 0, domain.ErrInvalidCursor
+	}
+	if offset < 0 {
+		return 0, domain.ErrInvalidCursor
+	}
+	return offset, nil
+}
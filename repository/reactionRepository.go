@@ -7,6 +7,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type reactionRepository struct {
@@ -21,107 +22,359 @@ func NewReactionRepositoryFromDB(db *mongo.Database) domain.IReactionRepository
 	}
 }
 
-func (rr *reactionRepository) AddReaction(ctx context.Context, reaction *domain.Reaction) error {
+func (rr *reactionRepository) SetReaction(ctx context.Context, reaction *domain.Reaction) (domain.ReactionType, error) {
 	collection := rr.database.Collection(rr.collection)
 
 	reactionDTO, err := domainToReactionDTO(reaction)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	_, err = collection.InsertOne(ctx, reactionDTO)
+	// blog reactions have no comment_id; matching null keeps them apart
+	// from the reactions on the blog's comments
+	var commentID interface{}
+	if reactionDTO.CommentID != nil {
+		commentID = *reactionDTO.CommentID
+	}
+	filter := bson.D{
+		{Key: "blog_id", Value: reactionDTO.BlogID},
+		{Key: "comment_id", Value: commentID},
+		{Key: "user_id", Value: reactionDTO.UserID},
+	}
+	update := bson.M{
+		"$set":         bson.M{"type": reactionDTO.Type},
+		"$setOnInsert": bson.M{"created_at": reactionDTO.CreatedAt},
+	}
+	// the previous document tells the caller which counters to move
+	updateOptions := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var previous ReactionDTO
+	err = collection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(&previous)
+	if mongo.IsDuplicateKeyError(err) {
+		// a concurrent first reaction won the upsert; this one now updates it
+		err = collection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(&previous)
+	}
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return domain.ReactionType(previous.Type), nil
+}
+
+func (rr *reactionRepository) DeleteReaction(ctx context.Context, blogID, userID string) (domain.ReactionType, error) {
+	bID, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return "", domain.ErrReactionNotFound
+	}
+	uID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", domain.ErrReactionNotFound
+	}
+
+	filter := bson.D{{Key: "blog_id", Value: bID}, {Key: "comment_id", Value: nil}, {Key: "user_id", Value: uID}}
+	return rr.deleteOne(ctx, filter)
+}
+
+func (rr *reactionRepository) DeleteCommentReaction(ctx context.Context, commentID, userID string) (domain.ReactionType, error) {
+	cID, err := bson.ObjectIDFromHex(commentID)
+	if err != nil {
+		return "", domain.ErrReactionNotFound
+	}
+	uID, err := bson.ObjectIDFromHex(userID)
 	if err != nil {
-		return err
+		return "", domain.ErrReactionNotFound
 	}
 
-	return nil
+	filter := bson.D{{Key: "comment_id", Value: cID}, {Key: "user_id", Value: uID}}
+	return rr.deleteOne(ctx, filter)
 }
 
-func (rr *reactionRepository) RemoveReaction(ctx context.Context, blogID, userID string) error {
+func (rr *reactionRepository) deleteOne(ctx context.Context, filter bson.D) (domain.ReactionType, error) {
 	collection := rr.database.Collection(rr.collection)
+	var removed ReactionDTO
+	err := collection.FindOneAndDelete(ctx, filter).Decode(&removed)
+	if err == mongo.ErrNoDocuments {
+		return "", domain.ErrReactionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return domain.ReactionType(removed.Type), nil
+}
 
+func (rr *reactionRepository) GetReactions(ctx context.Context, blogID string, reactionType domain.ReactionType, cursorToken string, limit int) ([]*domain.Reaction, string, error) {
+	limit = normalizeLimit(limit)
+	collection := rr.database.Collection(rr.collection)
 	bID, err := bson.ObjectIDFromHex(blogID)
 	if err != nil {
-		return err
+		return nil, "", domain.ErrBlogNotFound
 	}
 
-	uID, err := bson.ObjectIDFromHex(userID)
+	filter := bson.M{"blog_id": bID, "comment_id": nil}
+	if reactionType != "" {
+		filter["type"] = string(reactionType)
+	}
+	findOptions, err := paginate(filter, cursorToken, "_id", -1, limit)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
-	
-	filter := bson.D{{Key: "blog_id", Value: bID}, {Key: "user_id", Value: uID}}
-
-	_, err = collection.DeleteOne(ctx, filter)
+	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return err
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []ReactionDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, "", err
 	}
 
-	return nil
+	next := ""
+	if len(dtos) > limit {
+		dtos = dtos[:limit]
+		next = encodePageCursor(nil, dtos[limit-1].ID)
+	}
+	reactions := make([]*domain.Reaction, len(dtos))
+	for i := range dtos {
+		reactions[i], err = ReactionDTOToDomain(&dtos[i])
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return reactions, next, nil
 }
 
-func (rr *reactionRepository) CheckReactionExists(ctx context.Context, blogID, userID string) (*domain.Reaction, bool, error) {
+// DeleteReactionsForBlogs removes every reaction on the blogs and their comments.
+func (rr *reactionRepository) DeleteReactionsForBlogs(ctx context.Context, blogIDs []string) (int64, error) {
 	collection := rr.database.Collection(rr.collection)
+	oids, err := hexToObjectIDs(blogIDs)
+	if err != nil {
+		return 0, err
+	}
+	if len(oids) == 0 {
+		return 0, nil
+	}
+	res, err := collection.DeleteMany(ctx, bson.M{"blog_id": bson.M{"$in": oids}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
 
-	bID, err := bson.ObjectIDFromHex(blogID)
+func (rr *reactionRepository) DeleteReactionsForComments(ctx context.Context, commentIDs []string) (int64, error) {
+	collection := rr.database.Collection(rr.collection)
+	oids, err := hexToObjectIDs(commentIDs)
 	if err != nil {
-		return &domain.Reaction{}, false, err
+		return 0, err
 	}
+	if len(oids) == 0 {
+		return 0, nil
+	}
+	res, err := collection.DeleteMany(ctx, bson.M{"comment_id": bson.M{"$in": oids}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
 
+func (rr *reactionRepository) DeleteReactionsByUser(ctx context.Context, userID string) ([]string, []string, error) {
+	collection := rr.database.Collection(rr.collection)
 	uID, err := bson.ObjectIDFromHex(userID)
 	if err != nil {
-		return &domain.Reaction{}, false, err
+		return nil, nil, err
 	}
 
-	filter := bson.D{{Key: "blog_id", Value: bID}, {Key: "user_id", Value: uID}}
+	var blogIDs []bson.ObjectID
+	if err := collection.Distinct(ctx, "blog_id", bson.M{"user_id": uID, "comment_id": nil}).Decode(&blogIDs); err != nil {
+		return nil, nil, err
+	}
+	var commentIDs []bson.ObjectID
+	if err := collection.Distinct(ctx, "comment_id", bson.M{"user_id": uID, "comment_id": bson.M{"$ne": nil}}).Decode(&commentIDs); err != nil {
+		return nil, nil, err
+	}
+	if _, err := collection.DeleteMany(ctx, bson.M{"user_id": uID}); err != nil {
+		return nil, nil, err
+	}
+	return objectIDsToHex(blogIDs), objectIDsToHex(commentIDs), nil
+}
 
-	var ReactionDTO ReactionDTO
-	err = collection.FindOne(ctx, filter).Decode(&ReactionDTO)
+// CountReactions tallies the blog's reactions by type.
+func (rr *reactionRepository) CountReactions(ctx context.Context, blogID string) (map[domain.ReactionType]int, error) {
+	counts, err := rr.CountReactionsForBlogs(ctx, []string{blogID})
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return &domain.Reaction{}, true, nil
-		}
+		return nil, err
+	}
+	if blogCounts, ok := counts[blogID]; ok {
+		return blogCounts, nil
+	}
+	return map[domain.ReactionType]int{}, nil
+}
 
-		return &domain.Reaction{}, false, err
+// CountReactionsForBlogs tallies the reactions of each blog by type. Blogs
+// without reactions are left out of the result.
+func (rr *reactionRepository) CountReactionsForBlogs(ctx context.Context, blogIDs []string) (map[string]map[domain.ReactionType]int, error) {
+	oids, err := hexToObjectIDs(blogIDs)
+	if err != nil {
+		return nil, err
 	}
+	if len(oids) == 0 {
+		return map[string]map[domain.ReactionType]int{}, nil
+	}
+	return rr.countByType(ctx, bson.M{"blog_id": bson.M{"$in": oids}, "comment_id": nil}, "$blog_id")
+}
 
-	dReaction, err := ReactionDTOToDomain(&ReactionDTO)
+// CountReactionsForComments tallies the reactions of each comment by type.
+// Comments without reactions are left out of the result.
+func (rr *reactionRepository) CountReactionsForComments(ctx context.Context, commentIDs []string) (map[string]map[domain.ReactionType]int, error) {
+	oids, err := hexToObjectIDs(commentIDs)
 	if err != nil {
-		return nil, false, err
+		return nil, err
+	}
+	if len(oids) == 0 {
+		return map[string]map[domain.ReactionType]int{}, nil
 	}
+	return rr.countByType(ctx, bson.M{"comment_id": bson.M{"$in": oids}}, "$comment_id")
+}
 
-	return dReaction, false, nil
+// likedFilter matches reactions on blogs that are anything but a dislike.
+func likedFilter(filter bson.M) bson.M {
+	filter["comment_id"] = nil
+	filter["type"] = bson.M{"$ne": string(domain.Dislike)}
+	return filter
 }
 
-func (rr *reactionRepository) UpdateReaction(ctx context.Context, blogID, userID string, reactionType domain.ReactionType) error {
+func (rr *reactionRepository) ListLikedBlogIDs(ctx context.Context, userID string, limit int) ([]string, error) {
 	collection := rr.database.Collection(rr.collection)
-	
-	bID, err := bson.ObjectIDFromHex(blogID)
+	uid, err := bson.ObjectIDFromHex(userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	uID, err := bson.ObjectIDFromHex(userID)
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"blog_id": 1})
+	cursor, err := collection.Find(ctx, likedFilter(bson.M{"user_id": uid}), findOptions)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	var dtos []struct {
+		BlogID bson.ObjectID `bson:"blog_id"`
+	}
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.BlogID.Hex()
+	}
+	return ids, nil
+}
 
-	filter := bson.D{{Key: "blog_id", Value: bID}, {Key: "user_id", Value: uID}}
-
-	update := bson.D{{Key: "$set", Value: bson.D{{Key: "type", Value: string(reactionType)}}}}
+func (rr *reactionRepository) CountCoReactions(ctx context.Context, blogIDs []string, excludeUserID string, readerLimit, limit int) (map[string]int, error) {
+	collection := rr.database.Collection(rr.collection)
+	counts := map[string]int{}
+	oids, err := hexToObjectIDs(blogIDs)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := bson.ObjectIDFromHex(excludeUserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(oids) == 0 {
+		return counts, nil
+	}
 
-	_, err = collection.UpdateOne(ctx, filter, update)
+	// the most recent other readers who liked the same blogs
+	readersPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: likedFilter(bson.M{"blog_id": bson.M{"$in": oids}, "user_id": bson.M{"$ne": exclude}})}},
+		{{Key: "$group", Value: bson.M{"_id": "$user_id", "last": bson.M{"$max": "$_id"}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "last", Value: -1}}}},
+		{{Key: "$limit", Value: readerLimit}},
+	}
+	cursor, err := collection.Aggregate(ctx, readersPipeline)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var readers []idOnlyDTO
+	if err = cursor.All(ctx, &readers); err != nil {
+		return nil, err
+	}
+	if len(readers) == 0 {
+		return counts, nil
+	}
+	readerIDs := make([]bson.ObjectID, len(readers))
+	for i, reader := range readers {
+		readerIDs[i] = reader.ID
 	}
 
-	return nil
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: likedFilter(bson.M{"user_id": bson.M{"$in": readerIDs}, "blog_id": bson.M{"$nin": oids}})}},
+		{{Key: "$group", Value: bson.M{"_id": "$blog_id", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+	cursor, err = collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		BlogID bson.ObjectID `bson:"_id"`
+		Count  int           `bson:"count"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		counts[group.BlogID.Hex()] = group.Count
+	}
+	return counts, nil
 }
 
+// countByType groups the reactions matching filter by the target held in
+// targetField and by type.
+func (rr *reactionRepository) countByType(ctx context.Context, filter bson.M, targetField string) (map[string]map[domain.ReactionType]int, error) {
+	collection := rr.database.Collection(rr.collection)
+	counts := make(map[string]map[domain.ReactionType]int)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"target": targetField, "type": "$type"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Key struct {
+			Target bson.ObjectID `bson:"target"`
+			Type   string        `bson:"type"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
 
-
+	for _, group := range groups {
+		target := group.Key.Target.Hex()
+		if counts[target] == nil {
+			counts[target] = make(map[domain.ReactionType]int)
+		}
+		counts[target][domain.ReactionType(group.Key.Type)] = group.Count
+	}
+	return counts, nil
+}
 
 type ReactionDTO struct {
+	ID          bson.ObjectID `bson:"_id,omitempty"`
 	BlogID	    bson.ObjectID `bson:"blog_id"`
+	CommentID   *bson.ObjectID `bson:"comment_id,omitempty"`
 	UserID	    bson.ObjectID `bson:"user_id"`
 	Type		string        `bson:"type"`	
 	CreatedAt   time.Time     `bson:"created_at"`
@@ -131,8 +384,15 @@ func ReactionDTOToDomain(dto *ReactionDTO) (*domain.Reaction, error) {
 	blogID := dto.BlogID.Hex()
 	userID := dto.UserID.Hex()
 
+	commentID := ""
+	if dto.CommentID != nil {
+		commentID = dto.CommentID.Hex()
+	}
+
 	return &domain.Reaction{
+		ID:        dto.ID.Hex(),
 		BlogID:    blogID,
+		CommentID: commentID,
 		UserID:    userID,
 		Type:      domain.ReactionType(dto.Type),
 		CreatedAt: dto.CreatedAt,
@@ -148,8 +408,17 @@ func domainToReactionDTO(reaction *domain.Reaction) (*ReactionDTO, error) {
 	if err != nil {
 		return nil, err
 	}
+	var commentID *bson.ObjectID
+	if reaction.CommentID != "" {
+		oid, err := bson.ObjectIDFromHex(reaction.CommentID)
+		if err != nil {
+			return nil, err
+		}
+		commentID = &oid
+	}
 	return &ReactionDTO{
 		BlogID:    blogID,
+		CommentID: commentID,
 		UserID:    userID,
 		Type:      string(reaction.Type),
 		CreatedAt: reaction.CreatedAt,
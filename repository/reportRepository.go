@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type reportRepository struct {
+	database   *mongo.Database
+	collection string
+}
+
+func NewReportRepositoryFromDB(db *mongo.Database) domain.IReportRepository {
+	return &reportRepository{
+		database:   db,
+		collection: "reports",
+	}
+}
+
+func (rr *reportRepository) CreateReport(ctx context.Context, report *domain.Report) (*domain.Report, error) {
+	collection := rr.database.Collection(rr.collection)
+	dto, err := reportDomainToDTO(report)
+	if err != nil {
+		return nil, err
+	}
+
+	insertedResult, err := collection.InsertOne(ctx, dto)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, domain.ErrAlreadyReported
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.ID = insertedResult.InsertedID.(bson.ObjectID).Hex()
+	return report, nil
+}
+
+func (rr *reportRepository) GetReportByID(ctx context.Context, reportID string) (*domain.Report, error) {
+	collection := rr.database.Collection(rr.collection)
+	oid, err := bson.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, domain.ErrReportNotFound
+	}
+
+	var dto reportDTO
+	err = collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reportDTOToDomain(&dto), nil
+}
+
+func (rr *reportRepository) ListReports(ctx context.Context, reportFilter domain.ReportFilter, cursorToken string, limit int) ([]*domain.Report, string, error) {
+	limit = normalizeLimit(limit)
+	collection := rr.database.Collection(rr.collection)
+
+	filter := bson.M{}
+	if reportFilter.Status != "" {
+		filter["status"] = string(reportFilter.Status)
+	}
+	if reportFilter.TargetType != "" {
+		filter["target_type"] = string(reportFilter.TargetType)
+	}
+	findOptions, err := paginate(filter, cursorToken, "created_at", 1, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []reportDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(dtos) > limit {
+		dtos = dtos[:limit]
+		last := dtos[limit-1]
+		next = encodePageCursor(last.CreatedAt, last.ID)
+	}
+	reports := make([]*domain.Report, len(dtos))
+	for i, dto := range dtos {
+		reports[i] = reportDTOToDomain(&dto)
+	}
+	return reports, next, nil
+}
+
+func (rr *reportRepository) CountOpenReports(ctx context.Context, targetType domain.ReportTargetType, targetID string) (int64, error) {
+	collection := rr.database.Collection(rr.collection)
+	return collection.CountDocuments(ctx, bson.M{
+		"target_type": string(targetType),
+		"target_id":   targetID,
+		"status":      string(domain.ReportOpen),
+	})
+}
+
+func (rr *reportRepository) ResolveReports(ctx context.Context, targetType domain.ReportTargetType, targetID string, resolution *domain.Report) (int64, error) {
+	collection := rr.database.Collection(rr.collection)
+	moderatorID, err := bson.ObjectIDFromHex(resolution.ResolvedBy)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := bson.M{
+		"target_type": string(targetType),
+		"target_id":   targetID,
+		"status":      string(domain.ReportOpen),
+	}
+	update := bson.M{"$set": bson.M{
+		"status":      string(resolution.Status),
+		"action":      string(resolution.Action),
+		"resolved_by": moderatorID,
+		"resolved_at": resolution.ResolvedAt,
+		"note":        resolution.Note,
+	}}
+	res, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+type reportDTO struct {
+	ID         bson.ObjectID  `bson:"_id,omitempty"`
+	TargetType string         `bson:"target_type"`
+	TargetID   string         `bson:"target_id"`
+	BlogID     string         `bson:"blog_id"`
+	ReporterID bson.ObjectID  `bson:"reporter_id"`
+	Reason     string         `bson:"reason"`
+	Details    string         `bson:"details,omitempty"`
+	Status     string         `bson:"status"`
+	Action     string         `bson:"action,omitempty"`
+	ResolvedBy *bson.ObjectID `bson:"resolved_by,omitempty"`
+	ResolvedAt time.Time      `bson:"resolved_at,omitempty"`
+	Note       string         `bson:"note,omitempty"`
+	CreatedAt  time.Time      `bson:"created_at"`
+}
+
+func reportDomainToDTO(report *domain.Report) (*reportDTO, error) {
+	reporterID, err := bson.ObjectIDFromHex(report.ReporterID)
+	if err != nil {
+		return nil, err
+	}
+	return &reportDTO{
+		TargetType: string(report.TargetType),
+		TargetID:   report.TargetID,
+		BlogID:     report.BlogID,
+		ReporterID: reporterID,
+		Reason:     string(report.Reason),
+		Details:    report.Details,
+		Status:     string(report.Status),
+		CreatedAt:  report.CreatedAt,
+	}, nil
+}
+
+func reportDTOToDomain(dto *reportDTO) *domain.Report {
+	report := &domain.Report{
+		ID:         dto.ID.Hex(),
+		TargetType: domain.ReportTargetType(dto.TargetType),
+		TargetID:   dto.TargetID,
+		BlogID:     dto.BlogID,
+		ReporterID: dto.ReporterID.Hex(),
+		Reason:     domain.ReportReason(dto.Reason),
+		Details:    dto.Details,
+		Status:     domain.ReportStatus(dto.Status),
+		Action:     domain.ModerationAction(dto.Action),
+		ResolvedAt: dto.ResolvedAt,
+		Note:       dto.Note,
+		CreatedAt:  dto.CreatedAt,
+	}
+	if dto.ResolvedBy != nil {
+		report.ResolvedBy = dto.ResolvedBy.Hex()
+	}
+	return report
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type revisionRepository struct {
+	database   *mongo.Database
+	collection string
+}
+
+func NewRevisionRepositoryFromDB(db *mongo.Database) domain.IBlogRevisionRepository {
+	return &revisionRepository{
+		database:   db,
+		collection: "blog_revisions",
+	}
+}
+
+// Revisions are append-only; there is deliberately no update method.
+func (rr *revisionRepository) CreateRevision(ctx context.Context, revision *domain.BlogRevision) (*domain.BlogRevision, error) {
+	collection := rr.database.Collection(rr.collection)
+
+	revisionDTO, err := domainToRevisionDTO(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	insertedResult, err := collection.InsertOne(ctx, revisionDTO)
+	// the unique (blog_id, version) index: another edit took this version
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, domain.ErrConcurrentEdit
+	}
+	if err != nil {
+		return nil, err
+	}
+	revision.ID = insertedResult.InsertedID.(bson.ObjectID).Hex()
+
+	return revision, nil
+}
+
+func (rr *revisionRepository) GetRevisions(ctx context.Context, blogID string) ([]*domain.BlogRevision, error) {
+	collection := rr.database.Collection(rr.collection)
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{"blog_id": oid}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var revisionDTOs []revisionDTO
+	if err = cursor.All(ctx, &revisionDTOs); err != nil {
+		return nil, err
+	}
+
+	revisions := make([]*domain.BlogRevision, len(revisionDTOs))
+	for i, dto := range revisionDTOs {
+		revisions[i] = revisionDTOToDomain(&dto)
+	}
+	return revisions, nil
+}
+
+func (rr *revisionRepository) GetRevision(ctx context.Context, blogID string, version int) (*domain.BlogRevision, error) {
+	collection := rr.database.Collection(rr.collection)
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	var dto revisionDTO
+	err = collection.FindOne(ctx, bson.M{"blog_id": oid, "version": version}).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return revisionDTOToDomain(&dto), nil
+}
+
+func (rr *revisionRepository) GetLatestRevision(ctx context.Context, blogID string) (*domain.BlogRevision, error) {
+	collection := rr.database.Collection(rr.collection)
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	var dto revisionDTO
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+	err = collection.FindOne(ctx, bson.M{"blog_id": oid}, findOptions).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return revisionDTOToDomain(&dto), nil
+}
+
+func (rr *revisionRepository) DeleteRevisionsForBlogs(ctx context.Context, blogIDs []string) error {
+	collection := rr.database.Collection(rr.collection)
+	oids, err := hexToObjectIDs(blogIDs)
+	if err != nil {
+		return err
+	}
+	if len(oids) == 0 {
+		return nil
+	}
+	_, err = collection.DeleteMany(ctx, bson.M{"blog_id": bson.M{"$in": oids}})
+	return err
+}
+
+type fieldChangeDTO struct {
+	Field string      `bson:"field"`
+	Old   interface{} `bson:"old"`
+	New   interface{} `bson:"new"`
+}
+
+type revisionDTO struct {
+	ID           bson.ObjectID    `bson:"_id,omitempty"`
+	BlogID       bson.ObjectID    `bson:"blog_id"`
+	Version      int              `bson:"version"`
+	EditorID     bson.ObjectID    `bson:"editor_id"`
+	Title        string           `bson:"title"`
+	Content      string           `bson:"content"`
+	Tags         []string         `bson:"tags"`
+	Changes      []fieldChangeDTO `bson:"changes"`
+	RestoredFrom int              `bson:"restored_from,omitempty"`
+	CreatedAt    time.Time        `bson:"created_at"`
+}
+
+func domainToRevisionDTO(revision *domain.BlogRevision) (*revisionDTO, error) {
+	blogID, err := bson.ObjectIDFromHex(revision.BlogID)
+	if err != nil {
+		return nil, err
+	}
+	editorID, err := bson.ObjectIDFromHex(revision.EditorID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]fieldChangeDTO, len(revision.Changes))
+	for i, change := range revision.Changes {
+		changes[i] = fieldChangeDTO{Field: change.Field, Old: change.Old, New: change.New}
+	}
+
+	return &revisionDTO{
+		BlogID:       blogID,
+		Version:      revision.Version,
+		EditorID:     editorID,
+		Title:        revision.Title,
+		Content:      revision.Content,
+		Tags:         revision.Tags,
+		Changes:      changes,
+		RestoredFrom: revision.RestoredFrom,
+		CreatedAt:    revision.CreatedAt,
+	}, nil
+}
+
+func revisionDTOToDomain(dto *revisionDTO) *domain.BlogRevision {
+	changes := make([]domain.FieldChange, len(dto.Changes))
+	for i, change := range dto.Changes {
+		changes[i] = domain.FieldChange{Field: change.Field, Old: change.Old, New: change.New}
+	}
+
+	return &domain.BlogRevision{
+		ID:           dto.ID.Hex(),
+		BlogID:       dto.BlogID.Hex(),
+		Version:      dto.Version,
+		EditorID:     dto.EditorID.Hex(),
+		Title:        dto.Title,
+		Content:      dto.Content,
+		Tags:         dto.Tags,
+		Changes:      changes,
+		RestoredFrom: dto.RestoredFrom,
+		CreatedAt:    dto.CreatedAt,
+	}
+}
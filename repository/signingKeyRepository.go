@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type signingKeyRepository struct {
+	database   *mongo.Database
+	collection string
+}
+
+// NewSigningKeyRepositoryFromDB keeps the access token key ring, shared by
+// every instance so they all verify each other's tokens.
+func NewSigningKeyRepositoryFromDB(db *mongo.Database) domain.ISigningKeyRepository {
+	return &signingKeyRepository{
+		database:   db,
+		collection: "signing_keys",
+	}
+}
+
+type signingKeyDTO struct {
+	ID          string    `bson:"_id"`
+	Algorithm   string    `bson:"algorithm"`
+	Generation  int       `bson:"generation"`
+	PrivateKey  []byte    `bson:"private_key"`
+	CreatedAt   time.Time `bson:"created_at"`
+	ActivatesAt time.Time `bson:"activates_at"`
+}
+
+func (kr *signingKeyRepository) ListSigningKeys(ctx context.Context) ([]*domain.SigningKey, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "generation", Value: 1}})
+	cursor, err := kr.database.Collection(kr.collection).Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []signingKeyDTO
+	if err := cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*domain.SigningKey, len(dtos))
+	for i, dto := range dtos {
+		keys[i] = &domain.SigningKey{
+			ID:          dto.ID,
+			Algorithm:   dto.Algorithm,
+			Generation:  dto.Generation,
+			PrivateKey:  dto.PrivateKey,
+			CreatedAt:   dto.CreatedAt,
+			ActivatesAt: dto.ActivatesAt,
+		}
+	}
+	return keys, nil
+}
+
+func (kr *signingKeyRepository) CreateSigningKey(ctx context.Context, key *domain.SigningKey) error {
+	_, err := kr.database.Collection(kr.collection).InsertOne(ctx, signingKeyDTO{
+		ID:          key.ID,
+		Algorithm:   key.Algorithm,
+		Generation:  key.Generation,
+		PrivateKey:  key.PrivateKey,
+		CreatedAt:   key.CreatedAt,
+		ActivatesAt: key.ActivatesAt,
+	})
+	// the unique generation index lets only one instance rotate
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrSigningKeyExists
+	}
+	return err
+}
+
+func (kr *signingKeyRepository) DeleteSigningKeys(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := kr.database.Collection(kr.collection).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// notDeleted narrows filter to documents that were not soft deleted. A nil
+// deleted_at matches both a missing field and an explicit null.
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	return filter
+}
+
+// softDeleteUpdate marks a document as deleted by the given user.
+func softDeleteUpdate(deletedBy string, at time.Time) bson.M {
+	return bson.M{"$set": bson.M{"deleted_at": at, "deleted_by": deletedBy}}
+}
+
+// restoreUpdate clears the soft delete marker again.
+func restoreUpdate() bson.M {
+	return bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}}
+}
+
+// deletedBeforeFilter matches documents soft deleted before the cutoff.
+func deletedBeforeFilter(before time.Time) bson.M {
+	return bson.M{"deleted_at": bson.M{"$lt": before}}
+}
+
+// deletedIDsOptions fetches the oldest deletions first, ids only.
+func deletedIDsOptions(limit int) *options.FindOptionsBuilder {
+	return options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.D{{Key: "deleted_at", Value: 1}}).
+		SetLimit(int64(limit))
+}
+
+type idOnlyDTO struct {
+	ID bson.ObjectID `bson:"_id"`
+}
+
+func idsToHex(dtos []idOnlyDTO) []string {
+	ids := make([]string, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.ID.Hex()
+	}
+	return ids
+}
+
+func objectIDsToHex(oids []bson.ObjectID) []string {
+	ids := make([]string, len(oids))
+	for i, oid := range oids {
+		ids[i] = oid.Hex()
+	}
+	return ids
+}
+
+func hexToObjectIDs(ids []string) ([]bson.ObjectID, error) {
+	oids := make([]bson.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		oid, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		oids = append(oids, oid)
+	}
+	return oids, nil
+}
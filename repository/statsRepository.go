@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// viewBatchRetention is how long written view batches are remembered; a
+// failed flush is retried long before that.
+const viewBatchRetention = 7 * 24 * time.Hour
+
+type statsRepository struct {
+	database        *mongo.Database
+	collection      string
+	batchCollection string
+}
+
+// NewStatsRepositoryFromDB stores one document per blog and UTC day.
+func NewStatsRepositoryFromDB(db *mongo.Database) domain.IStatsRepository {
+	return &statsRepository{
+		database:        db,
+		collection:      "blog_stats",
+		batchCollection: "view_batches",
+	}
+}
+
+type dailyStatsDTO struct {
+	BlogID    bson.ObjectID `bson:"blog_id"`
+	Day       time.Time     `bson:"day"`
+	Views     int           `bson:"views"`
+	Readers   int           `bson:"readers"`
+	Reactions int           `bson:"reactions"`
+	Comments  int           `bson:"comments"`
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (sr *statsRepository) AddActivity(ctx context.Context, blogID string, day time.Time, field domain.StatsField, n int) error {
+	collection := sr.database.Collection(sr.collection)
+	oid, err := bson.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.ErrBlogNotFound
+	}
+	filter := bson.M{"blog_id": oid, "day": startOfDay(day)}
+	update := bson.M{"$inc": bson.M{string(field): n}}
+	_, err = collection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+func (sr *statsRepository) AddViews(ctx context.Context, views []*domain.ViewCount) error {
+	collection := sr.database.Collection(sr.collection)
+	models := make([]mongo.WriteModel, 0, len(views))
+	for _, view := range views {
+		oid, err := bson.ObjectIDFromHex(view.BlogID)
+		if err != nil {
+			continue
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"blog_id": oid, "day": startOfDay(view.Day)}).
+			SetUpdate(bson.M{
+				"$inc": bson.M{"views": view.Views},
+				"$max": bson.M{"readers": view.Readers},
+			}).
+			SetUpsert(true))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// MarkViewBatch upserts rather than inserts, so an already written batch
+// does not abort the transaction it is checked in.
+func (sr *statsRepository) MarkViewBatch(ctx context.Context, batchID string) (bool, error) {
+	collection := sr.database.Collection(sr.batchCollection)
+	update := bson.M{"$setOnInsert": bson.M{"expires_at": time.Now().Add(viewBatchRetention)}}
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": batchID}, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount > 0, nil
+}
+
+func (sr *statsRepository) GetDailyStats(ctx context.Context, blogIDs []string, from, to time.Time) ([]*domain.DailyStats, error) {
+	collection := sr.database.Collection(sr.collection)
+	oids, err := hexToObjectIDs(blogIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(oids) == 0 {
+		return []*domain.DailyStats{}, nil
+	}
+	filter := bson.M{
+		"blog_id": bson.M{"$in": oids},
+		"day":     bson.M{"$gte": startOfDay(from), "$lte": startOfDay(to)},
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "day", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var dtos []dailyStatsDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	stats := make([]*domain.DailyStats, len(dtos))
+	for i, dto := range dtos {
+		stats[i] = &domain.DailyStats{
+			BlogID: dto.BlogID.Hex(),
+			Day:    dto.Day.UTC(),
+			StatsTotals: domain.StatsTotals{
+				Views:     dto.Views,
+				Readers:   dto.Readers,
+				Reactions: dto.Reactions,
+				Comments:  dto.Comments,
+			},
+		}
+	}
+	return stats, nil
+}
+
+func (sr *statsRepository) DeleteStatsForBlogs(ctx context.Context, blogIDs []string) error {
+	collection := sr.database.Collection(sr.collection)
+	oids, err := hexToObjectIDs(blogIDs)
+	if err != nil {
+		return err
+	}
+	if len(oids) == 0 {
+		return nil
+	}
+	_, err = collection.DeleteMany(ctx, bson.M{"blog_id": bson.M{"$in": oids}})
+	return err
+}
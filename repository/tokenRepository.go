@@ -8,6 +8,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type refreshTokenRepository struct {
@@ -24,66 +25,70 @@ func NewRefreshTokenRepositoryFromDB(db *mongo.Database) domain.IRefreshTokenRep
 }
 
 // Refresh Tokens
-func (tr *refreshTokenRepository) CreateRefreshToken(ctx context.Context, userID, refToken string) (*domain.RefreshToken, error) {
+func (tr *refreshTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) (*domain.RefreshToken, error) {
 	collection := tr.database.Collection(tr.collection)
 
-	refreshToken, err := refreshToken(userID, refToken)
+	refreshToken, err := refreshToken(token)
 	if err != nil {
-		return nil, err	
+		return nil, err
 	}
 
 	insertResult, err := collection.InsertOne(ctx, refreshToken)
-	if err != nil {	
+	if err != nil {
 		return nil, err
 	}
 
 	refreshToken.ID = insertResult.InsertedID.(bson.ObjectID)
-	
-	return &domain.RefreshToken{
-		Token:     refreshToken.Token,
-		ExpiresAt: refreshToken.ExpiresAt,
-	}, nil
+
+	return refreshToken.toDomain(), nil
 }
 
-func (tr *refreshTokenRepository) ReplaceRefreshToken(ctx context.Context, userID, refToken string) (*domain.RefreshToken, error) {
+func (tr *refreshTokenRepository) GetRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
 	collection := tr.database.Collection(tr.collection)
-	filter := bson.D{{Key: "user_id", Value: userID}}
-
-	refreshToken, err := refreshToken(userID, refToken)
-	if err != nil {
-		return nil, err	
-	}
+	filter := bson.D{{Key: "token", Value: token}}
 
-	_, err = collection.ReplaceOne(ctx, filter, refreshToken)
+	var refreshTokenDTO refreshTokenDTO
+	err := collection.FindOne(ctx, filter).Decode(&refreshTokenDTO)
 	if err != nil {
-		return nil, err	
+		return nil, err
 	}
 
-	return &domain.RefreshToken{
-		Token:     refreshToken.Token,
-		ExpiresAt: refreshToken.ExpiresAt,
-	}, nil
+	return refreshTokenDTO.toDomain(), nil
 }
 
-func (tr *refreshTokenRepository) GetRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+func (tr *refreshTokenRepository) UseRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
 	collection := tr.database.Collection(tr.collection)
-	filter := bson.D{{Key: "token", Value: token}}
 
-	var refreshTokenDTO refreshTokenDTO
-	err := collection.FindOne(ctx, filter).Decode(&refreshTokenDTO)
-	if err != nil {
+	var spent refreshTokenDTO
+	err := collection.FindOneAndUpdate(ctx,
+		bson.M{"token": token, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": time.Now()}},
+	).Decode(&spent)
+	if err == nil {
+		return spent.toDomain(), nil
+	}
+	if err != mongo.ErrNoDocuments {
 		return nil, err
 	}
 
-	return &domain.RefreshToken{
-		UserID: refreshTokenDTO.UserID.Hex(),
-		Token: refreshTokenDTO.Token,
-		ExpiresAt: refreshTokenDTO.ExpiresAt,
-	}, err
+	// either the token never existed or it was spent before
+	previous, err := tr.GetRefreshToken(ctx, token)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return previous, domain.ErrRefreshTokenReused
 }
 
-func (tr *refreshTokenRepository) DeleteRefreshToken(ctx context.Context, token string) error {
-	res, err := tr.database.Collection(tr.collection).DeleteOne(ctx,bson.M{"token": token})
+func (tr *refreshTokenRepository) DeleteRefreshTokensForUser(ctx context.Context, userID string) error {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrTokenNotFound
+	}
+
+	res, err := tr.database.Collection(tr.collection).DeleteMany(ctx, bson.M{"user_id": uid})
 	if err != nil {
 		return err
 	}
@@ -93,18 +98,102 @@ func (tr *refreshTokenRepository) DeleteRefreshToken(ctx context.Context, token
 	return nil
 }
 
-func (tr *refreshTokenRepository) DeleteRefreshTokensForUser(ctx context.Context, userID string) error {
+// ListSessions returns a session for every unspent, unexpired token of the
+// user, most recently used first.
+func (tr *refreshTokenRepository) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return []*domain.Session{}, nil
+	}
 
-	res, err := tr.database.Collection(tr.collection).DeleteMany(ctx, bson.M{"user_id": userID})
+	filter := bson.M{
+		"user_id":    uid,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := tr.database.Collection(tr.collection).Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []refreshTokenDTO
+	if err := cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*domain.Session, len(dtos))
+	for i, dto := range dtos {
+		token := dto.toDomain()
+		sessions[i] = &domain.Session{
+			ID:         token.SessionID,
+			Device:     token.Device,
+			UserAgent:  token.UserAgent,
+			IP:         token.IP,
+			CreatedAt:  token.SessionStartedAt,
+			LastUsedAt: token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+		}
+	}
+	return sessions, nil
+}
+
+// DeleteSession removes the whole token family of a session, spent tokens
+// included.
+func (tr *refreshTokenRepository) DeleteSession(ctx context.Context, userID, sessionID string) error {
+	filter, err := sessionFilter(userID, sessionID)
+	if err != nil {
+		return domain.ErrSessionNotFound
+	}
+
+	res, err := tr.database.Collection(tr.collection).DeleteMany(ctx, filter)
 	if err != nil {
 		return err
 	}
-	if res.DeletedCount == 0{
-		return domain.ErrTokenNotFound
+	if res.DeletedCount == 0 {
+		return domain.ErrSessionNotFound
 	}
 	return nil
 }
 
+func (tr *refreshTokenRepository) DeleteOtherSessions(ctx context.Context, userID, sessionID string) (int64, error) {
+	keep, err := sessionFilter(userID, sessionID)
+	if err != nil {
+		return 0, domain.ErrSessionNotFound
+	}
+
+	filter := bson.M{
+		"user_id": keep["user_id"],
+		"$nor":    keep["$or"],
+	}
+	res, err := tr.database.Collection(tr.collection).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// sessionFilter matches the tokens of a session. Tokens issued before
+// sessions existed have no session_id and are a session of their own.
+func sessionFilter(userID, sessionID string) (bson.M, error) {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	sid, err := bson.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{
+		"user_id": uid,
+		"$or": bson.A{
+			bson.M{"session_id": sid},
+			bson.M{"_id": sid, "session_id": bson.M{"$exists": false}},
+		},
+	}, nil
+}
+
 type resetTokenRepository struct {
 	database   *mongo.Database
 	collection string
@@ -177,19 +266,64 @@ type refreshTokenDTO struct {
 	UserID    bson.ObjectID `bson:"user_id"`
 	ExpiresAt time.Time     `bson:"expires_at"`
 	CreatedAt time.Time     `bson:"created_at"`
+
+	SessionID        bson.ObjectID `bson:"session_id,omitempty"`
+	SessionStartedAt time.Time     `bson:"session_started_at,omitempty"`
+	UsedAt           *time.Time    `bson:"used_at,omitempty"`
+	Device           string        `bson:"device,omitempty"`
+	UserAgent        string        `bson:"user_agent,omitempty"`
+	IP               string        `bson:"ip,omitempty"`
 }
 
-func refreshToken(userID, token string) (*refreshTokenDTO, error) {
-	oid, err := bson.ObjectIDFromHex(userID)
+// refreshToken builds the document for t; a token without a session opens a
+// new one.
+func refreshToken(t *domain.RefreshToken) (*refreshTokenDTO, error) {
+	oid, err := bson.ObjectIDFromHex(t.UserID)
 	if err != nil {
 		return nil, err
 	}
-	return &refreshTokenDTO{
-		Token:     token,
-		UserID:    oid,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour), 
-		CreatedAt: time.Now(),
-	}, nil
+	now := time.Now()
+	dto := &refreshTokenDTO{
+		Token:            t.Token,
+		UserID:           oid,
+		ExpiresAt:        now.Add(7 * 24 * time.Hour),
+		CreatedAt:        now,
+		SessionID:        bson.NewObjectID(),
+		SessionStartedAt: now,
+		Device:           t.Device,
+		UserAgent:        t.UserAgent,
+		IP:               t.IP,
+	}
+	if t.SessionID != "" {
+		sid, err := bson.ObjectIDFromHex(t.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		dto.SessionID = sid
+		dto.SessionStartedAt = t.SessionStartedAt
+	}
+	return dto, nil
+}
+
+func (dto *refreshTokenDTO) toDomain() *domain.RefreshToken {
+	token := &domain.RefreshToken{
+		ID:               dto.ID.Hex(),
+		Token:            dto.Token,
+		UserID:           dto.UserID.Hex(),
+		ExpiresAt:        dto.ExpiresAt,
+		CreatedAt:        dto.CreatedAt,
+		SessionID:        dto.SessionID.Hex(),
+		SessionStartedAt: dto.SessionStartedAt,
+		Used:             dto.UsedAt != nil,
+		Device:           dto.Device,
+		UserAgent:        dto.UserAgent,
+		IP:               dto.IP,
+	}
+	if dto.SessionID.IsZero() {
+		token.SessionID = dto.ID.Hex()
+		token.SessionStartedAt = dto.CreatedAt
+	}
+	return token
 }
 
 type activationTokenRepository struct {
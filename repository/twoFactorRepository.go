@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type twoFactorRepository struct {
+	database   *mongo.Database
+	collection string
+}
+
+// NewTwoFactorRepositoryFromDB keeps one document per user, keyed by the
+// user's ID.
+func NewTwoFactorRepositoryFromDB(db *mongo.Database) domain.ITwoFactorRepository {
+	return &twoFactorRepository{
+		database:   db,
+		collection: "two_factor",
+	}
+}
+
+type twoFactorDTO struct {
+	UserID        bson.ObjectID `bson:"_id"`
+	Enabled       bool          `bson:"enabled"`
+	Secret        []byte        `bson:"secret,omitempty"`
+	PendingSecret []byte        `bson:"pending_secret,omitempty"`
+	RecoveryCodes []string      `bson:"recovery_codes"`
+	LastStep      int64         `bson:"last_step"`
+	EnabledAt     *time.Time    `bson:"enabled_at,omitempty"`
+}
+
+func (tr *twoFactorRepository) GetTwoFactor(ctx context.Context, userID string) (*domain.TwoFactor, error) {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidUserID
+	}
+
+	var dto twoFactorDTO
+	err = tr.database.Collection(tr.collection).FindOne(ctx, bson.M{"_id": uid}).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TwoFactor{
+		UserID:            userID,
+		Enabled:           dto.Enabled,
+		Secret:            dto.Secret,
+		PendingSecret:     dto.PendingSecret,
+		RecoveryCodesLeft: len(dto.RecoveryCodes),
+		EnabledAt:         dto.EnabledAt,
+	}, nil
+}
+
+func (tr *twoFactorRepository) SetPendingSecret(ctx context.Context, userID string, sealed []byte) error {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidUserID
+	}
+
+	_, err = tr.database.Collection(tr.collection).UpdateOne(ctx,
+		bson.M{"_id": uid},
+		bson.M{
+			"$set":         bson.M{"pending_secret": sealed},
+			"$setOnInsert": bson.M{"enabled": false, "recovery_codes": []string{}, "last_step": int64(0)},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+func (tr *twoFactorRepository) EnableTwoFactor(ctx context.Context, userID string, sealed []byte, recoveryHashes []string, step int64) error {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidUserID
+	}
+
+	// a second enrolment started meanwhile replaced the pending secret
+	res, err := tr.database.Collection(tr.collection).UpdateOne(ctx,
+		bson.M{"_id": uid, "enabled": false, "pending_secret": sealed},
+		bson.M{
+			"$set": bson.M{
+				"enabled":        true,
+				"secret":         sealed,
+				"recovery_codes": recoveryHashes,
+				"last_step":      step,
+				"enabled_at":     time.Now(),
+			},
+			"$unset": bson.M{"pending_secret": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrTwoFactorNotEnrolled
+	}
+	return nil
+}
+
+func (tr *twoFactorRepository) UseTwoFactorStep(ctx context.Context, userID string, step int64) (bool, error) {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, domain.ErrInvalidUserID
+	}
+
+	res, err := tr.database.Collection(tr.collection).UpdateOne(ctx,
+		bson.M{"_id": uid, "last_step": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"last_step": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (tr *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID, hash string) (bool, error) {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, domain.ErrInvalidUserID
+	}
+
+	res, err := tr.database.Collection(tr.collection).UpdateOne(ctx,
+		bson.M{"_id": uid, "enabled": true, "recovery_codes": hash},
+		bson.M{"$pull": bson.M{"recovery_codes": hash}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (tr *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, recoveryHashes []string) error {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidUserID
+	}
+
+	res, err := tr.database.Collection(tr.collection).UpdateOne(ctx,
+		bson.M{"_id": uid, "enabled": true},
+		bson.M{"$set": bson.M{"recovery_codes": recoveryHashes}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrTwoFactorNotEnabled
+	}
+	return nil
+}
+
+func (tr *twoFactorRepository) ReplaceSecret(ctx context.Context, userID string, previous, sealed []byte) error {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidUserID
+	}
+
+	_, err = tr.database.Collection(tr.collection).UpdateOne(ctx,
+		bson.M{"_id": uid, "enabled": true, "secret": previous},
+		bson.M{"$set": bson.M{"secret": sealed}},
+	)
+	return err
+}
+
+func (tr *twoFactorRepository) DeleteTwoFactor(ctx context.Context, userID string) error {
+	uid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrInvalidUserID
+	}
+
+	res, err := tr.database.Collection(tr.collection).DeleteOne(ctx, bson.M{"_id": uid})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrTwoFactorNotEnabled
+	}
+	return nil
+}
@@ -28,6 +28,10 @@ func (ur userRepository) CreateUser(ctx context.Context, user *domain.User) (*do
 
 	userDTO := DomainToDTO(*user)
 	insertedResult, err := collection.InsertOne(ctx, userDTO)
+	// a soft deleted account keeps its email and username until it is purged
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, domain.ErrUserAlreadyExists
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +48,7 @@ func (ur userRepository) ActivateUser(ctx context.Context, userID string) error
 		return err
 	}
 	updates := bson.D{{Key:"status", Value: string(domain.Active)}}
-	filter := bson.D{{Key:"_id", Value: oid}}
+	filter := notDeleted(bson.M{"_id": oid})
 	update := bson.D{{Key: "$set", Value: updates}}
 
 	updateResult, err := collection.UpdateOne(ctx, filter, update)
@@ -63,7 +67,7 @@ func (ur userRepository) GetUserByID(ctx context.Context, id string) (*domain.Us
 		return nil, fmt.Errorf("invalid user ID: %v", err)
 	}
 
-	filter := bson.D{{Key: "_id", Value: oid}}
+	filter := notDeleted(bson.M{"_id": oid})
 
 	var user *UserDTO
 	err = collection.FindOne(ctx, filter).Decode(&user)
@@ -75,7 +79,7 @@ func (ur userRepository) GetUserByID(ctx context.Context, id string) (*domain.Us
 
 func (ur userRepository) GetUserByGoogleID(ctx context.Context, googleID string) (*domain.User, error) {
 	collection := ur.database.Collection(ur.collection)
-	filter := bson.D{{Key: "google_id", Value: googleID}}
+	filter := notDeleted(bson.M{"google_id": googleID})
 
 	var user *UserDTO
 	err := collection.FindOne(ctx, filter).Decode(&user)
@@ -88,7 +92,7 @@ func (ur userRepository) GetUserByGoogleID(ctx context.Context, googleID string)
 
 func (ur userRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	collection := ur.database.Collection(ur.collection)
-	filter := bson.D{{Key: "email", Value: email}}
+	filter := notDeleted(bson.M{"email": email})
 
 	var user *UserDTO
 	err := collection.FindOne(ctx, filter).Decode(&user)
@@ -101,7 +105,7 @@ func (ur userRepository) GetUserByEmail(ctx context.Context, email string) (*dom
 
 func (ur userRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
 	collection := ur.database.Collection(ur.collection)
-	filter := bson.D{{Key: "username", Value: username}}
+	filter := notDeleted(bson.M{"username": username})
 
 	var user *UserDTO
 	err := collection.FindOne(ctx, filter).Decode(&user)
@@ -114,7 +118,7 @@ func (ur userRepository) GetUserByUsername(ctx context.Context, username string)
 
 func (ur userRepository) GetUserByUsernameAndEmail(ctx context.Context, username, email string) (*domain.User, error) {
 	collection := ur.database.Collection(ur.collection)
-	filter := bson.D{{Key: "username", Value: username}, {Key: "email", Value: email}}
+	filter := notDeleted(bson.M{"username": username, "email": email})
 
 	var user *UserDTO
 	err := collection.FindOne(ctx, filter).Decode(&user)
@@ -156,7 +160,7 @@ func (ur userRepository) UpdateUser(ctx context.Context, id string, updates map[
 	}
 	delete(updates, "role")
 
-	filter := bson.D{{Key: "_id", Value: oid}}
+	filter := notDeleted(bson.M{"_id": oid})
 	updateDoc := bson.D{{Key: "$set", Value: updates}}
 
 	_, err = collection.UpdateOne(ctx, filter, updateDoc)
@@ -166,16 +170,132 @@ func (ur userRepository) UpdateUser(ctx context.Context, id string, updates map[
 	return nil
 }
 //i used soft delete to preserve the context of history for other objects such as blog and comments
-func (ur userRepository) DeleteUser(ctx context.Context, id string) error {
-	connection := ur.database.Collection(ur.collection) 
+func (ur userRepository) DeleteUser(ctx context.Context, id string, deletedBy string, action domain.UserContentAction, at time.Time) error {
+	collection := ur.database.Collection(ur.collection)
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+	update := softDeleteUpdate(deletedBy, at)
+	update["$set"].(bson.M)["content_action"] = string(action)
+	res, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (ur userRepository) RestoreUser(ctx context.Context, id string) (*domain.DeletedUser, error) {
+	collection := ur.database.Collection(ur.collection)
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	update := restoreUpdate()
+	update["$unset"].(bson.M)["content_action"] = ""
+	findOptions := options.FindOneAndUpdate().
+		SetProjection(bson.M{"_id": 1, "deleted_at": 1, "deleted_by": 1, "content_action": 1}).
+		SetReturnDocument(options.Before)
+	var dto deletedUserDTO
+	err = collection.FindOneAndUpdate(ctx, bson.M{"_id": oid, "deleted_at": bson.M{"$ne": nil}}, update, findOptions).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dto.toDomain(), nil
+}
+
+type deletedUserDTO struct {
+	ID            bson.ObjectID `bson:"_id"`
+	DeletedAt     time.Time     `bson:"deleted_at"`
+	DeletedBy     string        `bson:"deleted_by"`
+	ContentAction string        `bson:"content_action"`
+}
+
+func (dto *deletedUserDTO) toDomain() *domain.DeletedUser {
+	action := domain.UserContentAction(dto.ContentAction)
+	// accounts deleted before the choice existed keep their content
+	if !action.IsValid() {
+		action = domain.TransferUserContent
+	}
+	return &domain.DeletedUser{
+		ID:            dto.ID.Hex(),
+		DeletedAt:     dto.DeletedAt,
+		DeletedBy:     dto.DeletedBy,
+		ContentAction: action,
+	}
+}
+
+func (ur userRepository) ListDeletedUsers(ctx context.Context, before time.Time, limit int) ([]*domain.DeletedUser, error) {
+	collection := ur.database.Collection(ur.collection)
+	findOptions := deletedIDsOptions(limit).SetProjection(bson.M{"_id": 1, "deleted_at": 1, "deleted_by": 1, "content_action": 1})
+	cursor, err := collection.Find(ctx, deletedBeforeFilter(before), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	var dtos []deletedUserDTO
+	if err = cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	users := make([]*domain.DeletedUser, len(dtos))
+	for i := range dtos {
+		users[i] = dtos[i].toDomain()
+	}
+	return users, nil
+}
+
+// PurgeUser permanently removes a soft deleted user; a restored user is kept.
+func (ur userRepository) PurgeUser(ctx context.Context, id string) error {
+	collection := ur.database.Collection(ur.collection)
 	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": oid, "deleted_at": bson.M{"$ne": nil}})
 	if err != nil {
 		return err
 	}
-	connection.DeleteOne(ctx, bson.M{"_id":oid})
+	if res.DeletedCount == 0 {
+		return domain.ErrUserNotFound
+	}
 	return nil
 }
 
+func (ur userRepository) EnsureGhostUser(ctx context.Context) (*domain.User, error) {
+	collection := ur.database.Collection(ur.collection)
+	now := time.Now()
+	filter := bson.M{"username": domain.GhostUsername}
+	// no password and never activated, so nobody can sign in as the ghost
+	update := bson.M{"$setOnInsert": bson.M{
+		"email":           domain.GhostUsername + "@users.invalid",
+		"password_hash":   "",
+		"role":            string(domain.RegularUser),
+		"status":          string(domain.Inactive),
+		"created_at":      now,
+		"updated_at":      now,
+		"follower_count":  0,
+		"following_count": 0,
+	}}
+	findOptions := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var dto UserDTO
+	err := collection.FindOneAndUpdate(ctx, filter, update, findOptions).Decode(&dto)
+	if mongo.IsDuplicateKeyError(err) {
+		// created concurrently; it exists now
+		err = collection.FindOne(ctx, filter).Decode(&dto)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return DTOToDomain(&dto), nil
+}
+
 // Profile Management
 func (ur userRepository) UpdateProfile(ctx context.Context, userID string, updates map[string]interface{}) error {
 		updates["updated_at"] = time.Now()
@@ -197,7 +317,7 @@ func (ur userRepository) PromoteToAdmin(ctx context.Context, userID string) erro
 		return fmt.Errorf("invalid user ID: %v", err)
 	}
 
-	filter := bson.D{{Key: "_id", Value: oid}}
+	filter := notDeleted(bson.M{"_id": oid})
 	update := bson.D{{Key: "$set", Value: bson.D{{Key: "role", Value: string(domain.Admin)}}}}
 
 	updateResult, err := collection.UpdateOne(ctx, filter, update)
@@ -216,7 +336,7 @@ func (ur userRepository) DemoteToUser(ctx context.Context, userID string) error
 		return fmt.Errorf("invalid user ID: %v", err)
 	}
 
-	filter := bson.D{{Key: "_id", Value: oid}}
+	filter := notDeleted(bson.M{"_id": oid})
 	update := bson.D{{Key: "$set", Value: bson.D{{Key: "role", Value: string(domain.RegularUser)}}}}
 
 	updateResult, err := collection.UpdateOne(ctx, filter, update)
@@ -226,38 +346,155 @@ func (ur userRepository) DemoteToUser(ctx context.Context, userID string) error
 
 	return nil
 }
-func (ur userRepository)GetUsers(ctx context.Context, page, limit int)([]*domain.User,int64, error){
-	if page <= 0 {
-		page = 1
+
+func (ur userRepository) RestrictUser(ctx context.Context, userID string, status domain.Status, reason string, until *time.Time) error {
+	collection := ur.database.Collection(ur.collection)
+
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	set := bson.M{
+		"status":        string(status),
+		"status_reason": reason,
+		"updated_at":    time.Now(),
+	}
+	update := bson.M{"$set": set}
+	if until != nil {
+		set["status_until"] = *until
+	} else {
+		update["$unset"] = bson.M{"status_until": ""}
+	}
+
+	updateResult, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), update)
+	if err != nil {
+		return err
+	}
+	if updateResult.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (ur userRepository) LiftRestriction(ctx context.Context, userID string) error {
+	collection := ur.database.Collection(ur.collection)
+
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	filter := notDeleted(bson.M{
+		"_id":    oid,
+		"status": bson.M{"$in": bson.A{string(domain.Suspended), string(domain.Banned)}},
+	})
+	update := bson.M{
+		"$set":   bson.M{"status": string(domain.Active), "updated_at": time.Now()},
+		"$unset": bson.M{"status_reason": "", "status_until": ""},
+	}
+
+	updateResult, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
 	}
-	if limit <= 0 {
-		limit = 10
+	if updateResult.MatchedCount == 0 {
+		return domain.ErrUserNotRestricted
 	}
+	return nil
+}
+
+func (ur userRepository)GetUsers(ctx context.Context, cursorToken string, limit int)([]*domain.User, string, error){
+	limit = normalizeLimit(limit)
 	collection := ur.database.Collection(ur.collection)
-	skip := int64((page - 1) * limit)
-	lim := int64(limit)
-	findOptions := options.Find()
-	findOptions.SetSkip(skip)
-	findOptions.SetLimit(lim)
-	cursor, err := collection.Find(ctx, bson.M{}, findOptions)
+	filter := notDeleted(bson.M{})
+	findOptions, err := paginate(filter, cursorToken, "created_at", -1, limit)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", err
+	}
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, "", err
 	}
 	defer cursor.Close(ctx)
 	var userResDTOs []UserDTO
 	if err = cursor.All(ctx, &userResDTOs); err != nil {
-		return nil, 0, err
+		return nil, "", err
+	}
+
+	next := ""
+	if len(userResDTOs) > limit {
+		userResDTOs = userResDTOs[:limit]
+		last := userResDTOs[limit-1]
+		next = encodePageCursor(last.CreatedAt, last.ID)
 	}
 	users := make([]*domain.User, len(userResDTOs))
 	for i, dto := range userResDTOs {
 		users[i] = DTOToDomain(&dto)
 	}
-	total, err := collection.CountDocuments(ctx, bson.M{})
+
+	return users, next, nil
+}
+
+func (ur userRepository) GetUsersByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	collection := ur.database.Collection(ur.collection)
+	oids := make([]bson.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		oid, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID: %v", err)
+		}
+		oids = append(oids, oid)
+	}
+	if len(oids) == 0 {
+		return []*domain.User{}, nil
+	}
+
+	cursor, err := collection.Find(ctx, notDeleted(bson.M{"_id": bson.M{"$in": oids}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var userDTOs []UserDTO
+	if err = cursor.All(ctx, &userDTOs); err != nil {
+		return nil, err
+	}
+	users := make([]*domain.User, len(userDTOs))
+	for i, dto := range userDTOs {
+		users[i] = DTOToDomain(&dto)
+	}
+	return users, nil
+}
+
+func (ur userRepository) UpdateUserMetrics(ctx context.Context, userID string, field string, increment int) error {
+	collection := ur.database.Collection(ur.collection)
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %v", err)
+	}
+
+	res, err := collection.UpdateOne(ctx, notDeleted(bson.M{"_id": oid}), bson.M{"$inc": bson.M{field: increment}})
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
+	if res.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
 
-	return users, total, nil
+func (ur userRepository) SetUserMetrics(ctx context.Context, userID string, metrics map[string]int) error {
+	collection := ur.database.Collection(ur.collection)
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %v", err)
+	}
+	set := bson.M{}
+	for field, value := range metrics {
+		set[field] = value
+	}
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": set})
+	return err
 }
 
 // DTOs
@@ -270,11 +507,15 @@ type UserDTO struct {
 	PasswordHash   string        `bson:"password_hash"`
 	Role           string        `bson:"role"`
 	Status         string        `bson:"status"`
+	StatusReason   string        `bson:"status_reason,omitempty"`
+	StatusUntil    *time.Time    `bson:"status_until,omitempty"`
 	CreatedAt      time.Time     `bson:"created_at"`
 	UpdatedAt      time.Time     `bson:"updated_at"`
 	Bio            string        `bson:"bio,omitempty"`
 	ProfilePicture string        `bson:"profile_picture,omitempty"`
 	ContactInfo    string        `bson:"contact_info,omitempty"`
+	FollowerCount  int           `bson:"follower_count"`
+	FollowingCount int           `bson:"following_count"`
 }
 
 // DTO mapper
@@ -288,11 +529,15 @@ func DTOToDomain(d *UserDTO) *domain.User {
 		PasswordHash:   d.PasswordHash,
 		Role:           domain.Role(d.Role),
 		Status:         domain.Status(d.Status),
+		StatusReason:   d.StatusReason,
+		StatusUntil:    d.StatusUntil,
 		CreatedAt:      d.CreatedAt,
 		UpdatedAt:      d.UpdatedAt,
 		Bio:            d.Bio,
 		ProfilePicture: d.ProfilePicture,
 		ContactInfo:    d.ContactInfo,
+		FollowerCount:  d.FollowerCount,
+		FollowingCount: d.FollowingCount,
 	}
 }
 
@@ -304,10 +549,14 @@ func DomainToDTO(u domain.User) *UserDTO {
 		PasswordHash:   u.PasswordHash,
 		Role:           string(u.Role),
 		Status:         string(u.Status),
+		StatusReason:   u.StatusReason,
+		StatusUntil:    u.StatusUntil,
 		CreatedAt:      u.CreatedAt,
 		UpdatedAt:      u.UpdatedAt,
 		Bio:            u.Bio,
 		ProfilePicture: u.ProfilePicture,
 		ContactInfo:    u.ContactInfo,
+		FollowerCount:  u.FollowerCount,
+		FollowingCount: u.FollowingCount,
 	}
 }
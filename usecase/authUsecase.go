@@ -4,10 +4,14 @@ import (
 	"blog-backend/domain"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,32 +21,44 @@ import (
 type authUsecase struct {
 	userRepository         domain.IUserRepository
 	refreshTokenRepository domain.IRefreshTokenRepository
+	tokenDenylist          domain.ITokenDenylist
 	resetTokenRepository domain.IResetTokenRepository
 	jwtServices      domain.IJWTService
 	passwordServices domain.IPasswordService
 	emailServices domain.IEmailServices
 	activationTokenRepository domain.IActivationTokenRepository
+	twoFactorRepository   domain.ITwoFactorRepository
+	totpService           domain.ITOTPService
+	twoFactorStore        domain.ITwoFactorStore
 	contextTimeout        time.Duration
 }
 
 func NewAuthUsecase(
 	userRepository domain.IUserRepository,
 	refreshTokenRepository domain.IRefreshTokenRepository,
+	tokenDenylist domain.ITokenDenylist,
 	resetTokenRepository domain.IResetTokenRepository,
 	jwtServices domain.IJWTService,
 	passwordServices domain.IPasswordService,
 	emailServices domain.IEmailServices,
 	activationTokenRepository domain.IActivationTokenRepository,
+	twoFactorRepository domain.ITwoFactorRepository,
+	totpService domain.ITOTPService,
+	twoFactorStore domain.ITwoFactorStore,
 	timeout time.Duration,
 ) domain.IAuthUseCase {
 	return &authUsecase{
 		userRepository: userRepository,   
 		refreshTokenRepository: refreshTokenRepository, 
+		tokenDenylist: tokenDenylist,
 		resetTokenRepository: resetTokenRepository,  
 		jwtServices: jwtServices,
 		passwordServices: passwordServices,
 		emailServices: emailServices,
 		activationTokenRepository: activationTokenRepository,
+		twoFactorRepository: twoFactorRepository,
+		totpService: totpService,
+		twoFactorStore: twoFactorStore,
 		contextTimeout: timeout, 
 	}
 }
@@ -112,7 +128,7 @@ func (au *authUsecase) Activate(ctx context.Context, tokenID string) error {
 	return nil
 }
 
-func (au *authUsecase) Login(ctx context.Context, email, password string) (*domain.User, *domain.TokenPair, error) {
+func (au *authUsecase) Login(ctx context.Context, email, password string, client *domain.SessionClient) (*domain.User, *domain.TokenPair, error) {
 	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
 	defer cancel()
 
@@ -126,56 +142,56 @@ func (au *authUsecase) Login(ctx context.Context, email, password string) (*doma
 		return nil, nil, err
 	}
 
-	jwtToken, err := au.jwtServices.GenerateToken(user.ID, user.Username, user.Email, string(user.Role))
+	tokenPair, err := au.beginLogin(ctx, user, client)
 	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to generate JWT token: %v", err)
+		return nil, nil, err
 	}
 
-	refToken, err := generateToken()
-	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to generate refresh token: %v", err)
-	}
+	return user, tokenPair, nil
+}
 
-	refreshToken, err := au.refreshTokenRepository.CreateRefreshToken(ctx, user.ID, refToken)
-	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to create refresh token: %v", err)
-	}
+// Logout ends the session the refresh token belongs to and denies the
+// access token the client still holds.
+func (au *authUsecase) Logout(ctx context.Context, refreshToken, accessToken string) error {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
 
-	tokenPair := &domain.TokenPair{
-		AccessToken:  jwtToken,
-		RefreshToken: refreshToken.Token,
-		ExpiresIn:    refreshToken.ExpiresAt,
+	if claims, err := au.jwtServices.ParseToken(accessToken); err == nil {
+		if err := au.tokenDenylist.Deny(ctx, claims.TokenID, claims.ExpiresAt); err != nil {
+			log.Printf("Failed to deny access token of user %s: %v", claims.UserID, err)
+		}
 	}
 
-	return user, tokenPair, nil
-}
-
-func (au *authUsecase) Logout(ctx context.Context, refreshToken string) error {
-	err := au.refreshTokenRepository.DeleteRefreshToken(ctx, refreshToken)
+	token, err := au.refreshTokenRepository.GetRefreshToken(ctx, refreshToken)
 	if err != nil {
 		return errors.New("failed to delete refresh token")
 	}
+	if err := au.refreshTokenRepository.DeleteSession(ctx, token.UserID, token.SessionID); err != nil {
+		return errors.New("failed to delete refresh token")
+	}
 	return nil
 }
 
-func (au *authUsecase) RefreshToken(ctx context.Context, refreshToken string) (*domain.User, *domain.TokenPair, error) {
+// RefreshToken rotates the refresh token: the presented one is spent and a
+// new one is issued for the same session. Presenting a spent token means it
+// leaked, so the whole session is revoked.
+func (au *authUsecase) RefreshToken(ctx context.Context, refreshToken string, client *domain.SessionClient) (*domain.User, *domain.TokenPair, error) {
 	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
 	defer cancel()
 
-	refreshTokenData, err := au.refreshTokenRepository.GetRefreshToken(ctx, refreshToken)
+	refreshTokenData, err := au.refreshTokenRepository.UseRefreshToken(ctx, refreshToken)
+	if err == domain.ErrRefreshTokenReused {
+		log.Printf("Refresh token reused on session %s of user %s, revoking the session", refreshTokenData.SessionID, refreshTokenData.UserID)
+		au.revokeSession(ctx, refreshTokenData)
+		return nil, nil, err
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get refresh token: %v", err)
 	}
 
-	if refreshTokenData == nil {
-		return nil, nil, errors.New("refresh token not found")
-	}
-
 	if refreshTokenData.ExpiresAt.Before(time.Now()) {
-		if err := au.Logout(ctx, refreshTokenData.Token); err != nil {
-			log.Printf("Failed to delete expired refresh token: %v", err)
-		}
-		return nil, nil, errors.New("refresh token has expired")
+		au.revokeSession(ctx, refreshTokenData)
+		return nil, nil, domain.ErrRefreshTokenExpired
 	}
 
 	user, err := au.userRepository.GetUserByID(ctx, refreshTokenData.UserID)
@@ -183,25 +199,58 @@ func (au *authUsecase) RefreshToken(ctx context.Context, refreshToken string) (*
 		return nil, nil, fmt.Errorf("failed to get user: %v", err)
 	}
 
-	jwtToken, err := au.jwtServices.GenerateToken(user.ID, user.Username, user.Email, string(user.Role))
-	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to generate JWT token: %v", err)
+	next := &domain.RefreshToken{
+		SessionID:        refreshTokenData.SessionID,
+		SessionStartedAt: refreshTokenData.SessionStartedAt,
+		Device:           refreshTokenData.Device,
+		UserAgent:        refreshTokenData.UserAgent,
+		IP:               refreshTokenData.IP,
+	}
+	if client != nil {
+		if client.Device != "" {
+			next.Device = client.Device
+		}
+		if client.UserAgent != "" {
+			next.UserAgent = client.UserAgent
+		}
+		if client.IP != "" {
+			next.IP = client.IP
+		}
 	}
 
-	newRefreshToken, err := generateToken()
+	tokenPair, err := au.issueTokenPair(ctx, user, next)
 	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to generate new refresh token: %v", err)
+		var restricted *domain.AccountRestrictedError
+		if errors.As(err, &restricted) {
+			au.revokeSession(ctx, refreshTokenData)
+		}
+		return nil, nil, err
 	}
 
-	refToken, err := au.refreshTokenRepository.ReplaceRefreshToken(ctx, user.ID, newRefreshToken)
+	return user, tokenPair, nil
+}
 
-	tokenPair := &domain.TokenPair{
-		AccessToken:  jwtToken,
-		RefreshToken: refToken.Token,
-		ExpiresIn:    refToken.ExpiresAt,
+func (au *authUsecase) LogoutAll(ctx context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	return revokeUserTokens(ctx, au.refreshTokenRepository, au.tokenDenylist, userID)
+}
+
+// revokeUserTokens signs the user out everywhere: their sessions are deleted
+// and every access token issued so far is rejected.
+func revokeUserTokens(ctx context.Context, refreshTokenRepository domain.IRefreshTokenRepository, tokenDenylist domain.ITokenDenylist, userID string) error {
+	denyErr := tokenDenylist.SetTokensValidAfter(ctx, userID, time.Now())
+	if err := refreshTokenRepository.DeleteRefreshTokensForUser(ctx, userID); err != nil && err != domain.ErrTokenNotFound {
+		return err
 	}
+	return denyErr
+}
 
-	return user, tokenPair, nil
+func (au *authUsecase) revokeSession(ctx context.Context, token *domain.RefreshToken) {
+	if err := au.refreshTokenRepository.DeleteSession(ctx, token.UserID, token.SessionID); err != nil && err != domain.ErrSessionNotFound {
+		log.Printf("Failed to revoke session %s of user %s: %v", token.SessionID, token.UserID, err)
+	}
 }
 
 func (au *authUsecase) FindOrCreateGoogleUser(ctx context.Context, email, username, profilePicture, googleID string) (*domain.User, error) {
@@ -238,8 +287,45 @@ func (au *authUsecase) FindOrCreateGoogleUser(ctx context.Context, email, userna
 	return user, nil
 }
 
-func (au *authUsecase) IssueTokenPair(ctx context.Context, user *domain.User) (*domain.TokenPair, error) {
-	jwtToken, err := au.jwtServices.GenerateToken(user.ID, user.Username, user.Email, string(user.Role))
+func (au *authUsecase) IssueTokenPair(ctx context.Context, user *domain.User, client *domain.SessionClient) (*domain.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	return au.beginLogin(ctx, user, client)
+}
+
+// beginLogin starts a session for a user who proved their first factor, or
+// returns a TwoFactorRequiredError when they must also give a code.
+func (au *authUsecase) beginLogin(ctx context.Context, user *domain.User, client *domain.SessionClient) (*domain.TokenPair, error) {
+	if err := accountRestriction(user); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := au.twoFactorRepository.GetTwoFactor(ctx, user.ID)
+	if err != nil && err != domain.ErrTwoFactorNotEnabled {
+		return nil, err
+	}
+	if err == nil && twoFactor.Enabled {
+		challenge, err := au.twoFactorStore.CreateChallenge(ctx, user.ID, domain.TwoFactorChallengeTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create login challenge: %v", err)
+		}
+		return nil, &domain.TwoFactorRequiredError{Challenge: challenge, ExpiresAt: time.Now().Add(domain.TwoFactorChallengeTTL)}
+	}
+
+	return au.issueTokenPair(ctx, user, newSessionToken(client))
+}
+
+// issueTokenPair signs an access token for user and stores the next refresh
+// token of session, which starts a new session when it has no SessionID.
+// Suspended and banned users get no tokens.
+func (au *authUsecase) issueTokenPair(ctx context.Context, user *domain.User, session *domain.RefreshToken) (*domain.TokenPair, error) {
+	if err := accountRestriction(user); err != nil {
+		return nil, err
+	}
+
+	status := user.EffectiveStatus(time.Now())
+	jwtToken, err := au.jwtServices.GenerateToken(user.ID, user.Username, user.Email, string(user.Role), string(status))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate JWT token: %v", err)
 	}
@@ -248,8 +334,10 @@ func (au *authUsecase) IssueTokenPair(ctx context.Context, user *domain.User) (*
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %v", err)
 	}
+	session.UserID = user.ID
+	session.Token = refToken
 
-	refreshToken, err := au.refreshTokenRepository.CreateRefreshToken(ctx, user.ID, refToken)
+	refreshToken, err := au.refreshTokenRepository.CreateRefreshToken(ctx, session)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create refresh token  %v", err)
 	}
@@ -263,6 +351,367 @@ func (au *authUsecase) IssueTokenPair(ctx context.Context, user *domain.User) (*
 	return tokenPair, nil
 }
 
+func accountRestriction(user *domain.User) error {
+	status := user.EffectiveStatus(time.Now())
+	if status == domain.Suspended || status == domain.Banned {
+		return &domain.AccountRestrictedError{Status: status, Reason: user.StatusReason, Until: user.StatusUntil}
+	}
+	return nil
+}
+
+func newSessionToken(client *domain.SessionClient) *domain.RefreshToken {
+	token := &domain.RefreshToken{}
+	if client != nil {
+		token.Device = client.Device
+		token.UserAgent = client.UserAgent
+		token.IP = client.IP
+	}
+	return token
+}
+
+func (au *authUsecase) ListSessions(ctx context.Context, userID, refreshToken string) ([]*domain.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	sessions, err := au.refreshTokenRepository.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current, err := au.currentSession(ctx, userID, refreshToken); err == nil {
+		for _, session := range sessions {
+			session.Current = session.ID == current
+		}
+	}
+	return sessions, nil
+}
+
+func (au *authUsecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	return au.refreshTokenRepository.DeleteSession(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions signs the user out everywhere except the session the
+// refresh token belongs to.
+func (au *authUsecase) RevokeOtherSessions(ctx context.Context, userID, refreshToken string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	current, err := au.currentSession(ctx, userID, refreshToken)
+	if err != nil {
+		return 0, err
+	}
+	return au.refreshTokenRepository.DeleteOtherSessions(ctx, userID, current)
+}
+
+// currentSession returns the ID of the user's session that refreshToken
+// belongs to.
+func (au *authUsecase) currentSession(ctx context.Context, userID, refreshToken string) (string, error) {
+	if refreshToken == "" {
+		return "", domain.ErrCurrentSessionUnknown
+	}
+	token, err := au.refreshTokenRepository.GetRefreshToken(ctx, refreshToken)
+	if err == mongo.ErrNoDocuments || (err == nil && token.UserID != userID) {
+		return "", domain.ErrCurrentSessionUnknown
+	}
+	if err != nil {
+		return "", err
+	}
+	return token.SessionID, nil
+}
+
+// CompleteTwoFactorLogin finishes a login that Login answered with a
+// challenge. Each challenge starts at most one session.
+func (au *authUsecase) CompleteTwoFactorLogin(ctx context.Context, challenge, code string, client *domain.SessionClient) (*domain.User, *domain.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	userID, err := au.twoFactorStore.ChallengeUser(ctx, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := au.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	twoFactor, err := au.twoFactorRepository.GetTwoFactor(ctx, userID)
+	if err == domain.ErrTwoFactorNotEnabled || (err == nil && !twoFactor.Enabled) {
+		// reset since the challenge was issued; the login has to start over
+		au.twoFactorStore.DeleteChallenge(ctx, challenge)
+		return nil, nil, domain.ErrInvalidTwoFactorChallenge
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := au.checkSecondFactor(ctx, twoFactor, code); err != nil {
+		return nil, nil, err
+	}
+	deleted, err := au.twoFactorStore.DeleteChallenge(ctx, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !deleted {
+		return nil, nil, domain.ErrInvalidTwoFactorChallenge
+	}
+
+	tokenPair, err := au.issueTokenPair(ctx, user, newSessionToken(client))
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokenPair, nil
+}
+
+func (au *authUsecase) GetTwoFactorStatus(ctx context.Context, userID string) (*domain.TwoFactor, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	twoFactor, err := au.twoFactorRepository.GetTwoFactor(ctx, userID)
+	if err == domain.ErrTwoFactorNotEnabled {
+		return &domain.TwoFactor{UserID: userID}, nil
+	}
+	return twoFactor, err
+}
+
+// BeginTwoFactorEnrolment creates a secret for the user's authenticator app.
+// It only takes effect once ConfirmTwoFactorEnrolment sees a code from it;
+// starting over replaces the previous pending secret.
+func (au *authUsecase) BeginTwoFactorEnrolment(ctx context.Context, userID string) (*domain.TOTPEnrolment, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	twoFactor, err := au.twoFactorRepository.GetTwoFactor(ctx, userID)
+	if err == nil && twoFactor.Enabled {
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+	if err != nil && err != domain.ErrTwoFactorNotEnabled {
+		return nil, err
+	}
+
+	user, err := au.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	sealed, enrolment, err := au.totpService.NewSecret(user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create two-factor secret: %v", err)
+	}
+	if err := au.twoFactorRepository.SetPendingSecret(ctx, userID, sealed); err != nil {
+		return nil, err
+	}
+	return enrolment, nil
+}
+
+func (au *authUsecase) ConfirmTwoFactorEnrolment(ctx context.Context, userID, code string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	twoFactor, err := au.twoFactorRepository.GetTwoFactor(ctx, userID)
+	if err == domain.ErrTwoFactorNotEnabled {
+		return nil, domain.ErrTwoFactorNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled {
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+	if len(twoFactor.PendingSecret) == 0 {
+		return nil, domain.ErrTwoFactorNotEnrolled
+	}
+
+	if err := au.checkTwoFactorLock(ctx, userID); err != nil {
+		return nil, err
+	}
+	step, ok := au.totpService.Verify(twoFactor.PendingSecret, normalizeTwoFactorCode(code), time.Now())
+	if !ok {
+		return nil, domain.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %v", err)
+	}
+	if err := au.twoFactorRepository.EnableTwoFactor(ctx, userID, twoFactor.PendingSecret, hashes, step); err != nil {
+		return nil, err
+	}
+	au.clearTwoFactorFailures(ctx, userID)
+	return codes, nil
+}
+
+func (au *authUsecase) DisableTwoFactor(ctx context.Context, userID, code string) error {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	twoFactor, err := au.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := au.checkSecondFactor(ctx, twoFactor, code); err != nil {
+		return err
+	}
+	return au.twoFactorRepository.DeleteTwoFactor(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not.
+func (au *authUsecase) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	twoFactor, err := au.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := au.checkSecondFactor(ctx, twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %v", err)
+	}
+	if err := au.twoFactorRepository.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (au *authUsecase) ResetTwoFactor(ctx context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	if err := au.twoFactorRepository.DeleteTwoFactor(ctx, userID); err != nil {
+		return err
+	}
+	au.clearTwoFactorFailures(ctx, userID)
+	return nil
+}
+
+func (au *authUsecase) enabledTwoFactor(ctx context.Context, userID string) (*domain.TwoFactor, error) {
+	twoFactor, err := au.twoFactorRepository.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !twoFactor.Enabled {
+		return nil, domain.ErrTwoFactorNotEnabled
+	}
+	return twoFactor, nil
+}
+
+// checkSecondFactor accepts a current TOTP code that was not used before or
+// an unused recovery code. Too many wrong codes lock the user out for a while.
+func (au *authUsecase) checkSecondFactor(ctx context.Context, twoFactor *domain.TwoFactor, code string) error {
+	if err := au.checkTwoFactorLock(ctx, twoFactor.UserID); err != nil {
+		return err
+	}
+
+	code = normalizeTwoFactorCode(code)
+	var ok bool
+	if isTOTPCode(code) {
+		step, valid := au.totpService.Verify(twoFactor.Secret, code, time.Now())
+		if valid {
+			used, err := au.twoFactorRepository.UseTwoFactorStep(ctx, twoFactor.UserID, step)
+			if err != nil {
+				return err
+			}
+			ok = used
+		}
+		if ok {
+			au.resealTwoFactorSecret(ctx, twoFactor)
+		}
+	} else if code != "" {
+		used, err := au.twoFactorRepository.UseRecoveryCode(ctx, twoFactor.UserID, hashRecoveryCode(code))
+		if err != nil {
+			return err
+		}
+		ok = used
+	}
+
+	if !ok {
+		return domain.ErrInvalidTwoFactorCode
+	}
+	au.clearTwoFactorFailures(ctx, twoFactor.UserID)
+	return nil
+}
+
+// checkTwoFactorLock counts the code about to be checked and refuses it once
+// the user is over the limit. The attempt counts until a right code clears
+// it, so a wrong code needs nothing more recorded.
+func (au *authUsecase) checkTwoFactorLock(ctx context.Context, userID string) error {
+	attempts, err := au.twoFactorStore.RecordAttempt(ctx, userID, domain.TwoFactorLockout)
+	if err != nil {
+		return err
+	}
+	if attempts > domain.MaxTwoFactorFailures {
+		return domain.ErrTwoFactorLocked
+	}
+	return nil
+}
+
+// resealTwoFactorSecret moves a secret still sealed with the legacy key over
+// to the current one. A failure leaves the old sealing, which still works.
+func (au *authUsecase) resealTwoFactorSecret(ctx context.Context, twoFactor *domain.TwoFactor) {
+	sealed, ok := au.totpService.Reseal(twoFactor.Secret)
+	if !ok {
+		return
+	}
+	if err := au.twoFactorRepository.ReplaceSecret(ctx, twoFactor.UserID, twoFactor.Secret, sealed); err != nil {
+		log.Printf("Failed to reseal the two-factor secret of user %s: %v", twoFactor.UserID, err)
+	}
+}
+
+func (au *authUsecase) clearTwoFactorFailures(ctx context.Context, userID string) {
+	if err := au.twoFactorStore.ClearFailures(ctx, userID); err != nil {
+		log.Printf("Failed to clear two-factor failures of user %s: %v", userID, err)
+	}
+}
+
+// normalizeTwoFactorCode drops the separators people type or paste along
+// with a code.
+func normalizeTwoFactorCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes returns codes to show the user once, formatted
+// xxxxx-xxxxx, and the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, domain.RecoveryCodeCount)
+	hashes := make([]string, domain.RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b)[:10])
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
 func (au *authUsecase) ForgotPassword(ctx context.Context, email string) ( err error) {
 	res, err := au.userRepository.GetUserByEmail(ctx, email)
 	if err != nil{
@@ -323,6 +772,10 @@ func (au *authUsecase) ResetPassword(ctx context.Context, token, newPassword str
 		return fmt.Errorf("failed to mark reset token as used: %v", err)
 	}
 
+	if err := revokeUserTokens(ctx, au.refreshTokenRepository, au.tokenDenylist, resetToken.UserID); err != nil {
+		log.Printf("Failed to revoke tokens of user %s after password reset: %v", resetToken.UserID, err)
+	}
+
 	return nil
 }
 
@@ -3,10 +3,13 @@ package usecase
 import (
 	"blog-backend/domain"
 	"context"
+	"crypto/sha1"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,15 +21,39 @@ const (
 	blogDetailCacheTTL = 10 * time.Minute // TTL for individual blog posts
 	blogListCacheTTL   = 5 * time.Minute  // TTL for lists of blogs (all, by user, search)
 	commentListCacheTTL = 2 * time.Minute // TTL for comments list
+	trendingCacheTTL   = time.Minute      // TTL for pages of trending blogs
 )
 
+// weights of each kind of activity in the trending score
+const (
+	trendingViewWeight     = 1.0
+	trendingReactionWeight = 3.0
+	trendingCommentWeight  = 5.0
+)
+
+// maxTrendingScan bounds how many ranked blogs one trending page looks at
+// while filtering by tag.
+const maxTrendingScan = 1000
+
 type blogUsecase struct {
 	blogRepository         domain.IBlogRepository
 	blogReactionRepository domain.IReactionRepository
 	blogCommentRepository  domain.ICommentRepository
 	historyRepository	domain.IHistoryRepository
+	revisionRepository     domain.IBlogRevisionRepository
+	bookmarkRepository     domain.IBookmarkRepository
+	statsRepository        domain.IStatsRepository
+	userRepository         domain.IUserRepository
+	transactionManager     domain.ITransactionManager
+	searchIndex            domain.ISearchIndex
+	notificationUseCase    domain.INotificationUseCase
+	eventBroker            domain.IEventBroker
+	viewCounter            domain.IViewCounter
+	trendingIndex          domain.ITrendingIndex
 	geminiServices         domain.IGeminiService
 	cacheUseCase           domain.ICacheUseCase
+	reactionTypes          map[domain.ReactionType]bool
+	reactionTypeOrder      []domain.ReactionType
 	contextTimeout         time.Duration
 }
 
@@ -35,16 +62,43 @@ func NewBlogUsecase(
 	blogReactionRepository domain.IReactionRepository,
 	blogCommentRepository domain.ICommentRepository,
 	historyRepository	domain.IHistoryRepository,
+	revisionRepository domain.IBlogRevisionRepository,
+	bookmarkRepository domain.IBookmarkRepository,
+	statsRepository domain.IStatsRepository,
+	userRepository domain.IUserRepository,
+	transactionManager domain.ITransactionManager,
+	searchIndex domain.ISearchIndex,
+	notificationUseCase domain.INotificationUseCase,
+	eventBroker domain.IEventBroker,
+	viewCounter domain.IViewCounter,
+	trendingIndex domain.ITrendingIndex,
 	geminiServices domain.IGeminiService,
+	reactionTypes []domain.ReactionType,
 	timeout time.Duration,
 	cacheUseCase domain.ICacheUseCase, 
 ) domain.IBlogUseCase {
+	allowed := make(map[domain.ReactionType]bool, len(reactionTypes))
+	for _, reactionType := range reactionTypes {
+		allowed[reactionType] = true
+	}
 	return &blogUsecase{
 		blogRepository:         blogRepository,
 		blogReactionRepository: blogReactionRepository,
 		blogCommentRepository:  blogCommentRepository,
 		historyRepository: 		historyRepository,	
+		revisionRepository:     revisionRepository,
+		bookmarkRepository:     bookmarkRepository,
+		statsRepository:        statsRepository,
+		userRepository:         userRepository,
+		transactionManager:     transactionManager,
+		searchIndex:            searchIndex,
+		notificationUseCase:    notificationUseCase,
+		eventBroker:            eventBroker,
+		viewCounter:            viewCounter,
+		trendingIndex:          trendingIndex,
 		geminiServices:         geminiServices,
+		reactionTypes:          allowed,
+		reactionTypeOrder:      reactionTypes,
 		contextTimeout:         timeout,
 		cacheUseCase:           cacheUseCase, 
 	}
@@ -65,11 +119,43 @@ func (bu *blogUsecase) CreateBlog(ctx context.Context, blog *domain.Blog) (*doma
 		blog.Tags = response
 	}
 
+	if blog.Status == "" {
+		blog.Status = domain.BlogPublished
+	}
+	switch blog.Status {
+	case domain.BlogDraft:
+	case domain.BlogPublished:
+		blog.PublishedAt = time.Now()
+	case domain.BlogScheduled:
+		if !blog.PublishAt.After(time.Now()) {
+			return nil, domain.ErrInvalidPublishTime
+		}
+	default:
+		return nil, domain.ErrInvalidBlogStatus
+	}
+	if blog.Status != domain.BlogScheduled {
+		blog.PublishAt = time.Time{}
+	}
+
 	createdBlog, err := bu.blogRepository.CreateBlog(ctx, blog)
 	if err != nil {
 		return nil, err
 	}
 
+	_, err = bu.revisionRepository.CreateRevision(ctx, &domain.BlogRevision{
+		BlogID:    createdBlog.ID,
+		Version:   1,
+		EditorID:  createdBlog.AuthorID,
+		Title:     createdBlog.Title,
+		Content:   createdBlog.Content,
+		Tags:      createdBlog.Tags,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record initial revision for blog %s: %v", createdBlog.ID, err)
+	}
+	bu.indexBlog(ctx, createdBlog)
+
 	go bu.cacheUseCase.InvalidatePrefix(context.Background(), "blogs:list:")
 	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("blogs:user:%s", blog.AuthorID))
 
@@ -88,18 +174,15 @@ func (bu *blogUsecase) 	AddReadHistory(ctx context.Context, userID, blogID strin
 	if err != nil{
 		return err
 	}
+	// read blogs are not recommended again
+	bu.invalidateRecommendations(userID)
 	return nil
 }
 
-func (bu *blogUsecase) 	GetRecommendations(ctx context.Context, userID string) ([]*domain.Blog, error){
-	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
-	defer cancel()
-	blogs, err := bu.historyRepository.GetRecommendations(ctx, userID)
-	if err != nil{
-		return nil, err
-	}
-	return blogs, nil
-	
+// invalidateRecommendations drops the user's cached ranking after they read
+// or reacted to a blog, which both feed it.
+func (bu *blogUsecase) invalidateRecommendations(userID string) {
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("recommendations:user:%s", userID))
 }
 
 func (bu *blogUsecase) GetBlog(ctx context.Context, blogID string) (*domain.Blog, error) {
@@ -113,16 +196,6 @@ func (bu *blogUsecase) GetBlog(ctx context.Context, blogID string) (*domain.Blog
 	if err == nil && cachedBlogBytes != nil {
 		var blog domain.Blog
 		if err := json.Unmarshal(cachedBlogBytes, &blog); err == nil {
-			// Update view count asynchronously
-			go func() {
-				goroutineCtx, goroutineCancel := context.WithTimeout(context.Background(), 5*time.Second)
-				defer goroutineCancel()
-				if updateErr := bu.blogRepository.UpdateBlogMetrics(goroutineCtx, blogID, "view_count", 1); updateErr != nil {
-					log.Printf("Error updating view count for blog %s: %v", blogID, updateErr)
-				} else {
-					bu.cacheUseCase.Delete(goroutineCtx, cacheKey) // Bust cache so next fetch is fresh
-				}
-			}()
 			return &blog, nil
 		}
 		log.Printf("Failed to unmarshal cached blog %s: %v", blogID, err)
@@ -143,18 +216,200 @@ func (bu *blogUsecase) GetBlog(ctx context.Context, blogID string) (*domain.Blog
 		log.Printf("Failed to marshal blog %s for caching: %v", blogID, err)
 	}
 
-	// Update metrics asynchronously
+	return blog, nil
+}
+
+// RecordView counts the view in the buffer; the view count on the blog
+// catches up when FlushViews runs.
+func (bu *blogUsecase) RecordView(ctx context.Context, blogID, viewer string) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	_, err := bu.viewCounter.RecordView(ctx, blogID, viewer)
+	return err
+}
+
+// FlushViews writes the buffered views to the blogs and their daily stats
+// and pushes the new counters to anyone streaming them. Cached copies of the
+// blogs keep their older view count until they expire.
+func (bu *blogUsecase) FlushViews(ctx context.Context) (int64, error) {
+	return bu.viewCounter.FlushViews(ctx, func(ctx context.Context, batchID string, views []*domain.ViewCount) error {
+		if len(views) == 0 {
+			return nil
+		}
+		counts := map[string]int{}
+		for _, view := range views {
+			counts[view.BlogID] += view.Views
+		}
+
+		batchCtx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+		defer cancel()
+		written := false
+		err := bu.transactionManager.WithTransaction(batchCtx, func(ctx context.Context) error {
+			written = false
+			fresh, err := bu.statsRepository.MarkViewBatch(ctx, batchID)
+			if err != nil || !fresh {
+				// a batch handed over again after its flush failed to forget it
+				return err
+			}
+			if err := bu.blogRepository.AddViewCounts(ctx, counts); err != nil {
+				return err
+			}
+			if err := bu.statsRepository.AddViews(ctx, views); err != nil {
+				return err
+			}
+			written = true
+			return nil
+		})
+		if err != nil || !written {
+			return err
+		}
+		weights := make(map[string]float64, len(counts))
+		for blogID, count := range counts {
+			bu.publishMetrics(blogID)
+			weights[blogID] = float64(count) * trendingViewWeight
+		}
+		bu.bumpTrending(weights)
+		return nil
+	})
+}
+
+// bumpTrending adds activity to the trending ranking in the background.
+func (bu *blogUsecase) bumpTrending(weights map[string]float64) {
 	go func() {
-		goroutineCtx, goroutineCancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer goroutineCancel()
-		if updateErr := bu.blogRepository.UpdateBlogMetrics(goroutineCtx, blogID, "view_count", 1); updateErr != nil {
-			log.Printf("Error updating view count for blog %s: %v", blogID, updateErr)
-		} else {
-			bu.cacheUseCase.Delete(goroutineCtx, cacheKey)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := bu.trendingIndex.Bump(ctx, weights, time.Now()); err != nil {
+			log.Printf("Failed to update trending ranking: %v", err)
 		}
 	}()
+}
 
-	return blog, nil
+// GetTrending pages through the visible blogs with the most recent activity
+// in the window, only those carrying every one of tags if any are given.
+// The cursor is the position in the ranking to continue from.
+func (bu *blogUsecase) GetTrending(ctx context.Context, window domain.TrendingWindow, tags []string, cursor string, limit int) ([]*domain.Blog, string, error) {
+	if window == "" {
+		window = domain.TrendingDay
+	}
+	if !window.IsValid() {
+		return nil, "", domain.ErrInvalidTrendingWindow
+	}
+	offset := 0
+	if cursor != "" {
+		var err error
+		if offset, err = strconv.Atoi(cursor); err != nil || offset < 0 {
+			return nil, "", domain.ErrInvalidCursor
+		}
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	cacheKey := fmt.Sprintf("blogs:trending:%s:tags:%s:cursor:%d:limit:%d", window, strings.Join(tags, ","), offset, limit)
+	cachedBytes, err := bu.cacheUseCase.Get(ctx, cacheKey)
+	if err == nil && cachedBytes != nil {
+		var cachedData struct {
+			Blogs      []*domain.Blog `json:"blogs"`
+			NextCursor string         `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(cachedBytes, &cachedData); err == nil {
+			return cachedData.Blogs, cachedData.NextCursor, nil
+		}
+		log.Printf("Failed to unmarshal cached trending blogs %s: %v", cacheKey, err)
+	} else if err != nil {
+		log.Printf("Error getting trending blogs from cache %s: %v", cacheKey, err)
+	}
+
+	blogs := make([]*domain.Blog, 0, limit)
+	next := ""
+	position := offset
+	for position-offset < maxTrendingScan && len(blogs) < limit {
+		// more than needed, since some are filtered out
+		ids, err := bu.trendingIndex.Top(ctx, window, position, limit*2)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		page, err := bu.blogRepository.GetBlogsByIDs(ctx, ids)
+		if err != nil {
+			return nil, "", err
+		}
+		byID := make(map[string]*domain.Blog, len(page))
+		for _, blog := range page {
+			byID[blog.ID] = blog
+		}
+		for _, id := range ids {
+			position++
+			blog, ok := byID[id]
+			if !ok || !blog.IsVisible() || !hasAllTags(blog, tags) {
+				continue
+			}
+			blogs = append(blogs, blog)
+			if len(blogs) == limit {
+				break
+			}
+		}
+		if len(ids) < limit*2 && len(blogs) < limit {
+			// the end of the ranking
+			position = -1
+			break
+		}
+	}
+	if position >= 0 {
+		next = strconv.Itoa(position)
+	}
+
+	dataToCache := struct {
+		Blogs      []*domain.Blog `json:"blogs"`
+		NextCursor string         `json:"next_cursor"`
+	}{
+		Blogs:      blogs,
+		NextCursor: next,
+	}
+	if data, err := json.Marshal(dataToCache); err == nil {
+		bu.cacheUseCase.Set(ctx, cacheKey, data, trendingCacheTTL)
+	}
+	return blogs, next, nil
+}
+
+func hasAllTags(blog *domain.Blog, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range blog.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (bu *blogUsecase) MaintainTrending(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+	return bu.trendingIndex.Maintain(ctx, time.Now())
+}
+
+// recordActivity counts a reaction or comment in the blog's stats for today
+// in the background.
+func (bu *blogUsecase) recordActivity(blogID string, field domain.StatsField) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := bu.statsRepository.AddActivity(ctx, blogID, time.Now(), field, 1); err != nil {
+			log.Printf("Failed to record %s stats of blog %s: %v", field, blogID, err)
+		}
+	}()
 }
 
 
@@ -162,19 +417,181 @@ func (bu *blogUsecase) UpdateBlog(ctx context.Context, blogID string, userID str
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
 
-	err := bu.blogRepository.UpdateBlog(ctx, blogID, userID, updates)
+	var blog *domain.Blog
+	err := bu.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		blog, err = bu.blogRepository.GetBlogByID(ctx, blogID)
+		if err != nil {
+			return err
+		}
+		updates["updated_at"] = time.Now()
+		return bu.updateWithRevision(ctx, blog, userID, updates, 0)
+	})
 	if err != nil {
 		return err
 	}
+	bu.reindexBlog(ctx, blogID)
 
 	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
-	go bu.cacheUseCase.InvalidatePrefix(context.Background(), "blogs:list:")
-	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("blogs:user:%s", userID)) 
+	bu.invalidateBlogLists(blog.AuthorID)
+
+	return nil
+}
+
+// updateWithRevision applies updates to the blog together with the revision
+// they make. The revision goes first: without a transaction, losing the race
+// for the next version then leaves the blog unchanged.
+func (bu *blogUsecase) updateWithRevision(ctx context.Context, blog *domain.Blog, editorID string, updates map[string]interface{}, restoredFrom int) error {
+	if err := bu.recordRevision(ctx, blog, editorID, updates, restoredFrom); err != nil {
+		return err
+	}
+	return bu.blogRepository.UpdateBlog(ctx, blog.ID, editorID, updates)
+}
+
+// recordRevision stores the state of the blog after updates were applied to
+// before. Blogs created before revisions existed get their original state saved
+// as version 1 first, so the very first edit can still be rolled back.
+func (bu *blogUsecase) recordRevision(ctx context.Context, before *domain.Blog, editorID string, updates map[string]interface{}, restoredFrom int) error {
+	after := *before
+	if title, ok := updates["title"].(string); ok {
+		after.Title = title
+	}
+	if content, ok := updates["content"].(string); ok {
+		after.Content = content
+	}
+	if tags, ok := updates["tags"].([]string); ok {
+		after.Tags = tags
+	}
+
+	changes := blogChanges(before, &after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	latest, err := bu.revisionRepository.GetLatestRevision(ctx, before.ID)
+	if err == domain.ErrRevisionNotFound {
+		latest, err = bu.revisionRepository.CreateRevision(ctx, &domain.BlogRevision{
+			BlogID:    before.ID,
+			Version:   1,
+			EditorID:  before.AuthorID,
+			Title:     before.Title,
+			Content:   before.Content,
+			Tags:      before.Tags,
+			CreatedAt: before.UpdatedAt,
+		})
+	}
+	if err == domain.ErrConcurrentEdit {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	_, err = bu.revisionRepository.CreateRevision(ctx, &domain.BlogRevision{
+		BlogID:       before.ID,
+		Version:      latest.Version + 1,
+		EditorID:     editorID,
+		Title:        after.Title,
+		Content:      after.Content,
+		Tags:         after.Tags,
+		Changes:      changes,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    time.Now(),
+	})
+	if err == domain.ErrConcurrentEdit {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+	return nil
+}
+
+func (bu *blogUsecase) GetRevisions(ctx context.Context, blogID string) ([]*domain.BlogRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	return bu.revisionRepository.GetRevisions(ctx, blogID)
+}
+
+func (bu *blogUsecase) GetRevision(ctx context.Context, blogID string, version int) (*domain.BlogRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	return bu.revisionRepository.GetRevision(ctx, blogID, version)
+}
+
+func (bu *blogUsecase) DiffRevisions(ctx context.Context, blogID string, from, to int) (*domain.RevisionDiff, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	fromRev, err := bu.revisionRepository.GetRevision(ctx, blogID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := bu.revisionRepository.GetRevision(ctx, blogID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromBlog := &domain.Blog{Title: fromRev.Title, Content: fromRev.Content, Tags: fromRev.Tags}
+	toBlog := &domain.Blog{Title: toRev.Title, Content: toRev.Content, Tags: toRev.Tags}
+
+	diff := &domain.RevisionDiff{
+		BlogID:  blogID,
+		From:    from,
+		To:      to,
+		Changes: blogChanges(fromBlog, toBlog),
+	}
+	if fromRev.Content != toRev.Content {
+		diff.ContentDiff = diffLines(fromRev.Content, toRev.Content)
+	}
+	return diff, nil
+}
+
+// RestoreRevision makes an old revision the current version of the blog. The
+// restore itself is recorded as a new revision rather than rewriting history.
+func (bu *blogUsecase) RestoreRevision(ctx context.Context, blogID, userID string, version int) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	revision, err := bu.revisionRepository.GetRevision(ctx, blogID, version)
+	if err != nil {
+		return err
+	}
+
+	var blog *domain.Blog
+	err = bu.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		blog, err = bu.blogRepository.GetBlogByID(ctx, blogID)
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{}
+		for _, change := range blogChanges(blog, &domain.Blog{Title: revision.Title, Content: revision.Content, Tags: revision.Tags}) {
+			updates[change.Field] = change.New
+		}
+		if len(updates) == 0 {
+			return domain.ErrNothingToRestore
+		}
+		updates["updated_at"] = time.Now()
+		return bu.updateWithRevision(ctx, blog, userID, updates, version)
+	})
+	if err != nil {
+		return err
+	}
+	bu.reindexBlog(ctx, blogID)
+
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+	bu.invalidateBlogLists(blog.AuthorID)
 
 	return nil
 }
 
-func (bu *blogUsecase) DeleteBlog(ctx context.Context, blogID string) error {
+// DeleteBlog soft deletes the blog. Its comments, reactions and bookmarks
+// stay in place so RestoreBlog can bring everything back; the purge job
+// removes them once the retention period is over.
+func (bu *blogUsecase) DeleteBlog(ctx context.Context, blogID string, deletedBy string) error {
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
 
@@ -183,51 +600,75 @@ func (bu *blogUsecase) DeleteBlog(ctx context.Context, blogID string) error {
 		return err
 	}
 
-	err = bu.blogRepository.DeleteBlog(ctx, blogID)
+	err = bu.blogRepository.DeleteBlog(ctx, blogID, deletedBy)
 	if err != nil {
 		return err
 	}
+	if err := bu.searchIndex.Remove(ctx, blogID); err != nil {
+		log.Printf("Failed to remove blog %s from the search index: %v", blogID, err)
+	}
+	if err := bu.trendingIndex.Remove(ctx, blogID); err != nil {
+		log.Printf("Failed to remove blog %s from trending: %v", blogID, err)
+	}
 
 	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
-	go bu.cacheUseCase.InvalidatePrefix(context.Background(), "blogs:list:")
-	if blog != nil { 
-		go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("blogs:user:%s", blog.AuthorID))
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("comments:blog:%s:", blogID))
+	bu.invalidateBlogLists(blog.AuthorID)
+	bu.publishBlogEvent(blogID, domain.EventBlogUnavailable, nil)
+
+	return nil
+}
+
+func (bu *blogUsecase) RestoreBlog(ctx context.Context, blogID string) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	if err := bu.blogRepository.RestoreBlog(ctx, blogID); err != nil {
+		return err
+	}
+	blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return err
 	}
+	bu.indexBlog(ctx, blog)
+
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+	bu.invalidateBlogLists(blog.AuthorID)
 
 	return nil
 }
 
-func (bu *blogUsecase) ListBlogs(ctx context.Context, page, limit int, field string) ([]*domain.Blog, int64, error) {
+func (bu *blogUsecase) ListBlogs(ctx context.Context, cursor string, limit int, field string) ([]*domain.Blog, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
 
-	cacheKey := fmt.Sprintf("blogs:list:page:%d:limit:%d:field:%s", page, limit, field)
+	cacheKey := fmt.Sprintf("blogs:list:cursor:%s:limit:%d:field:%s", cursor, limit, field)
 
 	cachedBlogsBytes, err := bu.cacheUseCase.Get(ctx, cacheKey)
 	if err == nil && cachedBlogsBytes != nil {
 		var cachedData struct {
-			Blogs []*domain.Blog `json:"blogs"`
-			Total int64          `json:"total"`
+			Blogs      []*domain.Blog `json:"blogs"`
+			NextCursor string         `json:"next_cursor"`
 		}
 		if err := json.Unmarshal(cachedBlogsBytes, &cachedData); err == nil {
-			return cachedData.Blogs, cachedData.Total, nil
+			return cachedData.Blogs, cachedData.NextCursor, nil
 		}
 		log.Printf("Failed to unmarshal cached blog list %s: %v", cacheKey, err)
 	} else if err != nil {
 		log.Printf("Error getting blog list from cache %s: %v", cacheKey, err)
 	}
 
-	blogs, total, err := bu.blogRepository.ListBlogs(ctx, page, limit, field)
+	blogs, next, err := bu.blogRepository.ListBlogs(ctx, cursor, limit, field)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", err
 	}
 
 	dataToCache := struct {
-		Blogs []*domain.Blog `json:"blogs"`
-		Total int64          `json:"total"`
+		Blogs      []*domain.Blog `json:"blogs"`
+		NextCursor string         `json:"next_cursor"`
 	}{
-		Blogs: blogs,
-		Total: total,
+		Blogs:      blogs,
+		NextCursor: next,
 	}
 	blogListJSON, err := json.Marshal(dataToCache)
 	if err == nil {
@@ -236,45 +677,86 @@ func (bu *blogUsecase) ListBlogs(ctx context.Context, page, limit int, field str
 		log.Printf("Failed to marshal blog list for caching: %v", err)
 	}
 
-	return blogs, total, nil
+	return blogs, next, nil
 }
 
-func (bu *blogUsecase) SearchBlogs(ctx context.Context, query string) ([]*domain.Blog, error) {
+func (bu *blogUsecase) SearchBlogs(ctx context.Context, query domain.SearchQuery) ([]*domain.SearchResult, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
 
-	cacheKey := fmt.Sprintf("blogs:search:%s", query)
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" && query.AuthorID == "" && len(query.Tags) == 0 && query.From.IsZero() && query.To.IsZero() && query.MinLikes <= 0 {
+		return nil, "", domain.ErrEmptySearch
+	}
+	switch query.Sort {
+	case "":
+		query.Sort = domain.SortRelevance
+	case domain.SortRelevance, domain.SortNewest, domain.SortOldest, domain.SortMostLiked, domain.SortMostViewed:
+	default:
+		return nil, "", domain.ErrInvalidSearchSort
+	}
 
-	cachedBlogsBytes, err := bu.cacheUseCase.Get(ctx, cacheKey)
-	if err == nil && cachedBlogsBytes != nil {
-		var blogs []*domain.Blog
-		if err := json.Unmarshal(cachedBlogsBytes, &blogs); err == nil {
-			return blogs, nil
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, "", err
+	}
+	cacheKey := fmt.Sprintf("blogs:search:%x", sha1.Sum(queryJSON))
+
+	cachedBytes, err := bu.cacheUseCase.Get(ctx, cacheKey)
+	if err == nil && cachedBytes != nil {
+		var cachedData struct {
+			Results    []*domain.SearchResult `json:"results"`
+			NextCursor string                 `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(cachedBytes, &cachedData); err == nil {
+			return cachedData.Results, cachedData.NextCursor, nil
 		}
 		log.Printf("Failed to unmarshal cached search results %s: %v", cacheKey, err)
 	} else if err != nil {
 		log.Printf("Error getting search results from cache %s: %v", cacheKey, err)
 	}
 
-	blogs, err := bu.blogRepository.SearchBlogs(ctx, query)
+	var results []*domain.SearchResult
+	var next string
+	// The index knows nothing about likes or views, so only plain relevance
+	// searches go to it.
+	if query.Text != "" && query.Sort == domain.SortRelevance && query.MinLikes <= 0 {
+		results, next, err = bu.searchFromIndex(ctx, query)
+	} else {
+		results, next, err = bu.blogRepository.SearchBlogs(ctx, query)
+	}
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	terms := searchTerms(query.Text)
+	for _, result := range results {
+		result.Snippet = highlightSnippet(result.Blog.Content, terms)
 	}
 
-	blogJSON, err := json.Marshal(blogs)
+	dataToCache := struct {
+		Results    []*domain.SearchResult `json:"results"`
+		NextCursor string                 `json:"next_cursor"`
+	}{
+		Results:    results,
+		NextCursor: next,
+	}
+	resultsJSON, err := json.Marshal(dataToCache)
 	if err == nil {
-		bu.cacheUseCase.Set(ctx, cacheKey, blogJSON, blogListCacheTTL)
+		bu.cacheUseCase.Set(ctx, cacheKey, resultsJSON, blogListCacheTTL)
 	} else {
 		log.Printf("Failed to marshal search results for caching: %v", err)
 	}
 
-	return blogs, nil
+	return results, next, nil
 }
 
 func (bu *blogUsecase) AddReaction(ctx context.Context, blogID, userID string, reactionType string) error {
 	if _, err := bson.ObjectIDFromHex(blogID); err != nil {
 		return errors.New("invalid blog ID")
 	}
+	if !bu.reactionTypes[domain.ReactionType(reactionType)] {
+		return domain.ErrInvalidReactionType
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
@@ -285,138 +767,533 @@ func (bu *blogUsecase) AddReaction(ctx context.Context, blogID, userID string, r
 		Type:      domain.ReactionType(reactionType),
 		CreatedAt: time.Now(),
 	}
-	rxn, noReaction, err := bu.blogReactionRepository.CheckReactionExists(ctx, blogID, userID)
-	if err != nil {
-		log.Printf("Error checking reaction: %v", err)
-		return err
-	}
-
-	goroutineCtx, goroutineCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer goroutineCancel()
 
-	errChan := make(chan error, 1)
-	var wg sync.WaitGroup
-
-	if noReaction {
-		err = bu.blogReactionRepository.AddReaction(ctx, reaction)
+	var previous domain.ReactionType
+	err := bu.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		// deleted, hidden and unpublished blogs take no new reactions
+		blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
 		if err != nil {
-			log.Printf("Error adding reaction: %v", err)
 			return err
 		}
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			var field string
-			if reactionType == string(domain.Like) {
-				field = string(domain.LikeCountField)
-			} else {
-				field = string(domain.DislikeCountField)
-			}
-			if err := bu.blogRepository.UpdateBlogMetrics(goroutineCtx, blogID, field, 1); err != nil {
-				log.Printf("Failed to update %s for blog %s: %v", field, blogID, err)
-				errChan <- fmt.Errorf("failed to update %s: %v", field, err)
-			}
-		}()
-	} else if string(rxn.Type) != reactionType {
-		err = bu.blogReactionRepository.UpdateReaction(ctx, blogID, userID, domain.ReactionType(reactionType))
+		if !blog.IsVisible() {
+			return domain.ErrBlogNotFound
+		}
+		previous, err = bu.blogReactionRepository.SetReaction(ctx, reaction)
 		if err != nil {
-			log.Printf("Error updating reaction: %v", err)
 			return err
 		}
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if reactionType == string(domain.Like) {
-				if err := bu.blogRepository.UpdateBlogMetrics(goroutineCtx, blogID, string(domain.LikeCountField), 1); err != nil {
-					log.Printf("Failed to increment like count for blog %s: %v", blogID, err)
-					errChan <- fmt.Errorf("failed to increment like count: %v", err)
-				}
-				if err := bu.blogRepository.UpdateBlogMetrics(goroutineCtx, blogID, string(domain.DislikeCountField), -1); err != nil {
-					log.Printf("Failed to decrement dislike count for blog %s: %v", blogID, err)
-					errChan <- fmt.Errorf("failed to decrement dislike count: %v", err)
-				}
-			} else if reactionType == string(domain.Dislike) {
-				if err := bu.blogRepository.UpdateBlogMetrics(goroutineCtx, blogID, string(domain.DislikeCountField), 1); err != nil {
-					log.Printf("Failed to increment dislike count for blog %s: %v", blogID, err)
-					errChan <- fmt.Errorf("failed to increment dislike count: %v", err)
-				}
-				if err := bu.blogRepository.UpdateBlogMetrics(goroutineCtx, blogID, string(domain.LikeCountField), -1); err != nil {
-					log.Printf("Failed to decrement like count for blog %s: %v", blogID, err)
-					errChan <- fmt.Errorf("failed to decrement like count: %v", err)
-				}
-			}
-		}()
-	} else {
-		return errors.New("reaction already exists with the same type")
+		return bu.blogRepository.IncrementBlogMetrics(ctx, blogID, reactionCountChanges(previous, reaction.Type))
+	})
+	if err != nil {
+		log.Printf("Error adding reaction: %v", err)
+		return err
+	}
+	if previous == reaction.Type {
+		// a repeated request; nothing changed
+		return nil
 	}
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+	bu.invalidateRecommendations(userID)
 
-	for err := range errChan {
-		if err != nil {
-			return err
+	bu.publishBlogEvent(blogID, domain.EventReaction, reactionEvent{UserID: userID, Reaction: reactionType})
+	bu.publishMetrics(blogID)
+
+	if previous == "" {
+		// changing one's reaction is not a new one
+		bu.recordActivity(blogID, domain.StatsReactions)
+		if reaction.Type != domain.Dislike {
+			bu.bumpTrending(map[string]float64{blogID: trendingReactionWeight})
 		}
 	}
 
-	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+	notifyType := domain.NotifyReaction
+	switch reaction.Type {
+	case domain.Like:
+		notifyType = domain.NotifyLike
+	case domain.Dislike:
+		notifyType = domain.NotifyDislike
+	}
+	bu.notifyBlogAuthor(blogID, userID, notifyType)
 
 	return nil
 }
 
-func (bu *blogUsecase) RemoveReaction(ctx context.Context, blogID, userID string) error {
-	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
-	defer cancel()
-	rxn, noReaction, err := bu.blogReactionRepository.CheckReactionExists(ctx, blogID, userID)
-	if err != nil {
-		return err
-	}
-	err = bu.blogReactionRepository.RemoveReaction(ctx, blogID, userID)
-	if err != nil {
-		return err
-	}
-	if noReaction {
-		return errors.New("no reaction found to remove")
+// reactionCountChanges returns the counter increments for a reaction going
+// from one type to another; "" stands for no reaction. Like and dislike also
+// move their own counters, which sorting and search rely on.
+func reactionCountChanges(from, to domain.ReactionType) map[string]int {
+	changes := map[string]int{}
+	if from == to {
+		return changes
 	}
-	errChan := make(chan error, 1)
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		goroutineCtx, goroutineCancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer goroutineCancel()
-		if string(rxn.Type) == string(domain.Like) {
-			err = bu.blogRepository.UpdateBlogMetrics(goroutineCtx, blogID, string(domain.LikeCountField), -1)
-			if err != nil {
-				errChan <- err
-			}
-		} else if rxn.Type == domain.Dislike {
-			err = bu.blogRepository.UpdateBlogMetrics(goroutineCtx, blogID, string(domain.DislikeCountField), -1)
-			if err != nil {
-				errChan <- err
-			}
-		} else {
-			errChan <- errors.New("reaction type not recognized")
+	if from != "" {
+		changes[reactionTypeCountField(from)]--
+		if field := reactionCountField(from); field != "" {
+			changes[field]--
 		}
-
-	}()
-	go func() {
-		wg.Wait()
-		close(errChan)
-
-	}()
-	for err := range errChan {
-		if err != nil {
-			return err
+	}
+	if to != "" {
+		changes[reactionTypeCountField(to)]++
+		if field := reactionCountField(to); field != "" {
+			changes[field]++
 		}
 	}
-	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+	return changes
+}
 
-	return nil
+func reactionTypeCountField(reactionType domain.ReactionType) string {
+	return string(domain.ReactionCountsField) + "." + string(reactionType)
+}
+
+func reactionCountField(reactionType domain.ReactionType) string {
+	switch reactionType {
+	case domain.Like:
+		return string(domain.LikeCountField)
+	case domain.Dislike:
+		return string(domain.DislikeCountField)
+	}
+	return ""
+}
+
+func (bu *blogUsecase) ReactionTypes() []domain.ReactionType {
+	types := make([]domain.ReactionType, len(bu.reactionTypeOrder))
+	copy(types, bu.reactionTypeOrder)
+	return types
+}
+
+func (bu *blogUsecase) GetReactions(ctx context.Context, blogID string, reactionType domain.ReactionType, cursor string, limit int) ([]*domain.ReactionUser, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	if reactionType != "" && !bu.reactionTypes[reactionType] {
+		return nil, "", domain.ErrInvalidReactionType
+	}
+	blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !blog.IsVisible() {
+		return nil, "", domain.ErrBlogNotFound
+	}
+
+	reactions, next, err := bu.blogReactionRepository.GetReactions(ctx, blogID, reactionType, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	ids := make([]string, len(reactions))
+	for i, reaction := range reactions {
+		ids[i] = reaction.UserID
+	}
+	users, err := bu.userRepository.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, "", err
+	}
+	byID := make(map[string]*domain.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	// reactions of users deleted since are left out
+	result := make([]*domain.ReactionUser, 0, len(reactions))
+	for _, reaction := range reactions {
+		user, ok := byID[reaction.UserID]
+		if !ok {
+			continue
+		}
+		result = append(result, &domain.ReactionUser{
+			UserID:         user.ID,
+			Username:       user.Username,
+			ProfilePicture: user.ProfilePicture,
+			Type:           reaction.Type,
+			ReactedAt:      reaction.CreatedAt,
+		})
+	}
+	return result, next, nil
+}
+
+// reactionEvent is the payload of a reaction event; Reaction is empty when
+// the user removed theirs.
+type reactionEvent struct {
+	UserID   string `json:"user_id"`
+	Reaction string `json:"reaction"`
+}
+
+// commentDeletedEvent is the payload of a comment_deleted event. Deleted
+// counts the comment together with its removed replies.
+type commentDeletedEvent struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parent_id,omitempty"`
+	Deleted  int64  `json:"deleted"`
+}
+
+// commentEvent is the payload of comment and comment_edited events.
+type commentEvent struct {
+	ID        string    `json:"id"`
+	BlogID    string    `json:"blog_id"`
+	AuthorID  string    `json:"author_id"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	Depth     int       `json:"depth"`
+	Content   string    `json:"content"`
+	Edited    bool      `json:"edited"`
+	EditedAt  time.Time `json:"edited_at"`
+	Hidden    bool      `json:"hidden"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newCommentEvent(comment *domain.Comment) commentEvent {
+	return commentEvent{
+		ID:        comment.ID,
+		BlogID:    comment.BlogID,
+		AuthorID:  comment.AuthorID,
+		ParentID:  comment.ParentID,
+		Depth:     comment.Depth,
+		Content:   comment.Content,
+		Edited:    comment.Edited,
+		EditedAt:  comment.EditedAt,
+		Hidden:    comment.Hidden,
+		CreatedAt: comment.CreatedAt,
+	}
+}
+
+type blogMetricsEvent struct {
+	ViewCount    int `json:"view_count"`
+	LikeCount    int `json:"like_count"`
+	DislikeCount int `json:"dislike_count"`
+	CommentCount int `json:"comment_count"`
+	SaveCount    int `json:"save_count"`
+}
+
+func (bu *blogUsecase) publishBlogEvent(blogID string, eventType domain.LiveEventType, data interface{}) {
+	publishInBackground(bu.eventBroker, domain.BlogEventTopic(blogID), &domain.LiveEvent{Type: eventType, Data: data})
+}
+
+// publishMetrics sends the blog's current counters to its stream after one
+// of them changed.
+func (bu *blogUsecase) publishMetrics(blogID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+		if err != nil {
+			log.Printf("Failed to load metrics of blog %s: %v", blogID, err)
+			return
+		}
+		bu.publishBlogEvent(blogID, domain.EventMetrics, blogMetricsEvent{
+			ViewCount:    blog.ViewCount,
+			LikeCount:    blog.LikeCount,
+			DislikeCount: blog.DislikeCount,
+			CommentCount: blog.CommentCount,
+			SaveCount:    blog.SaveCount,
+		})
+	}()
+}
+
+// notifyBlogAuthor tells the author of the blog about an action on it in the
+// background, with the blog title as preview.
+func (bu *blogUsecase) notifyBlogAuthor(blogID, actorID string, notifyType domain.NotificationType) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+		if err != nil {
+			log.Printf("Failed to load blog %s for %s notification: %v", blogID, notifyType, err)
+			return
+		}
+		notifyInBackground(bu.notificationUseCase, &domain.NotificationEvent{
+			RecipientID: blog.AuthorID,
+			ActorID:     actorID,
+			Type:        notifyType,
+			TargetID:    blogID,
+			Preview:     blog.Title,
+		})
+	}()
+}
+
+func (bu *blogUsecase) RemoveReaction(ctx context.Context, blogID, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	err := bu.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		removed, err := bu.blogReactionRepository.DeleteReaction(ctx, blogID, userID)
+		if err != nil {
+			return err
+		}
+		err = bu.blogRepository.IncrementBlogMetrics(ctx, blogID, reactionCountChanges(removed, ""))
+		if err == domain.ErrBlogNotFound {
+			// a deleted blog keeps no counters worth fixing
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+	bu.invalidateRecommendations(userID)
+
+	bu.publishBlogEvent(blogID, domain.EventReaction, reactionEvent{UserID: userID})
+	bu.publishMetrics(blogID)
+
+	return nil
+}
+
+// reactionCountersMatch reports whether the counters stored on a blog agree
+// with the reactions counted for it.
+func reactionCountersMatch(stored *domain.ReactionCounters, actual map[domain.ReactionType]int) bool {
+	if stored.LikeCount != actual[domain.Like] || stored.DislikeCount != actual[domain.Dislike] {
+		return false
+	}
+	for reactionType, count := range actual {
+		if stored.Counts[reactionType] != count {
+			return false
+		}
+	}
+	for reactionType, count := range stored.Counts {
+		if count != actual[reactionType] {
+			return false
+		}
+	}
+	return true
+}
+
+// reconcileBatchSize is how many blogs or comments one reconcile
+// transaction covers.
+const reconcileBatchSize = 200
+
+// reactionCounterStore is where the reconcile job reads and repairs the
+// counters of one kind of reaction target.
+type reactionCounterStore struct {
+	name   string
+	list   func(ctx context.Context, afterID string, limit int) ([]*domain.ReactionCounters, error)
+	count  func(ctx context.Context, ids []string) (map[string]map[domain.ReactionType]int, error)
+	set    func(ctx context.Context, id string, counts map[domain.ReactionType]int) error
+	cached func(id string)
+}
+
+// ReconcileReactionCounts is run periodically to repair reaction counters that drifted from the reactions, e.g. after a failed write on a
+// deployment without transactions.
+func (bu *blogUsecase) ReconcileReactionCounts(ctx context.Context) (int64, error) {
+	stores := []reactionCounterStore{
+		{
+			name:  "blog",
+			list:  bu.blogRepository.ListReactionCounters,
+			count: bu.blogReactionRepository.CountReactionsForBlogs,
+			set:   bu.blogRepository.SetReactionCounts,
+			cached: func(id string) {
+				go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", id))
+			},
+		},
+		{
+			name:  "comment",
+			list:  bu.blogCommentRepository.ListReactionCounters,
+			count: bu.blogReactionRepository.CountReactionsForComments,
+			set:   bu.blogCommentRepository.SetReactionCounts,
+			cached: func(id string) {
+				bu.invalidateCommentsOf(id)
+			},
+		},
+	}
+
+	var fixed int64
+	for _, store := range stores {
+		n, err := bu.reconcileReactionCounters(ctx, store)
+		fixed += n
+		if err != nil {
+			return fixed, err
+		}
+	}
+	return fixed, nil
+}
+
+func (bu *blogUsecase) reconcileReactionCounters(ctx context.Context, store reactionCounterStore) (int64, error) {
+	var fixed int64
+	afterID := ""
+	for {
+		var page []*domain.ReactionCounters
+		var drifted []string
+		batchCtx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+		err := bu.transactionManager.WithTransaction(batchCtx, func(ctx context.Context) error {
+			drifted = nil
+			var err error
+			page, err = store.list(ctx, afterID, reconcileBatchSize)
+			if err != nil || len(page) == 0 {
+				return err
+			}
+			ids := make([]string, len(page))
+			for i, counters := range page {
+				ids[i] = counters.ID
+			}
+			actual, err := store.count(ctx, ids)
+			if err != nil {
+				return err
+			}
+
+			for _, counters := range page {
+				counts := actual[counters.ID]
+				if reactionCountersMatch(counters, counts) {
+					continue
+				}
+				if err := store.set(ctx, counters.ID, counts); err != nil {
+					return err
+				}
+				drifted = append(drifted, counters.ID)
+			}
+			return nil
+		})
+		cancel()
+		if err != nil {
+			return fixed, err
+		}
+
+		fixed += int64(len(drifted))
+		for _, id := range drifted {
+			log.Printf("Reconciled reaction counters of %s %s", store.name, id)
+			store.cached(id)
+		}
+		if len(page) < reconcileBatchSize {
+			return fixed, nil
+		}
+		afterID = page[len(page)-1].ID
+	}
+}
+
+// invalidateCommentsOf drops the cached comment pages of the blog the
+// comment belongs to.
+func (bu *blogUsecase) invalidateCommentsOf(commentID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		comment, err := bu.blogCommentRepository.GetCommentByID(ctx, commentID)
+		if err != nil {
+			log.Printf("Failed to load comment %s to invalidate its cache: %v", commentID, err)
+			return
+		}
+		bu.cacheUseCase.InvalidatePrefix(ctx, fmt.Sprintf("comments:blog:%s:", comment.BlogID))
+	}()
+}
+
+// commentReactionEvent is the payload of a comment_reaction event; Reaction
+// is empty when the user removed theirs.
+type commentReactionEvent struct {
+	CommentID string `json:"comment_id"`
+	UserID    string `json:"user_id"`
+	Reaction  string `json:"reaction"`
+}
+
+// commentReactionChanges returns the per type increments for a reaction on a
+// comment going from one type to another; "" stands for no reaction.
+func commentReactionChanges(from, to domain.ReactionType) map[domain.ReactionType]int {
+	changes := map[domain.ReactionType]int{}
+	if from == to {
+		return changes
+	}
+	if from != "" {
+		changes[from]--
+	}
+	if to != "" {
+		changes[to]++
+	}
+	return changes
+}
+
+// liveComment returns the comment if it is on the blog and readers can see it.
+func (bu *blogUsecase) liveComment(ctx context.Context, blogID, commentID string) (*domain.Comment, error) {
+	comment, err := bu.blogCommentRepository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.BlogID != blogID || comment.Hidden {
+		return nil, domain.ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+func (bu *blogUsecase) ReactToComment(ctx context.Context, blogID, commentID, userID string, reactionType string) error {
+	if _, err := bson.ObjectIDFromHex(commentID); err != nil {
+		return domain.ErrCommentNotFound
+	}
+	if !bu.reactionTypes[domain.ReactionType(reactionType)] {
+		return domain.ErrInvalidReactionType
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	reaction := &domain.Reaction{
+		BlogID:    blogID,
+		CommentID: commentID,
+		UserID:    userID,
+		Type:      domain.ReactionType(reactionType),
+		CreatedAt: time.Now(),
+	}
+
+	var previous domain.ReactionType
+	err := bu.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+		if err != nil {
+			return err
+		}
+		if !blog.IsVisible() {
+			return domain.ErrBlogNotFound
+		}
+		if _, err := bu.liveComment(ctx, blogID, commentID); err != nil {
+			return err
+		}
+		previous, err = bu.blogReactionRepository.SetReaction(ctx, reaction)
+		if err != nil {
+			return err
+		}
+		return bu.blogCommentRepository.IncrementReactionCounts(ctx, commentID, commentReactionChanges(previous, reaction.Type))
+	})
+	if err != nil {
+		return err
+	}
+	if previous == reaction.Type {
+		return nil
+	}
+
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("comments:blog:%s:", blogID))
+	bu.publishBlogEvent(blogID, domain.EventCommentReaction, commentReactionEvent{CommentID: commentID, UserID: userID, Reaction: reactionType})
+
+	return nil
+}
+
+func (bu *blogUsecase) RemoveCommentReaction(ctx context.Context, blogID, commentID, userID string) error {
+	if _, err := bson.ObjectIDFromHex(commentID); err != nil {
+		return domain.ErrCommentNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	err := bu.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		comment, err := bu.blogCommentRepository.GetCommentByID(ctx, commentID)
+		if err != nil {
+			return err
+		}
+		if comment.BlogID != blogID {
+			return domain.ErrCommentNotFound
+		}
+		removed, err := bu.blogReactionRepository.DeleteCommentReaction(ctx, commentID, userID)
+		if err != nil {
+			return err
+		}
+		err = bu.blogCommentRepository.IncrementReactionCounts(ctx, commentID, commentReactionChanges(removed, ""))
+		if err == domain.ErrCommentNotFound {
+			// a deleted comment keeps no counters worth fixing
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("comments:blog:%s:", blogID))
+	bu.publishBlogEvent(blogID, domain.EventCommentReaction, commentReactionEvent{CommentID: commentID, UserID: userID})
+
+	return nil
 }
 
 // Comments
@@ -424,10 +1301,50 @@ func (bu *blogUsecase) AddComment(ctx context.Context, comment *domain.Comment)
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
 
+	if _, err := bson.ObjectIDFromHex(comment.BlogID); err != nil {
+		return nil, domain.ErrBlogNotFound
+	}
+	blog, err := bu.blogRepository.GetBlogByID(ctx, comment.BlogID)
+	if err != nil {
+		return nil, err
+	}
+	if !blog.IsVisible() {
+		return nil, domain.ErrBlogNotFound
+	}
+
+	comment.Ancestors = []string{}
+	comment.Depth = 0
+	comment.ReplyCount = 0
+	var parent *domain.Comment
+	if comment.ParentID != "" {
+		var err error
+		parent, err = bu.blogCommentRepository.GetCommentByID(ctx, comment.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.BlogID != comment.BlogID {
+			return nil, domain.ErrInvalidParentComment
+		}
+		// moderators hid the parent; readers cannot see it to reply to
+		if parent.Hidden {
+			return nil, domain.ErrCommentNotFound
+		}
+		if parent.Depth+1 > domain.MaxCommentDepth {
+			return nil, domain.ErrCommentTooDeep
+		}
+		comment.Ancestors = append(append([]string{}, parent.Ancestors...), parent.ID)
+		comment.Depth = parent.Depth + 1
+	}
+
 	res, err := bu.blogCommentRepository.AddComment(ctx, comment)
 	if err != nil {
 		return nil, err
 	}
+	if comment.ParentID != "" {
+		if err := bu.blogCommentRepository.IncrementReplyCount(ctx, comment.ParentID, 1); err != nil {
+			log.Printf("Error updating reply count for comment %s: %v", comment.ParentID, err)
+		}
+	}
 	errcChan := make(chan error, 1)
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -451,83 +1368,385 @@ func (bu *blogUsecase) AddComment(ctx context.Context, comment *domain.Comment)
 			return nil, err
 		}
 	}
-	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("comments:blog:%s", comment.BlogID))
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("comments:blog:%s:", comment.BlogID))
 	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", comment.BlogID))
 
+	bu.publishBlogEvent(comment.BlogID, domain.EventComment, newCommentEvent(res))
+	bu.publishMetrics(comment.BlogID)
+	bu.recordActivity(comment.BlogID, domain.StatsComments)
+	bu.bumpTrending(map[string]float64{comment.BlogID: trendingCommentWeight})
+	bu.notifyComment(comment, parent)
+
 	return res, nil
 }
 
+// notifyComment tells the blog author about a new comment and, for replies,
+// the author of the parent comment. Someone who is both hears only about the
+// reply.
+func (bu *blogUsecase) notifyComment(comment, parent *domain.Comment) {
+	if parent != nil {
+		notifyInBackground(bu.notificationUseCase, &domain.NotificationEvent{
+			RecipientID: parent.AuthorID,
+			ActorID:     comment.AuthorID,
+			Type:        domain.NotifyReply,
+			TargetID:    parent.ID,
+			Preview:     commentExcerpt(parent.Content),
+		})
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		blog, err := bu.blogRepository.GetBlogByID(ctx, comment.BlogID)
+		if err != nil {
+			log.Printf("Failed to load blog %s for comment notification: %v", comment.BlogID, err)
+			return
+		}
+		if parent != nil && parent.AuthorID == blog.AuthorID {
+			return
+		}
+		notifyInBackground(bu.notificationUseCase, &domain.NotificationEvent{
+			RecipientID: blog.AuthorID,
+			ActorID:     comment.AuthorID,
+			Type:        domain.NotifyComment,
+			TargetID:    comment.BlogID,
+			Preview:     blog.Title,
+		})
+	}()
+}
+
 func (bu *blogUsecase) IsComAuthor(ctx context.Context, comId, userId string) (bool, error) {
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
 	return bu.blogCommentRepository.IsComAuthor(ctx, comId, userId)
 }
 
-func (bu *blogUsecase) GetComments(ctx context.Context, blogID string) ([]*domain.Comment, error) {
+// GetComments returns a page of top-level comments, each with its full reply
+// tree attached.
+func (bu *blogUsecase) GetComments(ctx context.Context, blogID, userID, role string, sort domain.CommentSort, cursor string, limit int) ([]*domain.Comment, string, error) {
+	if sort == "" {
+		sort = domain.CommentSortOldest
+	}
+	if !sort.IsValid() {
+		return nil, "", domain.ErrInvalidCommentSort
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
 
-	cacheKey := fmt.Sprintf("comments:blog:%s", blogID)
+	if _, err := bson.ObjectIDFromHex(blogID); err != nil {
+		return nil, "", domain.ErrBlogNotFound
+	}
+	// comments of a deleted blog go with it, hidden and draft ones stay private
+	blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !blog.IsVisible() && userID != blog.AuthorID && role != string(domain.Admin) {
+		return nil, "", domain.ErrBlogNotFound
+	}
+
+	cacheKey := fmt.Sprintf("comments:blog:%s:sort:%s:cursor:%s:limit:%d", blogID, sort, cursor, limit)
 
 	cachedCommentsBytes, err := bu.cacheUseCase.Get(ctx, cacheKey)
 	if err == nil && cachedCommentsBytes != nil {
-		var comments []*domain.Comment
-		if err := json.Unmarshal(cachedCommentsBytes, &comments); err == nil {
-			return comments, nil
+		var cachedData struct {
+			Comments   []*domain.Comment `json:"comments"`
+			NextCursor string            `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(cachedCommentsBytes, &cachedData); err == nil {
+			return cachedData.Comments, cachedData.NextCursor, nil
 		}
 		log.Printf("Failed to unmarshal cached comments %s: %v", cacheKey, err)
 	} else if err != nil {
 		log.Printf("Error getting comments from cache %s: %v", cacheKey, err)
 	}
 
-	comments, err := bu.blogCommentRepository.GetCommentsForBlog(ctx, blogID)
+	roots, next, err := bu.blogCommentRepository.GetCommentsForBlog(ctx, blogID, sort, cursor, limit)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	rootIDs := make([]string, len(roots))
+	for i, root := range roots {
+		rootIDs[i] = root.ID
+	}
+	replies, err := bu.blogCommentRepository.GetReplies(ctx, rootIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	all := append(roots, replies...)
+	for _, comment := range all {
+		if comment.Hidden {
+			comment.Content = ""
+		}
 	}
+	comments := buildCommentTree(all)
 
-	commentsJSON, err := json.Marshal(comments)
+	dataToCache := struct {
+		Comments   []*domain.Comment `json:"comments"`
+		NextCursor string            `json:"next_cursor"`
+	}{
+		Comments:   comments,
+		NextCursor: next,
+	}
+	commentsJSON, err := json.Marshal(dataToCache)
 	if err == nil {
 		bu.cacheUseCase.Set(ctx, cacheKey, commentsJSON, commentListCacheTTL)
 	} else {
 		log.Printf("Failed to marshal comments for caching: %v", err)
 	}
 
-	return comments, nil
+	return comments, next, nil
 }
 
-func (bu *blogUsecase) GetBlogsByUserID(ctx context.Context, userID string) ([]*domain.Blog, error) {
+func (bu *blogUsecase) GetBlogsByUserID(ctx context.Context, userID string, cursor string, limit int) ([]*domain.Blog, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
 
-	cacheKey := fmt.Sprintf("blogs:user:%s", userID)
+	cacheKey := fmt.Sprintf("blogs:user:%s:cursor:%s:limit:%d", userID, cursor, limit)
 
 	cachedBlogsBytes, err := bu.cacheUseCase.Get(ctx, cacheKey)
 	if err == nil && cachedBlogsBytes != nil {
-		var blogs []*domain.Blog
-		if err := json.Unmarshal(cachedBlogsBytes, &blogs); err == nil {
-			return blogs, nil
+		var cachedData struct {
+			Blogs      []*domain.Blog `json:"blogs"`
+			NextCursor string         `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(cachedBlogsBytes, &cachedData); err == nil {
+			return cachedData.Blogs, cachedData.NextCursor, nil
 		}
 		log.Printf("Failed to unmarshal cached user blogs %s: %v", cacheKey, err)
 	} else if err != nil {
 		log.Printf("Error getting user blogs from cache %s: %v", cacheKey, err)
 	}
 
-	blogs, err := bu.blogRepository.ListBlogsByAuthor(ctx, userID)
+	blogs, next, err := bu.blogRepository.ListBlogsByAuthor(ctx, userID, domain.BlogPublished, cursor, limit)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	blogsJSON, err := json.Marshal(blogs)
+	dataToCache := struct {
+		Blogs      []*domain.Blog `json:"blogs"`
+		NextCursor string         `json:"next_cursor"`
+	}{
+		Blogs:      blogs,
+		NextCursor: next,
+	}
+	blogsJSON, err := json.Marshal(dataToCache)
 	if err == nil {
 		bu.cacheUseCase.Set(ctx, cacheKey, blogsJSON, blogListCacheTTL)
 	} else {
 		log.Printf("Failed to marshal user blogs for caching: %v", err)
 	}
 
-	return blogs, nil
+	return blogs, next, nil
+}
+
+// GetMyBlogs lists the caller's own blogs, including drafts and scheduled
+// posts. It is not cached since only the author ever sees it.
+func (bu *blogUsecase) GetMyBlogs(ctx context.Context, userID string, status domain.BlogStatus, cursor string, limit int) ([]*domain.Blog, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	switch status {
+	case "", domain.BlogDraft, domain.BlogScheduled, domain.BlogPublished, domain.BlogArchived:
+	default:
+		return nil, "", domain.ErrInvalidBlogStatus
+	}
+
+	return bu.blogRepository.ListBlogsByAuthor(ctx, userID, status, cursor, limit)
+}
+
+// ChangeBlogStatus moves a blog through its lifecycle. publishAt is only used
+// when scheduling and must lie in the future.
+func (bu *blogUsecase) ChangeBlogStatus(ctx context.Context, blogID, userID string, status domain.BlogStatus, publishAt time.Time) error {
+	if _, err := bson.ObjectIDFromHex(blogID); err != nil {
+		return domain.ErrBlogNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":     string(status),
+		"updated_at": now,
+	}
+	switch status {
+	case domain.BlogDraft, domain.BlogArchived, domain.BlogPublished:
+		updates["publish_at"] = nil
+	case domain.BlogScheduled:
+		if !publishAt.After(now) {
+			return domain.ErrInvalidPublishTime
+		}
+		updates["publish_at"] = publishAt
+	default:
+		return domain.ErrInvalidBlogStatus
+	}
+
+	blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return err
+	}
+	// a blog published again keeps the date it first went out
+	if status == domain.BlogPublished && blog.PublishedAt.IsZero() {
+		updates["published_at"] = now
+	}
+
+	if err := bu.blogRepository.UpdateBlog(ctx, blogID, userID, updates); err != nil {
+		return err
+	}
+	bu.reindexBlog(ctx, blogID)
+
+	bu.invalidateBlogLists(blog.AuthorID)
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+	if status != domain.BlogPublished {
+		bu.publishBlogEvent(blogID, domain.EventBlogUnavailable, nil)
+	}
+
+	return nil
+}
+
+// PublishDueBlogs is run periodically to publish scheduled blogs whose time
+// has come.
+func (bu *blogUsecase) PublishDueBlogs(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	published, err := bu.blogRepository.PublishDueBlogs(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	for _, blogID := range published {
+		bu.reindexBlog(ctx, blogID)
+	}
+	if len(published) > 0 {
+		bu.invalidateBlogLists("")
+		go bu.cacheUseCase.InvalidatePrefix(context.Background(), "blog:id:")
+	}
+
+	return int64(len(published)), nil
+}
+
+// searchFromIndex runs the query against the search index and loads the
+// matching blogs. Hits that are stale in the index are dropped before the page
+// is cut: the index is asked for the missing hits until the page is full.
+func (bu *blogUsecase) searchFromIndex(ctx context.Context, query domain.SearchQuery) ([]*domain.SearchResult, string, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	results := make([]*domain.SearchResult, 0, limit)
+	for {
+		query.Limit = limit - len(results)
+		hits, next, err := bu.searchIndex.Search(ctx, query)
+		if err != nil {
+			return nil, "", err
+		}
+		ids := make([]string, len(hits))
+		for i, hit := range hits {
+			ids[i] = hit.ID
+		}
+		blogs, err := bu.blogRepository.GetBlogsByIDs(ctx, ids)
+		if err != nil {
+			return nil, "", err
+		}
+		byID := make(map[string]*domain.Blog, len(blogs))
+		for _, blog := range blogs {
+			byID[blog.ID] = blog
+		}
+
+		for _, hit := range hits {
+			blog, ok := byID[hit.ID]
+			if !ok || !blog.IsVisible() {
+				continue
+			}
+			results = append(results, &domain.SearchResult{Blog: blog, Score: hit.Score})
+		}
+		if len(results) >= limit || next == "" {
+			return results, next, nil
+		}
+		query.Cursor = next
+	}
+}
+
+// indexBlog brings the search index up to date with blog: visible blogs are
+// (re)indexed, drafts and hidden blogs are taken out of search. Failures only
+// leave the index stale until the next reindex, so they are logged.
+func (bu *blogUsecase) indexBlog(ctx context.Context, blog *domain.Blog) {
+	var err error
+	if blog.IsVisible() {
+		err = bu.searchIndex.Index(ctx, searchDocument(blog))
+	} else {
+		err = bu.searchIndex.Remove(ctx, blog.ID)
+		// hidden and unpublished blogs only drop out of trending; they
+		// earn their place again with new activity
+		if err := bu.trendingIndex.Remove(ctx, blog.ID); err != nil {
+			log.Printf("Failed to remove blog %s from trending: %v", blog.ID, err)
+		}
+	}
+	if err != nil {
+		log.Printf("Failed to update search index for blog %s: %v", blog.ID, err)
+	}
+}
+
+func (bu *blogUsecase) reindexBlog(ctx context.Context, blogID string) {
+	blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		log.Printf("Failed to load blog %s for the search index: %v", blogID, err)
+		return
+	}
+	bu.indexBlog(ctx, blog)
+}
+
+// RebuildSearchIndex fills index from scratch with every published blog and
+// returns how many were indexed.
+func RebuildSearchIndex(ctx context.Context, blogRepository domain.IBlogRepository, index domain.ISearchIndex) (int, error) {
+	var docs []*domain.SearchDocument
+	cursor := ""
+	for {
+		blogs, next, err := blogRepository.ListBlogs(ctx, cursor, 100, "created_at")
+		if err != nil {
+			return 0, err
+		}
+		for _, blog := range blogs {
+			docs = append(docs, searchDocument(blog))
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if err := index.Rebuild(ctx, docs); err != nil {
+		return 0, err
+	}
+	return len(docs), nil
+}
+
+func searchDocument(blog *domain.Blog) *domain.SearchDocument {
+	return &domain.SearchDocument{
+		ID:        blog.ID,
+		AuthorID:  blog.AuthorID,
+		Title:     blog.Title,
+		Content:   blog.Content,
+		Tags:      blog.Tags,
+		CreatedAt: blog.CreatedAt,
+	}
+}
+
+// invalidateBlogLists drops every cached listing a status change can affect.
+// An empty authorID clears the per-author lists of all users.
+func (bu *blogUsecase) invalidateBlogLists(authorID string) {
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), "blogs:list:")
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), "blogs:search:")
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("blogs:user:%s", authorID))
 }
 
 func (bu *blogUsecase) IsBlogAuthor(ctx context.Context, blogID, userID string) (bool, error) {
+	if _, err := bson.ObjectIDFromHex(blogID); err != nil {
+		return false, domain.ErrBlogNotFound
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
 
@@ -538,16 +1757,151 @@ func (bu *blogUsecase) IsBlogAuthor(ctx context.Context, blogID, userID string)
 	return isAuthor, nil
 }
 
-func (bu *blogUsecase) RemoveComment(ctx context.Context, commentID string) (error) {
+// EditComment lets an author change the text of their own comment. Edited
+// comments are flagged so readers can tell they changed after posting.
+func (bu *blogUsecase) EditComment(ctx context.Context, blogID, commentID, userID, content string) (*domain.Comment, error) {
+	if _, err := bson.ObjectIDFromHex(commentID); err != nil {
+		return nil, domain.ErrCommentNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	existing, err := bu.blogCommentRepository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.BlogID != blogID {
+		return nil, domain.ErrCommentNotFound
+	}
+	if existing.AuthorID != userID {
+		return nil, domain.ErrUserNotAuthorized
+	}
+
+	if err := bu.blogCommentRepository.UpdateCommentContent(ctx, commentID, content); err != nil {
+		return nil, err
+	}
+	comment, err := bu.blogCommentRepository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("comments:blog:%s:", comment.BlogID))
+	if !comment.Hidden {
+		bu.publishBlogEvent(comment.BlogID, domain.EventCommentEdited, newCommentEvent(comment))
+	}
+
+	return comment, nil
+}
+
+// RemoveComment deletes the comment and its whole reply thread, keeping the
+// blog's comment_count and the parent's reply_count in step.
+func (bu *blogUsecase) RemoveComment(ctx context.Context, commentID string, deletedBy string) error {
 	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
 	defer cancel()
-	
-	err := bu.blogCommentRepository.DeleteComment(ctx, commentID)
+
+	comment, err := bu.blogCommentRepository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := bu.blogCommentRepository.DeleteComment(ctx, commentID, deletedBy)
 	if err != nil {
 		return err
 	}
-	go bu.cacheUseCase.InvalidatePrefix(context.Background(), "comments:blog:") 
-	go bu.cacheUseCase.InvalidatePrefix(context.Background(), "blog:id:") 
+	if deleted > 0 {
+		if err := bu.blogRepository.UpdateBlogMetrics(ctx, comment.BlogID, "comment_count", -int(deleted)); err != nil {
+			log.Printf("Error updating comment count for blog %s: %v", comment.BlogID, err)
+		}
+	}
+	if comment.ParentID != "" {
+		if err := bu.blogCommentRepository.IncrementReplyCount(ctx, comment.ParentID, -1); err != nil {
+			log.Printf("Error updating reply count for comment %s: %v", comment.ParentID, err)
+		}
+	}
+
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("comments:blog:%s:", comment.BlogID))
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", comment.BlogID))
+	bu.publishBlogEvent(comment.BlogID, domain.EventCommentDeleted, commentDeletedEvent{ID: comment.ID, ParentID: comment.ParentID, Deleted: deleted})
+	bu.publishMetrics(comment.BlogID)
+
+	return nil
+}
+
+// RestoreComment brings back a deleted comment with the replies removed
+// along with it, undoing the count changes RemoveComment made.
+func (bu *blogUsecase) RestoreComment(ctx context.Context, blogID, commentID string) (*domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	if _, err := bu.blogRepository.GetBlogByID(ctx, blogID); err != nil {
+		return nil, err
+	}
+	comment, restored, err := bu.blogCommentRepository.RestoreComment(ctx, blogID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if restored > 0 {
+		if err := bu.blogRepository.UpdateBlogMetrics(ctx, blogID, "comment_count", int(restored)); err != nil {
+			log.Printf("Error updating comment count for blog %s: %v", blogID, err)
+		}
+	}
+	if comment.ParentID != "" {
+		if err := bu.blogCommentRepository.IncrementReplyCount(ctx, comment.ParentID, 1); err != nil {
+			log.Printf("Error updating reply count for comment %s: %v", comment.ParentID, err)
+		}
+	}
+
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("comments:blog:%s:", blogID))
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+	bu.publishMetrics(blogID)
+
+	return comment, nil
+}
+
+// SetBlogHidden hides a blog from readers on behalf of the moderators, or
+// shows it again. The author keeps access either way.
+func (bu *blogUsecase) SetBlogHidden(ctx context.Context, blogID string, hidden bool) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	if err := bu.blogRepository.SetBlogHidden(ctx, blogID, hidden); err != nil {
+		return err
+	}
+	blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return err
+	}
+	bu.indexBlog(ctx, blog)
+
+	bu.invalidateBlogLists(blog.AuthorID)
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+	if hidden {
+		bu.publishBlogEvent(blogID, domain.EventBlogUnavailable, nil)
+	}
+
+	return nil
+}
+
+// SetCommentHidden withholds a comment's content from readers, or shows it
+// again. Hidden comments keep their place so their replies stay threaded.
+func (bu *blogUsecase) SetCommentHidden(ctx context.Context, commentID string, hidden bool) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	if err := bu.blogCommentRepository.SetCommentHidden(ctx, commentID, hidden); err != nil {
+		return err
+	}
+	comment, err := bu.blogCommentRepository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.Hidden {
+		comment.Content = ""
+	}
+
+	go bu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("comments:blog:%s:", comment.BlogID))
+	bu.publishBlogEvent(comment.BlogID, domain.EventCommentEdited, newCommentEvent(comment))
 
 	return nil
 }
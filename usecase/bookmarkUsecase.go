@@ -0,0 +1,330 @@
+package usecase
+
+import (
+	"blog-backend/domain"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const maxReadingListNameLength = 100
+
+type bookmarkUsecase struct {
+	bookmarkRepository domain.IBookmarkRepository
+	blogRepository     domain.IBlogRepository
+	cacheUseCase       domain.ICacheUseCase
+	contextTimeout     time.Duration
+}
+
+func NewBookmarkUsecase(
+	bookmarkRepository domain.IBookmarkRepository,
+	blogRepository domain.IBlogRepository,
+	timeout time.Duration,
+	cacheUseCase domain.ICacheUseCase,
+) domain.IBookmarkUseCase {
+	return &bookmarkUsecase{
+		bookmarkRepository: bookmarkRepository,
+		blogRepository:     blogRepository,
+		cacheUseCase:       cacheUseCase,
+		contextTimeout:     timeout,
+	}
+}
+
+func (bu *bookmarkUsecase) GetReadingLists(ctx context.Context, userID string) ([]*domain.ReadingList, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	// make sure a brand new user sees their default list too
+	if _, err := bu.defaultList(ctx, userID); err != nil {
+		return nil, err
+	}
+	lists, err := bu.bookmarkRepository.GetLists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := bu.bookmarkRepository.CountBookmarksByList(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		list.ItemCount = counts[list.ID]
+	}
+	return lists, nil
+}
+
+func (bu *bookmarkUsecase) CreateReadingList(ctx context.Context, userID, name, description string) (*domain.ReadingList, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	name, err := readingListName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return bu.bookmarkRepository.CreateList(ctx, &domain.ReadingList{
+		UserID:      userID,
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+func (bu *bookmarkUsecase) UpdateReadingList(ctx context.Context, userID, listID string, update domain.ReadingListUpdate) (*domain.ReadingList, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	list, err := bu.bookmarkRepository.GetList(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if update.Description != nil {
+		updates["description"] = strings.TrimSpace(*update.Description)
+	}
+	if update.Name != nil && strings.TrimSpace(*update.Name) != list.Name {
+		if list.IsDefault {
+			return nil, domain.ErrDefaultReadingList
+		}
+		if updates["name"], err = readingListName(*update.Name); err != nil {
+			return nil, err
+		}
+	}
+	if err := bu.bookmarkRepository.UpdateList(ctx, userID, listID, updates); err != nil {
+		return nil, err
+	}
+
+	return bu.bookmarkRepository.GetList(ctx, userID, listID)
+}
+
+// DeleteReadingList removes a list together with the bookmarks in it.
+func (bu *bookmarkUsecase) DeleteReadingList(ctx context.Context, userID, listID string) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	list, err := bu.bookmarkRepository.GetList(ctx, userID, listID)
+	if err != nil {
+		return err
+	}
+	if list.IsDefault {
+		return domain.ErrDefaultReadingList
+	}
+
+	removed, err := bu.bookmarkRepository.DeleteBookmarksInList(ctx, listID)
+	if err != nil {
+		return err
+	}
+	for _, bookmark := range removed {
+		bu.updateSaveCount(ctx, bookmark.BlogID, -1)
+	}
+
+	return bu.bookmarkRepository.DeleteList(ctx, userID, listID)
+}
+
+// ReorderReadingList sets the order of a list. blogIDs has to name every blog
+// in the list exactly once.
+func (bu *bookmarkUsecase) ReorderReadingList(ctx context.Context, userID, listID string, blogIDs []string) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	list, err := bu.resolveList(ctx, userID, listID)
+	if err != nil {
+		return err
+	}
+	current, err := bu.bookmarkRepository.GetListBlogIDs(ctx, list.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(current) != len(blogIDs) {
+		return domain.ErrInvalidBookmarkOrder
+	}
+	inList := make(map[string]bool, len(current))
+	for _, id := range current {
+		inList[id] = true
+	}
+	for _, id := range blogIDs {
+		if !inList[id] {
+			return domain.ErrInvalidBookmarkOrder
+		}
+		// a repeated id would leave another bookmark out
+		delete(inList, id)
+	}
+
+	return bu.bookmarkRepository.SetPositions(ctx, list.ID, blogIDs)
+}
+
+func (bu *bookmarkUsecase) AddBookmark(ctx context.Context, userID, blogID, listID, note string) (*domain.Bookmark, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	blog, err := bu.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, err
+	}
+	if !blog.IsVisible() {
+		return nil, domain.ErrBlogNotFound
+	}
+
+	list, err := bu.resolveList(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+	position, err := bu.bookmarkRepository.NextPosition(ctx, list.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	bookmark, err := bu.bookmarkRepository.AddBookmark(ctx, &domain.Bookmark{
+		UserID:    userID,
+		BlogID:    blogID,
+		ListID:    list.ID,
+		Position:  position,
+		Note:      strings.TrimSpace(note),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bu.updateSaveCount(ctx, blogID, 1)
+
+	bookmark.Blog = blog
+	return bookmark, nil
+}
+
+// GetBookmarks returns one page of a reading list with the blogs filled in.
+// Blogs that are no longer published are left out as nil.
+func (bu *bookmarkUsecase) GetBookmarks(ctx context.Context, userID, listID string, cursor string, limit int) ([]*domain.Bookmark, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	list, err := bu.resolveList(ctx, userID, listID)
+	if err != nil {
+		return nil, "", err
+	}
+	bookmarks, next, err := bu.bookmarkRepository.GetBookmarks(ctx, list.ID, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	blogIDs := make([]string, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		blogIDs[i] = bookmark.BlogID
+	}
+	blogs, err := bu.blogRepository.GetBlogsByIDs(ctx, blogIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	byID := make(map[string]*domain.Blog, len(blogs))
+	for _, blog := range blogs {
+		if blog.IsVisible() {
+			byID[blog.ID] = blog
+		}
+	}
+	for _, bookmark := range bookmarks {
+		bookmark.Blog = byID[bookmark.BlogID]
+	}
+
+	return bookmarks, next, nil
+}
+
+func (bu *bookmarkUsecase) UpdateBookmark(ctx context.Context, userID, blogID string, update domain.BookmarkUpdate) (*domain.Bookmark, error) {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	bookmark, err := bu.bookmarkRepository.GetBookmark(ctx, userID, blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if update.Note != nil {
+		updates["note"] = strings.TrimSpace(*update.Note)
+	}
+	if update.ListID != nil {
+		list, err := bu.resolveList(ctx, userID, *update.ListID)
+		if err != nil {
+			return nil, err
+		}
+		if list.ID != bookmark.ListID {
+			position, err := bu.bookmarkRepository.NextPosition(ctx, list.ID)
+			if err != nil {
+				return nil, err
+			}
+			updates["list_id"] = list.ID
+			updates["position"] = position
+		}
+	}
+	if err := bu.bookmarkRepository.UpdateBookmark(ctx, userID, blogID, updates); err != nil {
+		return nil, err
+	}
+
+	return bu.bookmarkRepository.GetBookmark(ctx, userID, blogID)
+}
+
+func (bu *bookmarkUsecase) RemoveBookmark(ctx context.Context, userID, blogID string) error {
+	ctx, cancel := context.WithTimeout(ctx, bu.contextTimeout)
+	defer cancel()
+
+	if err := bu.bookmarkRepository.DeleteBookmark(ctx, userID, blogID); err != nil {
+		return err
+	}
+	bu.updateSaveCount(ctx, blogID, -1)
+	return nil
+}
+
+// defaultList returns the user's default list, creating it on first use.
+func (bu *bookmarkUsecase) defaultList(ctx context.Context, userID string) (*domain.ReadingList, error) {
+	list, err := bu.bookmarkRepository.GetDefaultList(ctx, userID)
+	if err != domain.ErrReadingListNotFound {
+		return list, err
+	}
+
+	now := time.Now()
+	list, err = bu.bookmarkRepository.CreateList(ctx, &domain.ReadingList{
+		UserID:    userID,
+		Name:      domain.DefaultReadingListName,
+		IsDefault: true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err == domain.ErrReadingListExists {
+		// a concurrent request created it first
+		return bu.bookmarkRepository.GetDefaultList(ctx, userID)
+	}
+	return list, err
+}
+
+func (bu *bookmarkUsecase) resolveList(ctx context.Context, userID, listID string) (*domain.ReadingList, error) {
+	if listID == "" {
+		return bu.defaultList(ctx, userID)
+	}
+	return bu.bookmarkRepository.GetList(ctx, userID, listID)
+}
+
+// updateSaveCount keeps Blog.SaveCount in step with the bookmarks. A failure
+// only skews the counter, so it is logged rather than returned.
+func (bu *bookmarkUsecase) updateSaveCount(ctx context.Context, blogID string, increment int) {
+	if err := bu.blogRepository.UpdateBlogMetrics(ctx, blogID, string(domain.SaveCountField), increment); err != nil {
+		log.Printf("Failed to update save count for blog %s: %v", blogID, err)
+		return
+	}
+	go bu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+}
+
+func readingListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxReadingListNameLength {
+		return "", domain.ErrInvalidReadingListName
+	}
+	if strings.EqualFold(name, domain.DefaultReadingListName) {
+		return "", domain.ErrReadingListExists
+	}
+	return name, nil
+}
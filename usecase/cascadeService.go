@@ -0,0 +1,484 @@
+package usecase
+
+import (
+	"blog-backend/domain"
+	"context"
+	"fmt"
+	"log"
+)
+
+type cascadeService struct {
+	transactionManager     domain.ITransactionManager
+	blogRepository         domain.IBlogRepository
+	commentRepository      domain.ICommentRepository
+	reactionRepository     domain.IReactionRepository
+	historyRepository      domain.IHistoryRepository
+	revisionRepository     domain.IBlogRevisionRepository
+	bookmarkRepository     domain.IBookmarkRepository
+	statsRepository        domain.IStatsRepository
+	followRepository       domain.IFollowRepository
+	notificationRepository domain.INotificationRepository
+	userRepository         domain.IUserRepository
+	refreshTokenRepository domain.IRefreshTokenRepository
+	twoFactorRepository    domain.ITwoFactorRepository
+	searchIndex            domain.ISearchIndex
+	cacheUseCase           domain.ICacheUseCase
+}
+
+func NewCascadeService(
+	transactionManager domain.ITransactionManager,
+	blogRepository domain.IBlogRepository,
+	commentRepository domain.ICommentRepository,
+	reactionRepository domain.IReactionRepository,
+	historyRepository domain.IHistoryRepository,
+	revisionRepository domain.IBlogRevisionRepository,
+	bookmarkRepository domain.IBookmarkRepository,
+	statsRepository domain.IStatsRepository,
+	followRepository domain.IFollowRepository,
+	notificationRepository domain.INotificationRepository,
+	userRepository domain.IUserRepository,
+	refreshTokenRepository domain.IRefreshTokenRepository,
+	twoFactorRepository domain.ITwoFactorRepository,
+	searchIndex domain.ISearchIndex,
+	cacheUseCase domain.ICacheUseCase,
+) domain.ICascadeService {
+	return &cascadeService{
+		transactionManager:     transactionManager,
+		blogRepository:         blogRepository,
+		commentRepository:      commentRepository,
+		reactionRepository:     reactionRepository,
+		historyRepository:      historyRepository,
+		revisionRepository:     revisionRepository,
+		bookmarkRepository:     bookmarkRepository,
+		statsRepository:        statsRepository,
+		followRepository:       followRepository,
+		notificationRepository: notificationRepository,
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		twoFactorRepository:    twoFactorRepository,
+		searchIndex:            searchIndex,
+		cacheUseCase:           cacheUseCase,
+	}
+}
+
+// cascadeEffects collects what a cascade touched, so counters can be
+// recalculated inside the transaction and caches and the search index
+// updated once it has committed.
+type cascadeEffects struct {
+	removedBlogs    map[string]bool
+	reindexedBlogs  []string        // blogs kept that changed author or came back
+	blogs           map[string]bool // blogs whose counters changed
+	comments        map[string]bool // parent comments whose reply count changed
+	reactedComments map[string]bool // comments whose reaction counts changed
+	removedComments map[string]bool
+	users           map[string]bool // users whose follow counts changed
+}
+
+func newCascadeEffects() *cascadeEffects {
+	return &cascadeEffects{
+		removedBlogs: map[string]bool{},
+		blogs:        map[string]bool{},
+		comments:     map[string]bool{},
+		users:        map[string]bool{},
+
+		reactedComments: map[string]bool{},
+		removedComments: map[string]bool{},
+	}
+}
+
+func addAll(set map[string]bool, ids []string) {
+	for _, id := range ids {
+		set[id] = true
+	}
+}
+
+func (cs *cascadeService) PurgeBlogs(ctx context.Context, blogIDs []string) (int64, error) {
+	if len(blogIDs) == 0 {
+		return 0, nil
+	}
+
+	var purged []string
+	err := cs.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		purged, err = cs.purgeBlogs(ctx, blogIDs)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	effects := newCascadeEffects()
+	addAll(effects.removedBlogs, purged)
+	cs.publishEffects(ctx, effects)
+	return int64(len(purged)), nil
+}
+
+// purgeBlogs removes the blogs and everything hanging off them, returning the
+// ids of the blogs it removed. Without a replica set the transaction is just
+// plain writes, so blogs restored since they were listed are dropped first and
+// dependents are only removed for the blogs that were actually purged.
+func (cs *cascadeService) purgeBlogs(ctx context.Context, blogIDs []string) ([]string, error) {
+	blogIDs, err := cs.blogRepository.FilterDeletedBlogIDs(ctx, blogIDs)
+	if err != nil || len(blogIDs) == 0 {
+		return nil, err
+	}
+	blogIDs, err = cs.blogRepository.PurgeBlogs(ctx, blogIDs)
+	if err != nil || len(blogIDs) == 0 {
+		return nil, err
+	}
+	if _, err := cs.commentRepository.DeleteCommentsForBlogs(ctx, blogIDs); err != nil {
+		return nil, err
+	}
+	if _, err := cs.reactionRepository.DeleteReactionsForBlogs(ctx, blogIDs); err != nil {
+		return nil, err
+	}
+	if err := cs.revisionRepository.DeleteRevisionsForBlogs(ctx, blogIDs); err != nil {
+		return nil, err
+	}
+	if err := cs.historyRepository.RemoveBlogsFromHistory(ctx, blogIDs); err != nil {
+		return nil, err
+	}
+	for _, id := range blogIDs {
+		if _, err := cs.bookmarkRepository.DeleteBookmarksForBlog(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := cs.notificationRepository.DeleteNotificationsForTargets(ctx, blogIDs); err != nil {
+		return nil, err
+	}
+	if err := cs.statsRepository.DeleteStatsForBlogs(ctx, blogIDs); err != nil {
+		return nil, err
+	}
+	return blogIDs, nil
+}
+
+func (cs *cascadeService) PurgeComments(ctx context.Context, commentIDs []string) (int64, error) {
+	if len(commentIDs) == 0 {
+		return 0, nil
+	}
+
+	var purged int64
+	err := cs.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := cs.reactionRepository.DeleteReactionsForComments(ctx, commentIDs); err != nil {
+			return err
+		}
+		if err := cs.notificationRepository.DeleteNotificationsForTargets(ctx, commentIDs); err != nil {
+			return err
+		}
+		var err error
+		purged, err = cs.commentRepository.PurgeComments(ctx, commentIDs)
+		return err
+	})
+	return purged, err
+}
+
+func (cs *cascadeService) PurgeUser(ctx context.Context, user *domain.DeletedUser) error {
+	var ghostID string
+	switch user.ContentAction {
+	case domain.TransferUserContent:
+		ghost, err := cs.userRepository.EnsureGhostUser(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to prepare the ghost account: %w", err)
+		}
+		ghostID = ghost.ID
+	case domain.DeleteUserContent:
+	default:
+		return domain.ErrInvalidContentAction
+	}
+
+	var effects *cascadeEffects
+	err := cs.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		// start afresh each time in case the transaction is retried
+		effects = newCascadeEffects()
+		return cs.purgeUser(ctx, user, ghostID, effects)
+	})
+	if err != nil {
+		return err
+	}
+
+	effects.users[user.ID] = true
+	if ghostID != "" {
+		effects.users[ghostID] = true
+	}
+	cs.publishEffects(ctx, effects)
+	return nil
+}
+
+func (cs *cascadeService) purgeUser(ctx context.Context, user *domain.DeletedUser, ghostID string, effects *cascadeEffects) error {
+	blogIDs, err := cs.blogRepository.ListBlogIDsByAuthor(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if user.ContentAction == domain.DeleteUserContent {
+		for _, id := range blogIDs {
+			// blogs must be soft deleted before they can be purged
+			if err := cs.blogRepository.DeleteBlog(ctx, id, user.DeletedBy); err != nil && err != domain.ErrBlogNotFound {
+				return err
+			}
+		}
+		purged, err := cs.purgeBlogs(ctx, blogIDs)
+		if err != nil {
+			return err
+		}
+		addAll(effects.removedBlogs, purged)
+
+		removal, err := cs.commentRepository.DeleteCommentsByAuthor(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := cs.reactionRepository.DeleteReactionsForComments(ctx, removal.CommentIDs); err != nil {
+			return err
+		}
+		if err := cs.notificationRepository.DeleteNotificationsForTargets(ctx, removal.CommentIDs); err != nil {
+			return err
+		}
+		addAll(effects.blogs, removal.BlogIDs)
+		addAll(effects.comments, removal.ParentIDs)
+		addAll(effects.removedComments, removal.CommentIDs)
+	} else {
+		if _, err := cs.blogRepository.TransferBlogs(ctx, user.ID, ghostID); err != nil {
+			return err
+		}
+		if _, err := cs.commentRepository.TransferComments(ctx, user.ID, ghostID); err != nil {
+			return err
+		}
+		effects.reindexedBlogs = blogIDs
+	}
+
+	if err := cs.refreshTokenRepository.DeleteRefreshTokensForUser(ctx, user.ID); err != nil && err != domain.ErrTokenNotFound {
+		return err
+	}
+	if err := cs.twoFactorRepository.DeleteTwoFactor(ctx, user.ID); err != nil && err != domain.ErrTwoFactorNotEnabled {
+		return err
+	}
+	if err := cs.historyRepository.DeleteHistoryForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	bookmarks, err := cs.bookmarkRepository.DeleteBookmarksForUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	for _, bookmark := range bookmarks {
+		effects.blogs[bookmark.BlogID] = true
+	}
+	if err := cs.bookmarkRepository.DeleteListsForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	followUserIDs, err := cs.followRepository.DeleteFollowsOfUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	addAll(effects.users, followUserIDs)
+
+	if err := cs.notificationRepository.DeleteNotificationsForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	reactedBlogIDs, reactedCommentIDs, err := cs.reactionRepository.DeleteReactionsByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	addAll(effects.blogs, reactedBlogIDs)
+	addAll(effects.reactedComments, reactedCommentIDs)
+
+	if err := cs.recalculate(ctx, effects); err != nil {
+		return err
+	}
+
+	// last, so that a restore in the meantime turns up as ErrUserNotFound.
+	// With a transaction that rolls the cascade back; without one, what was
+	// removed up to here stays removed.
+	return cs.userRepository.PurgeUser(ctx, user.ID)
+}
+
+func (cs *cascadeService) HideUserContent(ctx context.Context, user *domain.DeletedUser) error {
+	if user.ContentAction != domain.DeleteUserContent {
+		return nil
+	}
+
+	var effects *cascadeEffects
+	err := cs.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		effects = newCascadeEffects()
+		blogIDs, err := cs.blogRepository.DeleteBlogsByAuthor(ctx, user.ID, user.DeletedBy, user.DeletedAt)
+		if err != nil {
+			return err
+		}
+		addAll(effects.removedBlogs, blogIDs)
+
+		removal, err := cs.commentRepository.SoftDeleteCommentsByAuthor(ctx, user.ID, user.DeletedBy, user.DeletedAt)
+		if err != nil {
+			return err
+		}
+		addAll(effects.blogs, removal.BlogIDs)
+		addAll(effects.comments, removal.ParentIDs)
+		return cs.recalculate(ctx, effects)
+	})
+	if err != nil {
+		return err
+	}
+	effects.users[user.ID] = true
+	cs.publishEffects(ctx, effects)
+	return nil
+}
+
+// RestoreUserContent only brings back content deleted at the moment the
+// account was, so blogs and comments the user deleted earlier stay deleted.
+func (cs *cascadeService) RestoreUserContent(ctx context.Context, user *domain.DeletedUser) error {
+	if user.ContentAction != domain.DeleteUserContent {
+		return nil
+	}
+
+	var effects *cascadeEffects
+	err := cs.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		effects = newCascadeEffects()
+		blogIDs, err := cs.blogRepository.RestoreBlogsByAuthor(ctx, user.ID, user.DeletedAt)
+		if err != nil {
+			return err
+		}
+		effects.reindexedBlogs = blogIDs
+
+		removal, err := cs.commentRepository.RestoreCommentsByAuthor(ctx, user.ID, user.DeletedAt)
+		if err != nil {
+			return err
+		}
+		addAll(effects.blogs, removal.BlogIDs)
+		addAll(effects.comments, removal.ParentIDs)
+		return cs.recalculate(ctx, effects)
+	})
+	if err != nil {
+		return err
+	}
+	effects.users[user.ID] = true
+	cs.publishEffects(ctx, effects)
+	return nil
+}
+
+// recalculate recounts the counters fed by the removed documents rather
+// than decrementing them, which also repairs any earlier drift.
+func (cs *cascadeService) recalculate(ctx context.Context, effects *cascadeEffects) error {
+	for blogID := range effects.blogs {
+		if effects.removedBlogs[blogID] {
+			continue
+		}
+		reactions, err := cs.reactionRepository.CountReactions(ctx, blogID)
+		if err != nil {
+			return err
+		}
+		comments, err := cs.commentRepository.CountComments(ctx, blogID)
+		if err != nil {
+			return err
+		}
+		saves, err := cs.bookmarkRepository.CountBookmarksForBlog(ctx, blogID)
+		if err != nil {
+			return err
+		}
+		err = cs.blogRepository.SetReactionCounts(ctx, blogID, reactions)
+		if err == domain.ErrBlogNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = cs.blogRepository.SetBlogMetrics(ctx, blogID, map[string]int{
+			"comment_count": int(comments),
+			"save_count":    int(saves),
+		})
+		if err != nil && err != domain.ErrBlogNotFound {
+			return err
+		}
+	}
+
+	var reactedComments []string
+	for commentID := range effects.reactedComments {
+		if !effects.removedComments[commentID] {
+			reactedComments = append(reactedComments, commentID)
+		}
+	}
+	if len(reactedComments) > 0 {
+		counts, err := cs.reactionRepository.CountReactionsForComments(ctx, reactedComments)
+		if err != nil {
+			return err
+		}
+		for _, commentID := range reactedComments {
+			err := cs.commentRepository.SetReactionCounts(ctx, commentID, counts[commentID])
+			if err != nil && err != domain.ErrCommentNotFound {
+				return err
+			}
+		}
+	}
+
+	for commentID := range effects.comments {
+		replies, err := cs.commentRepository.CountReplies(ctx, commentID)
+		if err != nil {
+			return err
+		}
+		if err := cs.commentRepository.SetReplyCount(ctx, commentID, int(replies)); err != nil {
+			return err
+		}
+	}
+
+	for userID := range effects.users {
+		followers, err := cs.followRepository.CountFollowers(ctx, userID)
+		if err != nil {
+			return err
+		}
+		following, err := cs.followRepository.CountFollowing(ctx, userID)
+		if err != nil {
+			return err
+		}
+		err = cs.userRepository.SetUserMetrics(ctx, userID, map[string]int{
+			"follower_count":  int(followers),
+			"following_count": int(following),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishEffects brings the search index and caches in line with a cascade
+// that has committed. Failures are only logged; the data is already right.
+func (cs *cascadeService) publishEffects(ctx context.Context, effects *cascadeEffects) {
+	for blogID := range effects.removedBlogs {
+		if err := cs.searchIndex.Remove(ctx, blogID); err != nil {
+			log.Printf("Failed to remove blog %s from the search index: %v", blogID, err)
+		}
+		effects.blogs[blogID] = true
+	}
+
+	if len(effects.reindexedBlogs) > 0 {
+		blogs, err := cs.blogRepository.GetBlogsByIDs(ctx, effects.reindexedBlogs)
+		if err != nil {
+			log.Printf("Failed to load blogs for the search index: %v", err)
+		}
+		for _, blog := range blogs {
+			if !blog.IsVisible() {
+				continue
+			}
+			if err := cs.searchIndex.Index(ctx, searchDocument(blog)); err != nil {
+				log.Printf("Failed to update search index for blog %s: %v", blog.ID, err)
+			}
+		}
+		addAll(effects.blogs, effects.reindexedBlogs)
+	}
+
+	for blogID := range effects.blogs {
+		go cs.cacheUseCase.Delete(context.Background(), fmt.Sprintf("blog:id:%s", blogID))
+		go cs.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("comments:blog:%s:", blogID))
+	}
+	for userID := range effects.users {
+		go cs.cacheUseCase.Delete(context.Background(), fmt.Sprintf("user:id:%s", userID))
+		go cs.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("blogs:user:%s", userID))
+	}
+	if len(effects.blogs) > 0 {
+		go cs.cacheUseCase.InvalidatePrefix(context.Background(), "blogs:list:")
+		go cs.cacheUseCase.InvalidatePrefix(context.Background(), "blogs:search:")
+	}
+	if len(effects.users) > 0 {
+		go cs.cacheUseCase.InvalidatePrefix(context.Background(), "users:list:")
+	}
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"blog-backend/domain"
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// blogStreamRecheck is how often an open blog stream checks that its
+// subscriber can still see the blog, for changes that were not signalled with
+// EventBlogUnavailable.
+const blogStreamRecheck = 30 * time.Second
+
+type eventUsecase struct {
+	eventBroker    domain.IEventBroker
+	blogRepository domain.IBlogRepository
+	contextTimeout time.Duration
+}
+
+func NewEventUsecase(eventBroker domain.IEventBroker, blogRepository domain.IBlogRepository, timeout time.Duration) domain.IEventUseCase {
+	return &eventUsecase{
+		eventBroker:    eventBroker,
+		blogRepository: blogRepository,
+		contextTimeout: timeout,
+	}
+}
+
+func (eu *eventUsecase) SubscribeBlog(ctx context.Context, blogID, userID, role string) (<-chan *domain.LiveEvent, error) {
+	if _, err := bson.ObjectIDFromHex(blogID); err != nil {
+		return nil, domain.ErrBlogNotFound
+	}
+	if err := eu.checkBlogAccess(ctx, blogID, userID, role); err != nil {
+		return nil, err
+	}
+
+	// the subscription lives as long as the stream, not the lookup timeout
+	ctx, cancel := context.WithCancel(ctx)
+	events, err := eu.eventBroker.Subscribe(ctx, domain.BlogEventTopic(blogID))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stream := make(chan *domain.LiveEvent)
+	go eu.forwardBlogEvents(ctx, cancel, events, stream, blogID, userID, role)
+	return stream, nil
+}
+
+// forwardBlogEvents passes the blog's events on until the subscription ends
+// or the subscriber can no longer see the blog.
+func (eu *eventUsecase) forwardBlogEvents(ctx context.Context, cancel context.CancelFunc, events <-chan *domain.LiveEvent, stream chan<- *domain.LiveEvent, blogID, userID, role string) {
+	defer close(stream)
+	defer cancel()
+
+	recheck := time.NewTicker(blogStreamRecheck)
+	defer recheck.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Type == domain.EventBlogUnavailable {
+				if eu.checkBlogAccess(ctx, blogID, userID, role) == domain.ErrBlogNotFound {
+					return
+				}
+				continue
+			}
+			select {
+			case stream <- event:
+			case <-ctx.Done():
+				return
+			}
+		case <-recheck.C:
+			if eu.checkBlogAccess(ctx, blogID, userID, role) == domain.ErrBlogNotFound {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkBlogAccess returns ErrBlogNotFound unless the blog is there and the
+// user may follow it.
+func (eu *eventUsecase) checkBlogAccess(ctx context.Context, blogID, userID, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, eu.contextTimeout)
+	defer cancel()
+
+	blog, err := eu.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return err
+	}
+	if !blog.IsVisible() && userID != blog.AuthorID && role != string(domain.Admin) {
+		return domain.ErrBlogNotFound
+	}
+	return nil
+}
+
+func (eu *eventUsecase) SubscribeUser(ctx context.Context, userID string) (<-chan *domain.LiveEvent, error) {
+	return eu.eventBroker.Subscribe(ctx, domain.UserEventTopic(userID))
+}
+
+// publishInBackground sends the event without holding up the change that
+// caused it; live updates are best effort.
+func publishInBackground(broker domain.IEventBroker, topic string, event *domain.LiveEvent) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := broker.Publish(ctx, topic, event); err != nil {
+			log.Printf("Failed to publish %s event on %s: %v", event.Type, topic, err)
+		}
+	}()
+}
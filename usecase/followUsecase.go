@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"blog-backend/domain"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	feedCacheTTL = 2 * time.Minute // TTL for pages of a user's home feed
+
+	// every feedRecommendationEvery-th item of a feed page is a recommendation
+	// while followed authors still have posts to show
+	feedRecommendationEvery = 4
+	// how many of the user's recommendations the feed draws from
+	feedRecommendations = 20
+)
+
+type followUsecase struct {
+	followRepository      domain.IFollowRepository
+	userRepository        domain.IUserRepository
+	blogRepository        domain.IBlogRepository
+	recommendationUseCase domain.IRecommendationUseCase
+	notificationUseCase   domain.INotificationUseCase
+	cacheUseCase          domain.ICacheUseCase
+	contextTimeout        time.Duration
+}
+
+func NewFollowUsecase(
+	followRepository domain.IFollowRepository,
+	userRepository domain.IUserRepository,
+	blogRepository domain.IBlogRepository,
+	recommendationUseCase domain.IRecommendationUseCase,
+	notificationUseCase domain.INotificationUseCase,
+	timeout time.Duration,
+	cacheUseCase domain.ICacheUseCase,
+) domain.IFollowUseCase {
+	return &followUsecase{
+		followRepository:      followRepository,
+		userRepository:        userRepository,
+		blogRepository:        blogRepository,
+		recommendationUseCase: recommendationUseCase,
+		notificationUseCase:   notificationUseCase,
+		cacheUseCase:          cacheUseCase,
+		contextTimeout:        timeout,
+	}
+}
+
+func (fu *followUsecase) Follow(ctx context.Context, followerID, followeeID string) error {
+	ctx, cancel := context.WithTimeout(ctx, fu.contextTimeout)
+	defer cancel()
+
+	if followerID == followeeID {
+		return domain.ErrCannotFollowSelf
+	}
+	if _, err := bson.ObjectIDFromHex(followeeID); err != nil {
+		return domain.ErrUserNotFound
+	}
+	if _, err := fu.userRepository.GetUserByID(ctx, followeeID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+
+	err := fu.followRepository.Follow(ctx, &domain.Follow{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	fu.updateFollowCounts(ctx, followerID, followeeID, 1)
+	notifyInBackground(fu.notificationUseCase, &domain.NotificationEvent{
+		RecipientID: followeeID,
+		ActorID:     followerID,
+		Type:        domain.NotifyFollow,
+	})
+	return nil
+}
+
+func (fu *followUsecase) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	ctx, cancel := context.WithTimeout(ctx, fu.contextTimeout)
+	defer cancel()
+
+	if err := fu.followRepository.Unfollow(ctx, followerID, followeeID); err != nil {
+		return err
+	}
+
+	fu.updateFollowCounts(ctx, followerID, followeeID, -1)
+	return nil
+}
+
+// updateFollowCounts keeps the counters on both users in step with the
+// follows collection and drops everything cached from the old state.
+func (fu *followUsecase) updateFollowCounts(ctx context.Context, followerID, followeeID string, increment int) {
+	if err := fu.userRepository.UpdateUserMetrics(ctx, followerID, string(domain.FollowingCountField), increment); err != nil {
+		log.Printf("Failed to update following count of user %s: %v", followerID, err)
+	}
+	if err := fu.userRepository.UpdateUserMetrics(ctx, followeeID, string(domain.FollowerCountField), increment); err != nil {
+		log.Printf("Failed to update follower count of user %s: %v", followeeID, err)
+	}
+
+	go fu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("user:id:%s", followerID))
+	go fu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("user:id:%s", followeeID))
+	go fu.cacheUseCase.InvalidatePrefix(context.Background(), fmt.Sprintf("feed:user:%s:", followerID))
+}
+
+func (fu *followUsecase) GetFollowers(ctx context.Context, userID string, cursor string, limit int) ([]*domain.FollowUser, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fu.contextTimeout)
+	defer cancel()
+
+	follows, next, err := fu.followRepository.GetFollowers(ctx, userID, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	ids := make([]string, len(follows))
+	for i, follow := range follows {
+		ids[i] = follow.FollowerID
+	}
+	users, err := fu.followUsers(ctx, ids, follows)
+	if err != nil {
+		return nil, "", err
+	}
+	return users, next, nil
+}
+
+func (fu *followUsecase) GetFollowing(ctx context.Context, userID string, cursor string, limit int) ([]*domain.FollowUser, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fu.contextTimeout)
+	defer cancel()
+
+	follows, next, err := fu.followRepository.GetFollowing(ctx, userID, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	ids := make([]string, len(follows))
+	for i, follow := range follows {
+		ids[i] = follow.FolloweeID
+	}
+	users, err := fu.followUsers(ctx, ids, follows)
+	if err != nil {
+		return nil, "", err
+	}
+	return users, next, nil
+}
+
+// followUsers loads the profiles of ids, which line up with follows, keeping
+// their order. Users deleted since are skipped.
+func (fu *followUsecase) followUsers(ctx context.Context, ids []string, follows []*domain.Follow) ([]*domain.FollowUser, error) {
+	users, err := fu.userRepository.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	result := make([]*domain.FollowUser, 0, len(ids))
+	for i, id := range ids {
+		user, ok := byID[id]
+		if !ok {
+			continue
+		}
+		result = append(result, &domain.FollowUser{
+			ID:             user.ID,
+			Username:       user.Username,
+			Bio:            user.Bio,
+			ProfilePicture: user.ProfilePicture,
+			FollowedAt:     follows[i].CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// feedCursor is the decoded form of a feed's next_cursor: where the posts of
+// followed authors left off and how many recommendations were already shown.
+type feedCursor struct {
+	Following     string `json:"f,omitempty"`
+	FollowingDone bool   `json:"fd,omitempty"`
+	Recommended   int    `json:"r,omitempty"`
+}
+
+// GetFeed fills a page with the newest posts of the authors the user follows
+// and mixes in recommendations, one every feedRecommendationEvery items. Once
+// the followed authors run out, the rest of the feed is recommendations.
+func (fu *followUsecase) GetFeed(ctx context.Context, userID string, cursor string, limit int) ([]*domain.FeedItem, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fu.contextTimeout)
+	defer cancel()
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	followingIDs, err := fu.followRepository.GetFollowingIDs(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	// pages are keyed on the followed authors' latest change, so a post they
+	// publish or edit shows up without waiting for the cached pages to expire
+	var latest time.Time
+	if len(followingIDs) > 0 {
+		if latest, err = fu.blogRepository.LatestBlogUpdate(ctx, followingIDs); err != nil {
+			return nil, "", err
+		}
+	}
+	cacheKey := fmt.Sprintf("feed:user:%s:latest:%d:cursor:%s:limit:%d", userID, latest.UnixMilli(), cursor, limit)
+
+	cachedBytes, err := fu.cacheUseCase.Get(ctx, cacheKey)
+	if err == nil && cachedBytes != nil {
+		var cachedData struct {
+			Items      []*domain.FeedItem `json:"items"`
+			NextCursor string             `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(cachedBytes, &cachedData); err == nil {
+			return cachedData.Items, cachedData.NextCursor, nil
+		}
+		log.Printf("Failed to unmarshal cached feed %s: %v", cacheKey, err)
+	} else if err != nil {
+		log.Printf("Error getting feed from cache %s: %v", cacheKey, err)
+	}
+
+	position, err := decodeFeedCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(followingIDs) == 0 {
+		position.FollowingDone = true
+	}
+
+	recommended, err := fu.feedRecommendations(ctx, userID, followingIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	if position.Recommended > len(recommended) {
+		position.Recommended = len(recommended)
+	}
+	recommended = recommended[position.Recommended:]
+
+	var following []*domain.Blog
+	if !position.FollowingDone {
+		followingSlots := limit
+		if len(recommended) > 0 {
+			followingSlots -= limit / feedRecommendationEvery
+		}
+		var next string
+		following, next, err = fu.blogRepository.ListBlogsByAuthors(ctx, followingIDs, position.Following, followingSlots)
+		if err != nil {
+			return nil, "", err
+		}
+		position.Following = next
+		position.FollowingDone = next == ""
+	}
+
+	items := make([]*domain.FeedItem, 0, limit)
+	for len(items) < limit && (len(following) > 0 || len(recommended) > 0) {
+		recommend := len(following) == 0 || (len(recommended) > 0 && (len(items)+1)%feedRecommendationEvery == 0)
+		if recommend {
+			items = append(items, &domain.FeedItem{Blog: recommended[0], Reason: domain.FeedRecommended})
+			recommended = recommended[1:]
+			position.Recommended++
+		} else {
+			items = append(items, &domain.FeedItem{Blog: following[0], Reason: domain.FeedFollowing})
+			following = following[1:]
+		}
+	}
+
+	next := ""
+	if !position.FollowingDone || len(recommended) > 0 {
+		next = encodeFeedCursor(position)
+	}
+
+	dataToCache := struct {
+		Items      []*domain.FeedItem `json:"items"`
+		NextCursor string             `json:"next_cursor"`
+	}{
+		Items:      items,
+		NextCursor: next,
+	}
+	feedJSON, err := json.Marshal(dataToCache)
+	if err == nil {
+		fu.cacheUseCase.Set(ctx, cacheKey, feedJSON, feedCacheTTL)
+	} else {
+		log.Printf("Failed to marshal feed for caching: %v", err)
+	}
+
+	return items, next, nil
+}
+
+// feedRecommendations returns the user's recommendations that the following
+// part of the feed would not show anyway.
+func (fu *followUsecase) feedRecommendations(ctx context.Context, userID string, followingIDs []string) ([]*domain.Blog, error) {
+	blogs, _, err := fu.recommendationUseCase.GetRecommendations(ctx, userID, "", feedRecommendations)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(followingIDs)+1)
+	skip[userID] = true
+	for _, id := range followingIDs {
+		skip[id] = true
+	}
+	result := make([]*domain.Blog, 0, len(blogs))
+	for _, blog := range blogs {
+		if !skip[blog.AuthorID] {
+			result = append(result, blog)
+		}
+	}
+	return result, nil
+}
+
+func encodeFeedCursor(c feedCursor) string {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeFeedCursor(token string) (feedCursor, error) {
+	var c feedCursor
+	if token == "" {
+		return c, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, domain.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || c.Recommended < 0 {
+		return c, domain.ErrInvalidCursor
+	}
+	return c, nil
+}
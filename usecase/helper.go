@@ -1,37 +1,258 @@
 package usecase
 import (
 	"blog-backend/domain"
-	"context"
-	"fmt"
+	"html"
+	"strings"
+	"unicode"
 )
 
-func UpdateBlogMetricsAsync(ctx context.Context,brr domain.IReactionRepository,  br domain.IBlogRepository, reaction *domain.Reaction, blogID string, metric string, value int, results chan error)  {
-	
-	go func() {
-		_, _, err := brr.CheckReactionExists(ctx, blogID, string(reaction.Type))
-		if err != nil{
-			results <- fmt.Errorf("failed to check if reaction exists:%w", err)
+// blogChanges lists the editable fields that differ between two versions of a blog.
+func blogChanges(before, after *domain.Blog) []domain.FieldChange {
+	var changes []domain.FieldChange
+	if before.Title != after.Title {
+		changes = append(changes, domain.FieldChange{Field: "title", Old: before.Title, New: after.Title})
+	}
+	if before.Content != after.Content {
+		changes = append(changes, domain.FieldChange{Field: "content", Old: before.Content, New: after.Content})
+	}
+	if !equalTags(before.Tags, after.Tags) {
+		changes = append(changes, domain.FieldChange{Field: "tags", Old: before.Tags, New: after.Tags})
+	}
+	return changes
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// maxDiffCells caps the LCS table diffLines builds, which grows with the
+// product of the line counts of the changed middle of two texts.
+const maxDiffCells = 1 << 20
+
+// diffLines returns a line based diff of two texts using the longest common
+// subsequence of their lines. Lines shared at the start and end are matched
+// first; when the rest is too large for the LCS table, it is shown as
+// deleted and reinserted instead.
+func diffLines(a, b string) []domain.DiffLine {
+	from := strings.Split(a, "\n")
+	to := strings.Split(b, "\n")
+
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]domain.DiffLine, 0, len(from)+len(to))
+	for _, line := range from[:prefix] {
+		diff = append(diff, domain.DiffLine{Op: domain.DiffEqual, Text: line})
+	}
+	diff = append(diff, diffMiddle(from[prefix:len(from)-suffix], to[prefix:len(to)-suffix])...)
+	for _, line := range from[len(from)-suffix:] {
+		diff = append(diff, domain.DiffLine{Op: domain.DiffEqual, Text: line})
+	}
+	return diff
+}
+
+func diffMiddle(from, to []string) []domain.DiffLine {
+	diff := make([]domain.DiffLine, 0, len(from)+len(to))
+	if (len(from)+1)*(len(to)+1) > maxDiffCells {
+		for _, line := range from {
+			diff = append(diff, domain.DiffLine{Op: domain.DiffDelete, Text: line})
+		}
+		for _, line := range to {
+			diff = append(diff, domain.DiffLine{Op: domain.DiffInsert, Text: line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the LCS length of from[i:] and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			diff = append(diff, domain.DiffLine{Op: domain.DiffEqual, Text: from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, domain.DiffLine{Op: domain.DiffDelete, Text: from[i]})
+			i++
+		default:
+			diff = append(diff, domain.DiffLine{Op: domain.DiffInsert, Text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		diff = append(diff, domain.DiffLine{Op: domain.DiffDelete, Text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		diff = append(diff, domain.DiffLine{Op: domain.DiffInsert, Text: to[j]})
+	}
+	return diff
+}
+
+// buildCommentTree nests replies under their parents. comments must be sorted
+// oldest first so every parent is seen before its replies.
+func buildCommentTree(comments []*domain.Comment) []*domain.Comment {
+	byID := make(map[string]*domain.Comment, len(comments))
+	roots := []*domain.Comment{}
+	for _, comment := range comments {
+		comment.Replies = []*domain.Comment{}
+		byID[comment.ID] = comment
+		if parent, ok := byID[comment.ParentID]; ok && comment.ParentID != "" {
+			parent.Replies = append(parent.Replies, comment)
+			continue
+		}
+		roots = append(roots, comment)
+	}
+	return roots
+}
+
+const snippetLength = 240
+
+// searchTerms splits a text query into lower-cased words, skipping the terms
+// excluded with a leading '-'.
+func searchTerms(query string) []string {
+	var terms []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(word, "-") {
+			continue
 		}
-	}()
-	go func() {
-		err := br.UpdateBlogMetrics(ctx, blogID, metric, value)
-		if err != nil {
-			results <- fmt.Errorf("failed to update blog metrics: %w", err)
-			return
+		word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if word != "" {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// highlightSnippet cuts a window of content around the first matched term and
+// wraps every match in <mark>. The content is HTML escaped so the only markup
+// in the snippet is the highlighting.
+func highlightSnippet(content string, terms []string) string {
+	runes := []rune(content)
+	lower := lowerRunes(runes)
+	lowerTerms := make([][]rune, len(terms))
+	for i, term := range terms {
+		lowerTerms[i] = lowerRunes([]rune(term))
+	}
+
+	first := -1
+	for _, term := range lowerTerms {
+		if i := indexRunes(lower, term, 0); i >= 0 && (first < 0 || i < first) {
+			first = i
 		}
-		results <- nil
-	}()
-	go func() {
-		err := brr.AddReaction(ctx, &domain.Reaction{
-			BlogID: blogID,
-			Type:   domain.ReactionType(metric),
-	})
-		if err != nil {
-			results <- fmt.Errorf("failed to add reaction: %w", err)
-			return
+	}
+
+	start := 0
+	if first > snippetLength/3 {
+		start = first - snippetLength/3
+		for start < first && !unicode.IsSpace(runes[start]) {
+			start++
 		}
-		results <- nil
-	}()
+	}
+	end := start + snippetLength
+	if end > len(runes) {
+		end = len(runes)
+	}
+	for end < len(runes) && end > first && !unicode.IsSpace(runes[end-1]) {
+		end--
+	}
 
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		matched := 0
+		for _, t := range lowerTerms {
+			if i+len(t) <= end && indexRunes(lower[i:i+len(t)], t, 0) == 0 && len(t) > matched {
+				matched = len(t)
+			}
+		}
+		if matched > 0 {
+			b.WriteString("<mark>" + html.EscapeString(string(runes[i:i+matched])) + "</mark>")
+			i += matched
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
 }
 
+// lowerRunes lower-cases rune by rune, so offsets into the result are offsets
+// into runes too; strings.ToLower may change the length.
+func lowerRunes(runes []rune) []rune {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
+
+func indexRunes(s, sub []rune, from int) int {
+	for i := from; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// commentExcerptLength caps the comment text shown with a notification.
+const commentExcerptLength = 80
+
+// commentExcerpt shortens content to commentExcerptLength runes on a word
+// boundary where possible.
+func commentExcerpt(content string) string {
+	runes := []rune(strings.Join(strings.Fields(content), " "))
+	if len(runes) <= commentExcerptLength {
+		return string(runes)
+	}
+	cut := commentExcerptLength
+	for i := cut; i > commentExcerptLength/2; i-- {
+		if runes[i] == ' ' {
+			cut = i
+			break
+		}
+	}
+	return string(runes[:cut]) + "…"
+}
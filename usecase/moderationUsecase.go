@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"blog-backend/domain"
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxReportDetailsLength caps the free text a reporter can add.
+const maxReportDetailsLength = 1000
+
+type moderationUsecase struct {
+	reportRepository  domain.IReportRepository
+	blogRepository    domain.IBlogRepository
+	commentRepository domain.ICommentRepository
+	blogUseCase       domain.IBlogUseCase
+	hideThreshold     int
+	contextTimeout    time.Duration
+}
+
+// NewModerationUsecase returns the moderation usecase. Content is hidden
+// automatically once hideThreshold distinct users reported it; 0 disables
+// automatic hiding.
+func NewModerationUsecase(
+	reportRepository domain.IReportRepository,
+	blogRepository domain.IBlogRepository,
+	commentRepository domain.ICommentRepository,
+	blogUseCase domain.IBlogUseCase,
+	hideThreshold int,
+	timeout time.Duration,
+) domain.IModerationUseCase {
+	return &moderationUsecase{
+		reportRepository:  reportRepository,
+		blogRepository:    blogRepository,
+		commentRepository: commentRepository,
+		blogUseCase:       blogUseCase,
+		hideThreshold:     hideThreshold,
+		contextTimeout:    timeout,
+	}
+}
+
+func (mu *moderationUsecase) ReportBlog(ctx context.Context, reporterID, blogID string, reason domain.ReportReason, details string) (*domain.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	if !reason.IsValid() {
+		return nil, domain.ErrInvalidReportReason
+	}
+	blog, err := mu.visibleBlog(ctx, blogID)
+	if err != nil {
+		return nil, err
+	}
+	if blog.AuthorID == reporterID {
+		return nil, domain.ErrCannotReportOwnContent
+	}
+
+	report, err := mu.reportRepository.CreateReport(ctx, newReport(reporterID, domain.ReportBlog, blogID, blogID, reason, details))
+	if err != nil {
+		return nil, err
+	}
+	if !blog.Hidden {
+		mu.hideIfOverThreshold(ctx, domain.ReportBlog, blogID)
+	}
+	return report, nil
+}
+
+func (mu *moderationUsecase) ReportComment(ctx context.Context, reporterID, blogID, commentID string, reason domain.ReportReason, details string) (*domain.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	if !reason.IsValid() {
+		return nil, domain.ErrInvalidReportReason
+	}
+	if _, err := bson.ObjectIDFromHex(commentID); err != nil {
+		return nil, domain.ErrCommentNotFound
+	}
+	comment, err := mu.commentRepository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.BlogID != blogID {
+		return nil, domain.ErrCommentNotFound
+	}
+	if _, err := mu.visibleBlog(ctx, blogID); err != nil {
+		return nil, err
+	}
+	if comment.AuthorID == reporterID {
+		return nil, domain.ErrCannotReportOwnContent
+	}
+
+	report, err := mu.reportRepository.CreateReport(ctx, newReport(reporterID, domain.ReportComment, commentID, blogID, reason, details))
+	if err != nil {
+		return nil, err
+	}
+	if !comment.Hidden {
+		mu.hideIfOverThreshold(ctx, domain.ReportComment, commentID)
+	}
+	return report, nil
+}
+
+// visibleBlog loads a blog readers can see; anything else cannot be reported.
+func (mu *moderationUsecase) visibleBlog(ctx context.Context, blogID string) (*domain.Blog, error) {
+	if _, err := bson.ObjectIDFromHex(blogID); err != nil {
+		return nil, domain.ErrBlogNotFound
+	}
+	blog, err := mu.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, err
+	}
+	if !blog.IsVisible() {
+		return nil, domain.ErrBlogNotFound
+	}
+	return blog, nil
+}
+
+func newReport(reporterID string, targetType domain.ReportTargetType, targetID, blogID string, reason domain.ReportReason, details string) *domain.Report {
+	details = strings.TrimSpace(details)
+	if runes := []rune(details); len(runes) > maxReportDetailsLength {
+		details = string(runes[:maxReportDetailsLength])
+	}
+	return &domain.Report{
+		TargetType: targetType,
+		TargetID:   targetID,
+		BlogID:     blogID,
+		ReporterID: reporterID,
+		Reason:     reason,
+		Details:    details,
+		Status:     domain.ReportOpen,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// hideIfOverThreshold hides the target until a moderator looks at it once
+// enough distinct users reported it. The report itself is already stored, so
+// failures are only logged.
+func (mu *moderationUsecase) hideIfOverThreshold(ctx context.Context, targetType domain.ReportTargetType, targetID string) {
+	if mu.hideThreshold <= 0 {
+		return
+	}
+	count, err := mu.reportRepository.CountOpenReports(ctx, targetType, targetID)
+	if err != nil {
+		log.Printf("Failed to count reports on %s %s: %v", targetType, targetID, err)
+		return
+	}
+	if count < int64(mu.hideThreshold) {
+		return
+	}
+	if err := mu.setHidden(ctx, targetType, targetID, true); err != nil {
+		log.Printf("Failed to hide reported %s %s: %v", targetType, targetID, err)
+		return
+	}
+	log.Printf("Hid %s %s after %d reports", targetType, targetID, count)
+}
+
+func (mu *moderationUsecase) setHidden(ctx context.Context, targetType domain.ReportTargetType, targetID string, hidden bool) error {
+	if targetType == domain.ReportComment {
+		return mu.blogUseCase.SetCommentHidden(ctx, targetID, hidden)
+	}
+	return mu.blogUseCase.SetBlogHidden(ctx, targetID, hidden)
+}
+
+func (mu *moderationUsecase) ListReports(ctx context.Context, filter domain.ReportFilter, cursor string, limit int) ([]*domain.Report, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, "", domain.ErrInvalidReportStatus
+	}
+	switch filter.TargetType {
+	case "", domain.ReportBlog, domain.ReportComment:
+	default:
+		return nil, "", domain.ErrInvalidReportTarget
+	}
+	return mu.reportRepository.ListReports(ctx, filter, cursor, limit)
+}
+
+func (mu *moderationUsecase) GetReport(ctx context.Context, reportID string) (*domain.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	return mu.reportRepository.GetReportByID(ctx, reportID)
+}
+
+func (mu *moderationUsecase) UnhideBlog(ctx context.Context, blogID string) error {
+	ctx, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	return mu.setHidden(ctx, domain.ReportBlog, blogID, false)
+}
+
+func (mu *moderationUsecase) UnhideComment(ctx context.Context, blogID, commentID string) error {
+	ctx, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	if _, err := bson.ObjectIDFromHex(commentID); err != nil {
+		return domain.ErrCommentNotFound
+	}
+	comment, err := mu.commentRepository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.BlogID != blogID {
+		return domain.ErrCommentNotFound
+	}
+	return mu.setHidden(ctx, domain.ReportComment, commentID, false)
+}
+
+func (mu *moderationUsecase) ResolveReport(ctx context.Context, reportID, moderatorID string, action domain.ModerationAction, note string) (*domain.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	report, err := mu.reportRepository.GetReportByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	// resolving a report closes every open report on its target
+	if report.Status != domain.ReportOpen {
+		return nil, domain.ErrReportResolved
+	}
+
+	status := domain.ReportActioned
+	switch action {
+	case domain.ActionDismiss:
+		status = domain.ReportDismissed
+	case domain.ActionHide:
+		err = mu.setHidden(ctx, report.TargetType, report.TargetID, true)
+	case domain.ActionRestore:
+		status = domain.ReportDismissed
+		err = mu.setHidden(ctx, report.TargetType, report.TargetID, false)
+	case domain.ActionDelete:
+		if report.TargetType == domain.ReportComment {
+			err = mu.blogUseCase.RemoveComment(ctx, report.TargetID, moderatorID)
+		} else {
+			err = mu.blogUseCase.DeleteBlog(ctx, report.TargetID, moderatorID)
+		}
+		// someone else already removed it; the reports still need closing
+		if err == domain.ErrBlogNotFound || err == domain.ErrCommentNotFound {
+			err = nil
+		}
+	default:
+		return nil, domain.ErrInvalidModerationAction
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = mu.reportRepository.ResolveReports(ctx, report.TargetType, report.TargetID, &domain.Report{
+		Status:     status,
+		Action:     action,
+		ResolvedBy: moderatorID,
+		ResolvedAt: time.Now(),
+		Note:       strings.TrimSpace(note),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mu.reportRepository.GetReportByID(ctx, reportID)
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"blog-backend/domain"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+type notificationUsecase struct {
+	notificationRepository domain.INotificationRepository
+	userRepository         domain.IUserRepository
+	eventBroker            domain.IEventBroker
+	contextTimeout         time.Duration
+}
+
+func NewNotificationUsecase(
+	notificationRepository domain.INotificationRepository,
+	userRepository domain.IUserRepository,
+	eventBroker domain.IEventBroker,
+	timeout time.Duration,
+) domain.INotificationUseCase {
+	return &notificationUsecase{
+		notificationRepository: notificationRepository,
+		userRepository:         userRepository,
+		eventBroker:            eventBroker,
+		contextTimeout:         timeout,
+	}
+}
+
+func (nu *notificationUsecase) Notify(ctx context.Context, event *domain.NotificationEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	if event.RecipientID == "" || event.RecipientID == event.ActorID {
+		return nil
+	}
+	prefs, err := nu.notificationRepository.GetPreferences(ctx, event.RecipientID)
+	if err != nil {
+		return err
+	}
+	for _, muted := range prefs.Muted {
+		if muted == event.Type {
+			return nil
+		}
+	}
+
+	notification, err := nu.notificationRepository.AddEvent(ctx, event, time.Now())
+	if err != nil {
+		return err
+	}
+
+	nu.summarize(ctx, []*domain.Notification{notification})
+	publishInBackground(nu.eventBroker, domain.UserEventTopic(event.RecipientID), &domain.LiveEvent{
+		Type: domain.EventNotification,
+		Data: notification,
+	})
+	return nil
+}
+
+func (nu *notificationUsecase) GetNotifications(ctx context.Context, userID string, unreadOnly bool, cursor string, limit int) ([]*domain.Notification, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	notifications, next, err := nu.notificationRepository.GetNotifications(ctx, userID, unreadOnly, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	nu.summarize(ctx, notifications)
+	return notifications, next, nil
+}
+
+// summarize fills in the human readable line of each notification, naming
+// the latest actor: "alice and 11 others liked your post".
+func (nu *notificationUsecase) summarize(ctx context.Context, notifications []*domain.Notification) {
+	var actorIDs []string
+	for _, n := range notifications {
+		if len(n.RecentActors) > 0 {
+			actorIDs = append(actorIDs, n.RecentActors[len(n.RecentActors)-1])
+		}
+	}
+	names := map[string]string{}
+	if users, err := nu.userRepository.GetUsersByIDs(ctx, actorIDs); err == nil {
+		for _, user := range users {
+			names[user.ID] = user.Username
+		}
+	} else {
+		log.Printf("Failed to load notification actors: %v", err)
+	}
+
+	for _, n := range notifications {
+		actor := "Someone"
+		if len(n.RecentActors) > 0 {
+			if name, ok := names[n.RecentActors[len(n.RecentActors)-1]]; ok {
+				actor = name
+			}
+		}
+		switch others := n.ActorCount - 1; {
+		case others == 1:
+			actor += " and 1 other"
+		case others > 1:
+			actor += fmt.Sprintf(" and %d others", others)
+		}
+		n.Summary = actor + " " + notificationVerb(n.Type)
+	}
+}
+
+func notificationVerb(t domain.NotificationType) string {
+	switch t {
+	case domain.NotifyLike:
+		return "liked your post"
+	case domain.NotifyDislike:
+		return "disliked your post"
+	case domain.NotifyReaction:
+		return "reacted to your post"
+	case domain.NotifyComment:
+		return "commented on your post"
+	case domain.NotifyReply:
+		return "replied to your comment"
+	case domain.NotifyFollow:
+		return "started following you"
+	}
+	return "interacted with you"
+}
+
+func (nu *notificationUsecase) CountUnread(ctx context.Context, userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	return nu.notificationRepository.CountUnread(ctx, userID)
+}
+
+func (nu *notificationUsecase) MarkRead(ctx context.Context, userID, notificationID string) error {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	return nu.notificationRepository.MarkRead(ctx, userID, notificationID)
+}
+
+func (nu *notificationUsecase) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	return nu.notificationRepository.MarkAllRead(ctx, userID)
+}
+
+func (nu *notificationUsecase) GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	return nu.notificationRepository.GetPreferences(ctx, userID)
+}
+
+func (nu *notificationUsecase) UpdatePreferences(ctx context.Context, userID string, muted []domain.NotificationType) (*domain.NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	seen := map[domain.NotificationType]bool{}
+	unique := make([]domain.NotificationType, 0, len(muted))
+	for _, t := range muted {
+		if !t.IsValid() {
+			return nil, domain.ErrInvalidNotificationType
+		}
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+
+	prefs := &domain.NotificationPreferences{
+		UserID:    userID,
+		Muted:     unique,
+		UpdatedAt: time.Now(),
+	}
+	if err := nu.notificationRepository.SavePreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// notifyInBackground hands an event to the notification centre without
+// holding up the action that caused it; a lost notification is only logged.
+func notifyInBackground(notifier domain.INotificationUseCase, event *domain.NotificationEvent) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := notifier.Notify(ctx, event); err != nil {
+			log.Printf("Failed to send %s notification to user %s: %v", event.Type, event.RecipientID, err)
+		}
+	}()
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"blog-backend/domain"
+	"context"
+	"time"
+)
+
+// purgeBatchSize bounds how many records one pass removes, so a large backlog
+// is worked off in steps that each fit in the context timeout.
+const purgeBatchSize = 100
+
+type purgeUsecase struct {
+	blogRepository    domain.IBlogRepository
+	commentRepository domain.ICommentRepository
+	userRepository    domain.IUserRepository
+	cascadeService    domain.ICascadeService
+	retention         time.Duration
+	contextTimeout    time.Duration
+}
+
+// NewPurgeUsecase returns the usecase that removes soft deleted records for
+// good once they have been deleted for longer than retention.
+func NewPurgeUsecase(
+	blogRepository domain.IBlogRepository,
+	commentRepository domain.ICommentRepository,
+	userRepository domain.IUserRepository,
+	cascadeService domain.ICascadeService,
+	retention time.Duration,
+	timeout time.Duration,
+) domain.IPurgeUseCase {
+	return &purgeUsecase{
+		blogRepository:    blogRepository,
+		commentRepository: commentRepository,
+		userRepository:    userRepository,
+		cascadeService:    cascadeService,
+		retention:         retention,
+		contextTimeout:    timeout,
+	}
+}
+
+func (pu *purgeUsecase) PurgeExpired(ctx context.Context) (*domain.PurgeReport, error) {
+	report := &domain.PurgeReport{}
+	cutoff := time.Now().Add(-pu.retention)
+
+	for {
+		listed, purged, err := pu.purgeBlogBatch(ctx, cutoff)
+		report.Blogs += purged
+		if err != nil {
+			return report, err
+		}
+		if listed < purgeBatchSize {
+			break
+		}
+	}
+
+	for {
+		listed, purged, err := pu.purgeCommentBatch(ctx, cutoff)
+		report.Comments += purged
+		if err != nil {
+			return report, err
+		}
+		if listed < purgeBatchSize {
+			break
+		}
+	}
+
+	for {
+		listed, purged, err := pu.purgeUserBatch(ctx, cutoff)
+		report.Users += purged
+		if err != nil {
+			return report, err
+		}
+		if listed < purgeBatchSize {
+			break
+		}
+	}
+	return report, nil
+}
+
+// purgeBlogBatch removes one batch of expired blogs with their dependents.
+func (pu *purgeUsecase) purgeBlogBatch(ctx context.Context, cutoff time.Time) (int, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	ids, err := pu.blogRepository.ListDeletedBlogIDs(ctx, cutoff, purgeBatchSize)
+	if err != nil || len(ids) == 0 {
+		return 0, 0, err
+	}
+
+	purged, err := pu.cascadeService.PurgeBlogs(ctx, ids)
+	return len(ids), purged, err
+}
+
+// purgeCommentBatch removes one batch of expired comments with their
+// reactions.
+func (pu *purgeUsecase) purgeCommentBatch(ctx context.Context, cutoff time.Time) (int, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	defer cancel()
+
+	ids, err := pu.commentRepository.ListDeletedCommentIDs(ctx, cutoff, purgeBatchSize)
+	if err != nil || len(ids) == 0 {
+		return 0, 0, err
+	}
+
+	purged, err := pu.cascadeService.PurgeComments(ctx, ids)
+	return len(ids), purged, err
+}
+
+// purgeUserBatch removes one batch of expired accounts. Each account is
+// purged on its own so one failure does not hold back the rest.
+func (pu *purgeUsecase) purgeUserBatch(ctx context.Context, cutoff time.Time) (int, int64, error) {
+	listCtx, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+	users, err := pu.userRepository.ListDeletedUsers(listCtx, cutoff, purgeBatchSize)
+	cancel()
+	if err != nil || len(users) == 0 {
+		return 0, 0, err
+	}
+
+	var purged int64
+	for _, user := range users {
+		userCtx, cancel := context.WithTimeout(ctx, pu.contextTimeout)
+		err := pu.cascadeService.PurgeUser(userCtx, user)
+		cancel()
+		if err != nil {
+			if err == domain.ErrUserNotFound {
+				// restored since it was listed
+				continue
+			}
+			return len(users), purged, err
+		}
+		purged++
+	}
+	return len(users), purged, nil
+}
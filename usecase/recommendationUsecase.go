@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"blog-backend/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	recommendationCacheTTL = 15 * time.Minute // TTL for a user's ranked recommendations
+	maxRecommendations     = 200
+
+	// a read's weight on the user's tag interests halves every two weeks
+	tagInterestHalfLife = 14 * 24 * time.Hour
+	interestReads       = 200 // reads that shape the tag interests
+	excludedReads       = 500 // reads never recommended again
+	interestTags        = 5
+	tagCandidates       = 200
+
+	likedSeeds     = 50  // the user's latest likes used for "liked X also liked Y"
+	similarReaders = 200 // readers sharing those likes that are looked at
+	coCandidates   = 100
+
+	tagScoreWeight           = 0.6
+	collaborativeScoreWeight = 0.4
+)
+
+type recommendationUsecase struct {
+	historyRepository  domain.IHistoryRepository
+	blogRepository     domain.IBlogRepository
+	reactionRepository domain.IReactionRepository
+	trendingIndex      domain.ITrendingIndex
+	cacheUseCase       domain.ICacheUseCase
+	contextTimeout     time.Duration
+}
+
+func NewRecommendationUsecase(
+	historyRepository domain.IHistoryRepository,
+	blogRepository domain.IBlogRepository,
+	reactionRepository domain.IReactionRepository,
+	trendingIndex domain.ITrendingIndex,
+	timeout time.Duration,
+	cacheUseCase domain.ICacheUseCase,
+) domain.IRecommendationUseCase {
+	return &recommendationUsecase{
+		historyRepository:  historyRepository,
+		blogRepository:     blogRepository,
+		reactionRepository: reactionRepository,
+		trendingIndex:      trendingIndex,
+		cacheUseCase:       cacheUseCase,
+		contextTimeout:     timeout,
+	}
+}
+
+// GetRecommendations ranks the candidates once and caches the ranking, so
+// the pages of one ranking stay consistent. The cursor is the position in it.
+func (ru *recommendationUsecase) GetRecommendations(ctx context.Context, userID string, cursor string, limit int) ([]*domain.Blog, string, error) {
+	offset := 0
+	if cursor != "" {
+		var err error
+		if offset, err = strconv.Atoi(cursor); err != nil || offset < 0 {
+			return nil, "", domain.ErrInvalidCursor
+		}
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	ids, err := ru.rankedIDs(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if offset >= len(ids) {
+		return []*domain.Blog{}, "", nil
+	}
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+	pageIDs := ids[offset:end]
+
+	blogs, err := ru.blogRepository.GetBlogsByIDs(ctx, pageIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	byID := make(map[string]*domain.Blog, len(blogs))
+	for _, blog := range blogs {
+		byID[blog.ID] = blog
+	}
+	// blogs deleted or hidden since the ranking are left out
+	result := make([]*domain.Blog, 0, len(pageIDs))
+	for _, id := range pageIDs {
+		if blog, ok := byID[id]; ok && blog.IsVisible() {
+			result = append(result, blog)
+		}
+	}
+
+	next := ""
+	if end < len(ids) {
+		next = strconv.Itoa(end)
+	}
+	return result, next, nil
+}
+
+func (ru *recommendationUsecase) rankedIDs(ctx context.Context, userID string) ([]string, error) {
+	cacheKey := fmt.Sprintf("recommendations:user:%s", userID)
+	cachedBytes, err := ru.cacheUseCase.Get(ctx, cacheKey)
+	if err == nil && cachedBytes != nil {
+		var ids []string
+		if err := json.Unmarshal(cachedBytes, &ids); err == nil {
+			return ids, nil
+		}
+		log.Printf("Failed to unmarshal cached recommendations %s: %v", cacheKey, err)
+	} else if err != nil {
+		log.Printf("Error getting recommendations from cache %s: %v", cacheKey, err)
+	}
+
+	ids, err := ru.rank(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(ids); err == nil {
+		ru.cacheUseCase.Set(ctx, cacheKey, data, recommendationCacheTTL)
+	}
+	return ids, nil
+}
+
+// rank scores blogs on the user's tags, weighted by how recently they read
+// them, and on what readers who liked the same blogs also liked. Trending
+// blogs fill up the rest, which is all a new user gets.
+func (ru *recommendationUsecase) rank(ctx context.Context, userID string) ([]string, error) {
+	reads, err := ru.historyRepository.GetRecentReads(ctx, userID, excludedReads)
+	if err != nil {
+		return nil, err
+	}
+	liked, err := ru.reactionRepository.ListLikedBlogIDs(ctx, userID, likedSeeds)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(reads)+len(liked))
+	for _, read := range reads {
+		seen[read.BlogID] = true
+	}
+	addAll(seen, liked)
+
+	interests, err := ru.tagInterests(ctx, reads)
+	if err != nil {
+		return nil, err
+	}
+	candidates := map[string]*domain.Blog{}
+	if len(interests) > 0 {
+		tags := make([]string, 0, len(interests))
+		for tag := range interests {
+			tags = append(tags, tag)
+		}
+		blogs, err := ru.blogRepository.ListRecentBlogsByTags(ctx, tags, tagCandidates)
+		if err != nil {
+			return nil, err
+		}
+		for _, blog := range blogs {
+			candidates[blog.ID] = blog
+		}
+	}
+
+	coCounts, err := ru.reactionRepository.CountCoReactions(ctx, liked, userID, similarReaders, coCandidates)
+	if err != nil {
+		return nil, err
+	}
+	maxCoCount := 0
+	missing := make([]string, 0, len(coCounts))
+	for id, count := range coCounts {
+		if count > maxCoCount {
+			maxCoCount = count
+		}
+		if _, ok := candidates[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if err := ru.addCandidates(ctx, candidates, missing); err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		blog  *domain.Blog
+		score float64
+	}
+	ranked := make([]scored, 0, len(candidates))
+	for id, blog := range candidates {
+		if seen[id] || blog.AuthorID == userID || !blog.IsVisible() {
+			continue
+		}
+		tagScore := 0.0
+		for _, tag := range blog.Tags {
+			tagScore += interests[tag]
+		}
+		score := tagScoreWeight * math.Min(tagScore, 1)
+		if maxCoCount > 0 {
+			score += collaborativeScoreWeight * float64(coCounts[id]) / float64(maxCoCount)
+		}
+		if score > 0 {
+			ranked = append(ranked, scored{blog: blog, score: score})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].blog.ViewCount > ranked[j].blog.ViewCount
+	})
+
+	ids := make([]string, 0, maxRecommendations)
+	for _, entry := range ranked {
+		if len(ids) == maxRecommendations {
+			break
+		}
+		ids = append(ids, entry.blog.ID)
+		seen[entry.blog.ID] = true
+	}
+	if len(ids) < maxRecommendations {
+		trending, err := ru.trendingFill(ctx, userID, seen, maxRecommendations-len(ids))
+		if err != nil {
+			// recommendations without the trending tail still help
+			log.Printf("Failed to add trending blogs to recommendations: %v", err)
+		}
+		ids = append(ids, trending...)
+	}
+	return ids, nil
+}
+
+// tagInterests weighs the tags of the user's recent reads by how long ago
+// they were read and returns the strongest ones, scaled so the top is 1.
+func (ru *recommendationUsecase) tagInterests(ctx context.Context, reads []*domain.BlogRead) (map[string]float64, error) {
+	if len(reads) > interestReads {
+		reads = reads[:interestReads]
+	}
+	ids := make([]string, 0, len(reads))
+	unique := map[string]bool{}
+	for _, read := range reads {
+		if !unique[read.BlogID] {
+			unique[read.BlogID] = true
+			ids = append(ids, read.BlogID)
+		}
+	}
+	blogs, err := ru.blogRepository.GetBlogsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	tagsByBlog := make(map[string][]string, len(blogs))
+	for _, blog := range blogs {
+		tagsByBlog[blog.ID] = blog.Tags
+	}
+
+	now := time.Now()
+	weights := map[string]float64{}
+	for _, read := range reads {
+		decay := math.Pow(0.5, now.Sub(read.ReadAt).Hours()/tagInterestHalfLife.Hours())
+		for _, tag := range tagsByBlog[read.BlogID] {
+			weights[tag] += decay
+		}
+	}
+
+	tags := make([]string, 0, len(weights))
+	for tag := range weights {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if weights[tags[i]] != weights[tags[j]] {
+			return weights[tags[i]] > weights[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	if len(tags) > interestTags {
+		tags = tags[:interestTags]
+	}
+	interests := make(map[string]float64, len(tags))
+	for _, tag := range tags {
+		interests[tag] = weights[tag] / weights[tags[0]]
+	}
+	return interests, nil
+}
+
+func (ru *recommendationUsecase) addCandidates(ctx context.Context, candidates map[string]*domain.Blog, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	blogs, err := ru.blogRepository.GetBlogsByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, blog := range blogs {
+		candidates[blog.ID] = blog
+	}
+	return nil
+}
+
+// trendingFill returns up to count of this week's trending blogs the user
+// has not seen and did not write.
+func (ru *recommendationUsecase) trendingFill(ctx context.Context, userID string, seen map[string]bool, count int) ([]string, error) {
+	trendingIDs, err := ru.trendingIndex.Top(ctx, domain.TrendingWeek, 0, count+len(seen))
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]string, 0, len(trendingIDs))
+	for _, id := range trendingIDs {
+		if !seen[id] {
+			candidates = append(candidates, id)
+		}
+	}
+	blogs, err := ru.blogRepository.GetBlogsByIDs(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.Blog, len(blogs))
+	for _, blog := range blogs {
+		byID[blog.ID] = blog
+	}
+
+	ids := make([]string, 0, count)
+	for _, id := range candidates {
+		blog, ok := byID[id]
+		if !ok || blog.AuthorID == userID || !blog.IsVisible() {
+			continue
+		}
+		ids = append(ids, id)
+		if len(ids) == count {
+			break
+		}
+	}
+	return ids, nil
+}
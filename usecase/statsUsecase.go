@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"blog-backend/domain"
+	"context"
+	"sort"
+	"time"
+)
+
+// defaultStatsDays is the range shown when the request leaves it open.
+const defaultStatsDays = 30
+
+type statsUsecase struct {
+	statsRepository domain.IStatsRepository
+	blogRepository  domain.IBlogRepository
+	contextTimeout  time.Duration
+}
+
+func NewStatsUsecase(statsRepository domain.IStatsRepository, blogRepository domain.IBlogRepository, timeout time.Duration) domain.IStatsUseCase {
+	return &statsUsecase{
+		statsRepository: statsRepository,
+		blogRepository:  blogRepository,
+		contextTimeout:  timeout,
+	}
+}
+
+func (su *statsUsecase) GetBlogStats(ctx context.Context, blogID, userID string, role domain.Role, query domain.StatsQuery) (*domain.BlogStats, error) {
+	query, err := normalizeStatsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	blog, err := su.blogRepository.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, err
+	}
+	if blog.AuthorID != userID && role != domain.Admin {
+		return nil, domain.ErrUserNotAuthorized
+	}
+
+	days, err := su.statsRepository.GetDailyStats(ctx, []string{blogID}, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	series, totals := statsSeries(days, query)
+	return &domain.BlogStats{
+		BlogID:   blog.ID,
+		Title:    blog.Title,
+		From:     query.From,
+		To:       query.To,
+		Interval: query.Interval,
+		Totals:   totals,
+		Series:   series,
+	}, nil
+}
+
+func (su *statsUsecase) GetDashboard(ctx context.Context, authorID string, query domain.StatsQuery) (*domain.StatsDashboard, error) {
+	query, err := normalizeStatsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	ids, err := su.blogRepository.ListBlogIDsByAuthor(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+	// deleted posts are left out
+	blogs, err := su.blogRepository.GetBlogsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	posts := make(map[string]*domain.PostStats, len(blogs))
+	liveIDs := make([]string, len(blogs))
+	for i, blog := range blogs {
+		liveIDs[i] = blog.ID
+		posts[blog.ID] = &domain.PostStats{BlogID: blog.ID, Title: blog.Title}
+	}
+
+	days, err := su.statsRepository.GetDailyStats(ctx, liveIDs, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		if post, ok := posts[day.BlogID]; ok {
+			post.Add(day.StatsTotals)
+		}
+	}
+	series, totals := statsSeries(days, query)
+
+	dashboard := &domain.StatsDashboard{
+		From:     query.From,
+		To:       query.To,
+		Interval: query.Interval,
+		Totals:   totals,
+		Series:   series,
+		Posts:    make([]*domain.PostStats, 0, len(posts)),
+	}
+	for _, post := range posts {
+		dashboard.Posts = append(dashboard.Posts, post)
+	}
+	sort.Slice(dashboard.Posts, func(i, j int) bool {
+		a, b := dashboard.Posts[i], dashboard.Posts[j]
+		if a.Views != b.Views {
+			return a.Views > b.Views
+		}
+		return a.BlogID > b.BlogID
+	})
+	return dashboard, nil
+}
+
+// normalizeStatsQuery fills in the defaults, the last 30 days by day, and
+// truncates the range to whole UTC days.
+func normalizeStatsQuery(query domain.StatsQuery) (domain.StatsQuery, error) {
+	if query.Interval == "" {
+		query.Interval = domain.StatsDaily
+	}
+	if !query.Interval.IsValid() {
+		return query, domain.ErrInvalidStatsInterval
+	}
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	query.To = utcDay(query.To)
+	if query.From.IsZero() {
+		query.From = query.To.AddDate(0, 0, -(defaultStatsDays - 1))
+	}
+	query.From = utcDay(query.From)
+	if query.From.After(query.To) || query.To.Sub(query.From) >= domain.MaxStatsRange*24*time.Hour {
+		return query, domain.ErrInvalidStatsRange
+	}
+	return query, nil
+}
+
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// bucketStart is the start of the interval the day falls in.
+func bucketStart(day time.Time, interval domain.StatsInterval) time.Time {
+	switch interval {
+	case domain.StatsWeekly:
+		offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+		return day.AddDate(0, 0, -offset)
+	case domain.StatsMonthly:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// statsSeries groups the daily buckets by interval, with an empty bucket for
+// every interval without activity, and sums them up.
+func statsSeries(days []*domain.DailyStats, query domain.StatsQuery) ([]*domain.StatsBucket, domain.StatsTotals) {
+	var series []*domain.StatsBucket
+	byStart := map[time.Time]*domain.StatsBucket{}
+	for day := query.From; !day.After(query.To); day = day.AddDate(0, 0, 1) {
+		start := bucketStart(day, query.Interval)
+		if _, ok := byStart[start]; !ok {
+			bucket := &domain.StatsBucket{Start: start}
+			byStart[start] = bucket
+			series = append(series, bucket)
+		}
+	}
+
+	var totals domain.StatsTotals
+	for _, day := range days {
+		if bucket, ok := byStart[bucketStart(utcDay(day.Day), query.Interval)]; ok {
+			bucket.Add(day.StatsTotals)
+			totals.Add(day.StatsTotals)
+		}
+	}
+	return series, totals
+}
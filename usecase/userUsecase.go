@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 const (
@@ -14,8 +16,18 @@ const (
 	userListCacheTTL    = 5 * time.Minute // TTL for lists of users
 )
 
+// protectedUserFields can only be changed through their own endpoints, never
+// by a profile update.
+var protectedUserFields = []string{
+	"_id", "role", "status", "status_reason", "status_until", "password_hash",
+	"google_id", "created_at", "deleted_at", "follower_count", "following_count",
+}
+
 type userUsecase struct {
 	userRepository   domain.IUserRepository
+	refreshTokenRepository domain.IRefreshTokenRepository
+	tokenDenylist    domain.ITokenDenylist
+	cascadeService   domain.ICascadeService
 	contextTimeout   time.Duration
 	passwordServices domain.IPasswordService
 	cacheUseCase     domain.ICacheUseCase 
@@ -23,12 +35,18 @@ type userUsecase struct {
 
 func NewUserUsecase(
 	userRepository domain.IUserRepository,
+	refreshTokenRepository domain.IRefreshTokenRepository,
+	tokenDenylist domain.ITokenDenylist,
+	cascadeService domain.ICascadeService,
 	timeout time.Duration,
 	passwordServices domain.IPasswordService,
 	cacheUseCase domain.ICacheUseCase, 
 ) domain.IUserUseCase {
 	return &userUsecase{
 		userRepository:   userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		tokenDenylist:    tokenDenylist,
+		cascadeService:   cascadeService,
 		contextTimeout:   timeout,
 		passwordServices: passwordServices,
 		cacheUseCase:     cacheUseCase, 
@@ -70,8 +88,12 @@ func (uu *userUsecase) GetProfile(ctx context.Context, userID string) (*domain.U
 func (uu *userUsecase) UpdateProfile(ctx context.Context, userID string, updates map[string]interface{}) error {
 	ctx, cancel := context.WithTimeout(ctx, uu.contextTimeout)
 	defer cancel()
+	for _, field := range protectedUserFields {
+		delete(updates, field)
+	}
 	updates["updated_at"] = time.Now()
 
+	passwordChanged := false
 	if newPass, ok := updates["password"].(string); ok {
 		hashedPass, err := uu.passwordServices.HashPassword(newPass)
 		if err != nil {
@@ -79,12 +101,18 @@ func (uu *userUsecase) UpdateProfile(ctx context.Context, userID string, updates
 		}
 		updates["password_hash"] = hashedPass
 		delete(updates, "password")
+		passwordChanged = true
 	}
 
 	err := uu.userRepository.UpdateUser(ctx, userID, updates)
 	if err != nil {
 		return err
 	}
+	if passwordChanged {
+		if err := revokeUserTokens(ctx, uu.refreshTokenRepository, uu.tokenDenylist, userID); err != nil {
+			log.Printf("Failed to revoke tokens of user %s after password change: %v", userID, err)
+		}
+	}
 
 	go uu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("user:id:%s", userID))
 	go uu.cacheUseCase.InvalidatePrefix(context.Background(), "users:list:")
@@ -114,6 +142,10 @@ func (uu *userUsecase) DemoteToUser(ctx context.Context, targetUserID string) er
 	if err != nil {
 		return err
 	}
+	// tokens still carry the admin role; the user refreshes into a new one
+	if err := uu.tokenDenylist.SetTokensValidAfter(ctx, targetUserID, time.Now()); err != nil {
+		log.Printf("Failed to revoke access tokens of demoted user %s: %v", targetUserID, err)
+	}
 
 	go uu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("user:id:%s", targetUserID))
 	go uu.cacheUseCase.InvalidatePrefix(context.Background(), "users:list:") 
@@ -121,37 +153,37 @@ func (uu *userUsecase) DemoteToUser(ctx context.Context, targetUserID string) er
 	return nil
 }
 
-func (uu *userUsecase) GetUsers(ctx context.Context, page, limit int) ([]*domain.User, int64, error) {
+func (uu *userUsecase) GetUsers(ctx context.Context, cursor string, limit int) ([]*domain.User, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, uu.contextTimeout)
 	defer cancel()
 
-	cacheKey := fmt.Sprintf("users:list:page:%d:limit:%d", page, limit)
+	cacheKey := fmt.Sprintf("users:list:cursor:%s:limit:%d", cursor, limit)
 
 	cachedUsersBytes, err := uu.cacheUseCase.Get(ctx, cacheKey)
 	if err == nil && cachedUsersBytes != nil {
 		var cachedData struct {
-			Users []*domain.User `json:"users"`
-			Total int64          `json:"total"`
+			Users      []*domain.User `json:"users"`
+			NextCursor string         `json:"next_cursor"`
 		}
 		if err := json.Unmarshal(cachedUsersBytes, &cachedData); err == nil {
-			return cachedData.Users, cachedData.Total, nil
+			return cachedData.Users, cachedData.NextCursor, nil
 		}
 		log.Printf("Failed to unmarshal cached user list %s: %v", cacheKey, err)
 	} else if err != nil {
 		log.Printf("Error getting user list from cache %s: %v", cacheKey, err)
 	}
 
-	users, total, err := uu.userRepository.GetUsers(ctx, page, limit)
+	users, next, err := uu.userRepository.GetUsers(ctx, cursor, limit)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", err
 	}
 
 	dataToCache := struct {
-		Users []*domain.User `json:"users"`
-		Total int64          `json:"total"`
+		Users      []*domain.User `json:"users"`
+		NextCursor string         `json:"next_cursor"`
 	}{
-		Users: users,
-		Total: total,
+		Users:      users,
+		NextCursor: next,
 	}
 	userListJSON, err := json.Marshal(dataToCache)
 	if err == nil {
@@ -160,20 +192,124 @@ func (uu *userUsecase) GetUsers(ctx context.Context, page, limit int) ([]*domain
 		log.Printf("Failed to marshal user list for caching: %v", err)
 	}
 
-	return users, total, nil
+	return users, next, nil
+}
+
+// DeleteUser soft deletes the account and signs it out everywhere. The
+// purge job removes it for good once the retention period is over, deleting
+// or transferring its content as action says.
+func (uu *userUsecase) DeleteUser(ctx context.Context, id string, deletedBy string, action domain.UserContentAction) error {
+	ctx, cancel := context.WithTimeout(ctx, uu.contextTimeout)
+	defer cancel()
+
+	if !action.IsValid() {
+		return domain.ErrInvalidContentAction
+	}
+	user, err := uu.userRepository.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.Username == domain.GhostUsername {
+		return domain.ErrGhostAccount
+	}
+
+	// Mongo keeps milliseconds; RestoreUserContent matches on this time
+	deletedAt := time.Now().Truncate(time.Millisecond)
+	err = uu.userRepository.DeleteUser(ctx, id, deletedBy, action, deletedAt)
+	if err != nil {
+		return err
+	}
+	if err := revokeUserTokens(ctx, uu.refreshTokenRepository, uu.tokenDenylist, id); err != nil {
+		log.Printf("Failed to revoke sessions of deleted user %s: %v", id, err)
+	}
+	deleted := &domain.DeletedUser{ID: id, DeletedBy: deletedBy, DeletedAt: deletedAt, ContentAction: action}
+	if err := uu.cascadeService.HideUserContent(ctx, deleted); err != nil {
+		log.Printf("Failed to hide the content of deleted user %s: %v", id, err)
+	}
+
+	go uu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("user:id:%s", id))
+	go uu.cacheUseCase.InvalidatePrefix(context.Background(), "users:list:")
+
+	return nil
+}
+
+func (uu *userUsecase) SuspendUser(ctx context.Context, id, actorID, reason string, until *time.Time) error {
+	if until != nil && !until.After(time.Now()) {
+		return domain.ErrInvalidSuspension
+	}
+	return uu.restrictUser(ctx, id, actorID, domain.Suspended, reason, until)
+}
+
+func (uu *userUsecase) BanUser(ctx context.Context, id, actorID, reason string) error {
+	return uu.restrictUser(ctx, id, actorID, domain.Banned, reason, nil)
+}
+
+// restrictUser records the new status and signs the user out everywhere;
+// sign-ins are refused until the restriction ends.
+func (uu *userUsecase) restrictUser(ctx context.Context, id, actorID string, status domain.Status, reason string, until *time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, uu.contextTimeout)
+	defer cancel()
+
+	if id == actorID {
+		return domain.ErrCannotRestrictSelf
+	}
+	user, err := uu.userRepository.GetUserByID(ctx, id)
+	if err == mongo.ErrNoDocuments {
+		return domain.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if user.Username == domain.GhostUsername {
+		return domain.ErrGhostAccount
+	}
+
+	if err := uu.userRepository.RestrictUser(ctx, id, status, reason, until); err != nil {
+		return err
+	}
+	if err := revokeUserTokens(ctx, uu.refreshTokenRepository, uu.tokenDenylist, id); err != nil {
+		log.Printf("Failed to revoke tokens of %s user %s: %v", status, id, err)
+	}
+
+	go uu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("user:id:%s", id))
+	go uu.cacheUseCase.InvalidatePrefix(context.Background(), "users:list:")
+
+	return nil
+}
+
+func (uu *userUsecase) UnsuspendUser(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, uu.contextTimeout)
+	defer cancel()
+
+	if err := uu.userRepository.LiftRestriction(ctx, id); err != nil {
+		if err == domain.ErrUserNotRestricted {
+			if _, lookupErr := uu.userRepository.GetUserByID(ctx, id); lookupErr == mongo.ErrNoDocuments {
+				return domain.ErrUserNotFound
+			}
+		}
+		return err
+	}
+
+	go uu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("user:id:%s", id))
+	go uu.cacheUseCase.InvalidatePrefix(context.Background(), "users:list:")
+
+	return nil
 }
 
-func (uu *userUsecase) DeleteUser(ctx context.Context, id string) error {
+func (uu *userUsecase) RestoreUser(ctx context.Context, id string) error {
 	ctx, cancel := context.WithTimeout(ctx, uu.contextTimeout)
 	defer cancel()
 
-	err := uu.userRepository.DeleteUser(ctx, id)
+	deleted, err := uu.userRepository.RestoreUser(ctx, id)
 	if err != nil {
 		return err
 	}
+	if err := uu.cascadeService.RestoreUserContent(ctx, deleted); err != nil {
+		log.Printf("Failed to restore the content of user %s: %v", id, err)
+	}
 
 	go uu.cacheUseCase.Delete(context.Background(), fmt.Sprintf("user:id:%s", id))
 	go uu.cacheUseCase.InvalidatePrefix(context.Background(), "users:list:")
 
 	return nil
-}
\ No newline at end of file
+}